@@ -1,11 +1,14 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"runtime/debug"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 
+	"lucor.dev/paw/internal/cli"
 	"lucor.dev/paw/internal/icon"
 	"lucor.dev/paw/internal/ui"
 )
@@ -14,6 +17,17 @@ import (
 var Version string
 
 func main() {
+	// paw linked as docker-credential-paw acts as docker credential helper
+	if filepath.Base(os.Args[0]) == cli.DockerCredentialHelperName {
+		cli.Run(append([]string{"docker-credential"}, os.Args[1:]...))
+		return
+	}
+
+	if len(os.Args) > 1 {
+		cli.Run(os.Args[1:])
+		return
+	}
+
 	a := app.NewWithID("dev.koceg.pawazure")
 	a.SetIcon(icon.PawIcon)
 
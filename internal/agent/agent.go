@@ -0,0 +1,420 @@
+// Package agent implements a local agent that keeps the Azure Key Vaults
+// unlocked for the CLI and GUI sessions.
+//
+// The agent listens on a per-user Unix socket and holds the Azure credential
+// along with the vault caches, so that each invocation does not need to
+// authenticate again and list the whole vault. The agent locks itself, dropping
+// the credential and the caches, after an idle timeout.
+//
+// The protocol is a stream of JSON encoded Request, each one followed by a
+// JSON encoded Response.
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+const (
+	// DefaultTimeout is the default idle timeout after which the agent locks itself
+	DefaultTimeout = 15 * time.Minute
+	// socketEnv is the environment variable that overrides the default socket path
+	socketEnv = "PAW_AGENT_SOCK"
+	// socketFileName is the default socket file name, relative to the paw root
+	socketFileName = "agent.sock"
+)
+
+// Action represents the action requested to the agent
+type Action string
+
+const (
+	// ListAction lists the secrets of a vault without the values
+	ListAction Action = "list"
+	// GetAction returns a secret along with its value
+	GetAction Action = "get"
+	// SetAction creates or updates a secret
+	SetAction Action = "set"
+	// DeleteAction deletes a secret
+	DeleteAction Action = "delete"
+	// LockAction drops the credential and the vault caches
+	LockAction Action = "lock"
+	// StopAction locks and stops the agent
+	StopAction Action = "stop"
+	// PingAction checks the agent is alive
+	PingAction Action = "ping"
+	// SecretAction derives the stateless password of the item,
+	// the vault key never leaves the agent
+	SecretAction Action = "secret"
+	// CreateKeyAction creates the vault key, if it does not exist yet
+	CreateKeyAction Action = "create-key"
+)
+
+// Request represents a request to the agent.
+// Items are JSON encoded, see paw.UnmarshalItem
+type Request struct {
+	Action Action          `json:"action"`
+	Vault  string          `json:"vault,omitempty"`
+	Name   string          `json:"name,omitempty"`
+	Item   json.RawMessage `json:"item,omitempty"`
+	// Password holds the JSON encoded password options, see SecretAction
+	Password json.RawMessage `json:"password,omitempty"`
+}
+
+// ErrorCode identifies the errors that the clients map back to the sentinel errors
+type ErrorCode string
+
+const (
+	// NoKeyCode reports that the vault has no key, see azure.ErrNoKey
+	NoKeyCode ErrorCode = "no-key"
+)
+
+// Response represents the agent response to a request.
+// Items are JSON encoded, see paw.UnmarshalItem
+type Response struct {
+	Error string `json:"error,omitempty"`
+	// Code identifies the error, if known
+	Code  ErrorCode         `json:"code,omitempty"`
+	Item  json.RawMessage   `json:"item,omitempty"`
+	Items []json.RawMessage `json:"items,omitempty"`
+	// Secret is the derived password, see SecretAction
+	Secret string `json:"secret,omitempty"`
+}
+
+// SocketPath returns the path of the agent socket.
+// It defaults to agent.sock into the paw root and can be overridden by the
+// PAW_AGENT_SOCK environment variable.
+func SocketPath() (string, error) {
+	if p := os.Getenv(socketEnv); p != "" {
+		return p, nil
+	}
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root(), socketFileName), nil
+}
+
+// OpenFunc opens the named vault
+type OpenFunc func(name string) (azure.Vault, error)
+
+// Agent holds the unlocked vaults and serves the requests
+type Agent struct {
+	// Timeout is the idle timeout after which the agent locks itself
+	Timeout time.Duration
+
+	newOpenFunc func() (OpenFunc, error)
+
+	// mu guards the state below, it is never held during the network calls
+	mu     sync.Mutex
+	open   OpenFunc
+	vaults map[string]azure.Vault
+	// generation is incremented on lock, so that the vaults opened meanwhile are not cached
+	generation int
+	timer      *time.Timer
+	done       chan struct{}
+}
+
+// New returns an agent that authenticates against Azure using the configuration.
+// The credential is created lazily on the first request that needs it.
+func New(conf *azure.Config, timeout time.Duration) *Agent {
+	return newAgent(timeout, func() (OpenFunc, error) {
+		cred, err := conf.NewCredential()
+		if err != nil {
+			return nil, err
+		}
+		return func(name string) (azure.Vault, error) {
+			return azure.NewSecretsVault(name, cred)
+		}, nil
+	})
+}
+
+func newAgent(timeout time.Duration, newOpenFunc func() (OpenFunc, error)) *Agent {
+	return &Agent{
+		Timeout:     timeout,
+		newOpenFunc: newOpenFunc,
+		vaults:      make(map[string]azure.Vault),
+		done:        make(chan struct{}),
+	}
+}
+
+// ListenAndServe listens on the Unix socket path and serves the requests
+// until a stop request is received.
+// The socket is accessible only by the current user.
+func (a *Agent) ListenAndServe(path string) error {
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return fmt.Errorf("agent already running on %s", path)
+	}
+	l, err := ListenUnix(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	return a.Serve(l)
+}
+
+// ListenUnix listens on the Unix socket path accessible only by the current user.
+// A stale socket, if any, is removed.
+func ListenUnix(path string) (net.Listener, error) {
+	os.Remove(path)
+
+	// make sure the socket is never accessible by other users, even briefly
+	mask := umask(0177)
+	l, err := net.Listen("unix", path)
+	umask(mask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Serve accepts the connections on the listener l until a stop request is received
+func (a *Agent) Serve(l net.Listener) error {
+	go func() {
+		<-a.done
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-a.done:
+				return nil
+			default:
+			}
+			return err
+		}
+		go a.serveConn(conn)
+	}
+}
+
+func (a *Agent) serveConn(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		req := &Request{}
+		err := dec.Decode(req)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			enc.Encode(&Response{Error: fmt.Sprintf("invalid request: %s", err)})
+			return
+		}
+		res := a.handle(req)
+		if err := enc.Encode(res); err != nil {
+			log.Printf("agent: could not write the response: %s", err)
+			return
+		}
+	}
+}
+
+// handle handles the request and resets the idle timer
+func (a *Agent) handle(req *Request) *Response {
+	a.mu.Lock()
+	a.resetTimer()
+	switch req.Action {
+	case PingAction:
+		a.mu.Unlock()
+		return &Response{}
+	case LockAction:
+		a.lock()
+		a.mu.Unlock()
+		return &Response{}
+	case StopAction:
+		a.lock()
+		select {
+		case <-a.done:
+		default:
+			close(a.done)
+		}
+		a.mu.Unlock()
+		return &Response{}
+	}
+	a.mu.Unlock()
+
+	vault, err := a.vault(req.Vault)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	switch req.Action {
+	case ListAction:
+		res := &Response{Items: []json.RawMessage{}}
+		var err error
+		vault.Range(func(name string, item paw.Item) bool {
+			var data []byte
+			data, err = marshalWithoutValue(item)
+			if err != nil {
+				return false
+			}
+			res.Items = append(res.Items, data)
+			return true
+		})
+		if err != nil {
+			return errorResponse(err)
+		}
+		return res
+	case GetAction:
+		m := &paw.Metadata{Name: req.Name}
+		item, err := vault.GetItem(m)
+		if err != nil {
+			return errorResponse(err)
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Item: data}
+	case SetAction:
+		item, err := paw.UnmarshalItem(req.Item)
+		if err != nil {
+			return errorResponse(fmt.Errorf("invalid item: %w", err))
+		}
+		if err := vault.AddItem(item); err != nil {
+			return errorResponse(err)
+		}
+		data, err := marshalWithoutValue(item)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Item: data}
+	case SecretAction:
+		var item paw.Item
+		if len(req.Item) > 0 {
+			item, err = paw.UnmarshalItem(req.Item)
+			if err != nil {
+				return errorResponse(fmt.Errorf("invalid item: %w", err))
+			}
+		}
+		password := &paw.Password{}
+		if err := json.Unmarshal(req.Password, password); err != nil {
+			return errorResponse(fmt.Errorf("invalid password: %w", err))
+		}
+		if !password.Stateless() {
+			return errorResponse(errors.New("only the stateless passwords are derived by the agent"))
+		}
+		secret, err := vault.Secret(password, item)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Secret: secret}
+	case CreateKeyAction:
+		if err := vault.CreateKey(); err != nil {
+			return errorResponse(err)
+		}
+		return &Response{}
+	case DeleteAction:
+		m := &paw.Metadata{Name: req.Name}
+		if err := vault.DeleteItem(m); err != nil {
+			return errorResponse(err)
+		}
+		return &Response{}
+	}
+	return errorResponse(fmt.Errorf("invalid action %q", req.Action))
+}
+
+// vault returns the named vault opening it, if needed.
+// The lock is not held while authenticating and opening the vault, so that a slow
+// request does not block the others. Vaults opened concurrently resolve to the first cached.
+func (a *Agent) vault(name string) (azure.Vault, error) {
+	if name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+	a.mu.Lock()
+	v, ok := a.vaults[name]
+	open := a.open
+	generation := a.generation
+	a.mu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	if open == nil {
+		var err error
+		open, err = a.newOpenFunc()
+		if err != nil {
+			return nil, err
+		}
+		a.mu.Lock()
+		if a.generation == generation && a.open == nil {
+			a.open = open
+		}
+		a.mu.Unlock()
+	}
+	v, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.vaults[name]; ok {
+		return cached, nil
+	}
+	if a.generation == generation {
+		a.vaults[name] = v
+	}
+	return v, nil
+}
+
+// lock drops the credential and the vault caches, mu must be held
+func (a *Agent) lock() {
+	a.generation++
+	a.open = nil
+	a.vaults = make(map[string]azure.Vault)
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+}
+
+// resetTimer resets the idle timer, mu must be held
+func (a *Agent) resetTimer() {
+	if a.Timeout <= 0 {
+		return
+	}
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = time.AfterFunc(a.Timeout, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.lock()
+	})
+}
+
+func errorResponse(err error) *Response {
+	res := &Response{Error: err.Error()}
+	if errors.Is(err, azure.ErrNoKey) {
+		res.Code = NoKeyCode
+	}
+	return res
+}
+
+// marshalWithoutValue returns the JSON encoding of the item without the secret value
+func marshalWithoutValue(item paw.Item) ([]byte, error) {
+	item, err := azure.WithoutValue(item)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(item)
+}
@@ -0,0 +1,254 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// memVault is an in memory azure.Vault used for testing
+type memVault struct {
+	secrets map[string]paw.Item
+	key     *paw.Key
+}
+
+func (v *memVault) AddItem(secret paw.Item) error {
+	m := secret.GetMetadata()
+	m.Modified = time.Now()
+	v.secrets[m.Name] = secret
+	return nil
+}
+
+func (v *memVault) DeleteItem(secret paw.Item) error {
+	delete(v.secrets, secret.GetMetadata().Name)
+	return nil
+}
+
+func (v *memVault) GetItem(secret paw.Item) (paw.Item, error) {
+	s, ok := v.secrets[secret.GetMetadata().Name]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return s, nil
+}
+
+func (v *memVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata {
+	return nil
+}
+
+func (v *memVault) Key() (*paw.Key, error) {
+	if v.key == nil {
+		return nil, azure.ErrNoKey
+	}
+	return v.key, nil
+}
+
+func (v *memVault) CreateKey() error {
+	if v.key != nil {
+		return nil
+	}
+	var err error
+	v.key, err = paw.GenerateKey()
+	return err
+}
+
+func (v *memVault) Secret(password *paw.Password, item paw.Item) (string, error) {
+	if !password.Stateless() {
+		return azure.GenerateSecret(nil, password, item)
+	}
+	return azure.GenerateSecret(v.key, password, item)
+}
+
+func (v *memVault) ListItems() []string {
+	var names []string
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v *memVault) Range(f func(name string, item paw.Item) bool) {
+	for _, name := range v.ListItems() {
+		if !f(name, v.secrets[name]) {
+			break
+		}
+	}
+}
+
+func (v *memVault) Size() int {
+	return len(v.secrets)
+}
+
+func (v *memVault) SizeByType(_ paw.ItemType) int {
+	return v.Size()
+}
+
+func TestAgentRoundTrip(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "paw")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opened := 0
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+	vault := &memVault{secrets: map[string]paw.Item{}, key: key}
+	a := newAgent(0, func() (OpenFunc, error) {
+		return func(name string) (azure.Vault, error) {
+			opened++
+			return vault, nil
+		}, nil
+	})
+
+	path := filepath.Join(dir, "agent.sock")
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- a.ListenAndServe(path)
+	}()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	client, err := Dial(path)
+	require.NoError(t, err)
+	defer client.Close()
+
+	rv, err := NewRemoteVault(client, "test")
+	require.NoError(t, err)
+	assert.Equal(t, 0, rv.Size())
+	// the vault key is never served, the stateless passwords are derived by the agent
+	_, err = rv.Key()
+	assert.Error(t, err)
+	stateless := paw.NewLogin()
+	stateless.Name = "example.com"
+	stateless.Password = paw.NewStatelessPassword()
+	want, err := key.Secret(stateless.Password.SeederFor(stateless))
+	require.NoError(t, err)
+	got, err := rv.Secret(stateless.Password, stateless)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	login := paw.NewLogin()
+	login.Name = "login"
+	login.Username = "user"
+	login.Password.Value = "secret"
+	err = rv.AddItem(login)
+	require.NoError(t, err)
+
+	// a new remote vault must see the login without the value
+	rv, err = NewRemoteVault(client, "test")
+	require.NoError(t, err)
+	require.Equal(t, []string{"login"}, rv.ListItems())
+	rv.Range(func(name string, item paw.Item) bool {
+		l := item.(*paw.Login)
+		assert.Equal(t, "user", l.Username)
+		assert.Empty(t, l.Password.Value)
+		return true
+	})
+
+	item, err := rv.GetItem(&paw.Metadata{Name: "login"})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", item.(*paw.Login).Password.Value)
+
+	err = rv.DeleteItem(item)
+	require.NoError(t, err)
+	assert.Equal(t, 0, rv.Size())
+
+	sshKey := paw.NewSSHKey()
+	sshKey.Name = "deploy"
+	require.NoError(t, sshKey.Generate(paw.ED25519SSHKey, 0))
+	require.NoError(t, rv.AddItem(sshKey))
+	rv, err = NewRemoteVault(client, "test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, rv.SizeByType(paw.SSHKeyItemType))
+	item, err = rv.GetItem(sshKey)
+	require.NoError(t, err)
+	assert.Equal(t, sshKey.PrivateKey, item.(*paw.SSHKey).PrivateKey)
+	require.NoError(t, rv.DeleteItem(sshKey))
+
+	// the vault key is created only on request
+	vault.key = nil
+	_, err = rv.Secret(stateless.Password, stateless)
+	assert.ErrorIs(t, err, azure.ErrNoKey)
+	require.NoError(t, rv.CreateKey())
+	require.NotNil(t, vault.key)
+	_, err = rv.Secret(stateless.Password, stateless)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, opened)
+
+	// the vault must be opened again once locked
+	require.NoError(t, client.Lock())
+	_, err = NewRemoteVault(client, "test")
+	require.NoError(t, err)
+	assert.Equal(t, 2, opened)
+
+	require.NoError(t, client.Stop())
+	require.NoError(t, <-errCh)
+}
+
+func TestAgentIdleTimeout(t *testing.T) {
+	a := newAgent(10*time.Millisecond, func() (OpenFunc, error) {
+		return func(name string) (azure.Vault, error) {
+			return &memVault{secrets: map[string]paw.Item{}}, nil
+		}, nil
+	})
+
+	res := a.handle(&Request{Action: ListAction, Vault: "test"})
+	require.Empty(t, res.Error)
+
+	require.Eventually(t, func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return len(a.vaults) == 0 && a.open == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAgentSlowVault(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	a := newAgent(0, func() (OpenFunc, error) {
+		return func(name string) (azure.Vault, error) {
+			if name == "slow" {
+				close(started)
+				<-release
+			}
+			return &memVault{secrets: map[string]paw.Item{}}, nil
+		}, nil
+	})
+
+	done := make(chan *Response)
+	go func() {
+		done <- a.handle(&Request{Action: ListAction, Vault: "slow"})
+	}()
+	<-started
+
+	// the requests to the other vaults are not blocked meanwhile
+	res := a.handle(&Request{Action: ListAction, Vault: "fast"})
+	require.Empty(t, res.Error)
+	res = a.handle(&Request{Action: PingAction})
+	require.Empty(t, res.Error)
+
+	// a vault opened while the agent is locked is not cached
+	a.handle(&Request{Action: LockAction})
+	close(release)
+	res = <-done
+	require.Empty(t, res.Error)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	assert.Empty(t, a.vaults)
+}
@@ -0,0 +1,302 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Vault interface
+var _ azure.Vault = (*RemoteVault)(nil)
+
+// Client is a client for the agent
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to the agent listening on the Unix socket path
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+	}, nil
+}
+
+// DialDefault connects to the agent listening on the default socket path
+func DialDefault() (*Client, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(path)
+}
+
+// Close closes the connection to the agent
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Do sends the request to the agent and returns the response.
+// An error is returned if the agent could not handle the request.
+func (c *Client) Do(req *Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enc.Encode(req); err != nil {
+		return nil, fmt.Errorf("agent: could not send the request: %w", err)
+	}
+	res := &Response{}
+	if err := c.dec.Decode(res); err != nil {
+		return nil, fmt.Errorf("agent: could not read the response: %w", err)
+	}
+	if res.Error != "" {
+		return nil, responseError(res)
+	}
+	return res, nil
+}
+
+// responseError returns the error of the response, mapping the known codes to the sentinel errors
+func responseError(res *Response) error {
+	switch res.Code {
+	case NoKeyCode:
+		return azure.ErrNoKey
+	}
+	return errors.New(res.Error)
+}
+
+// Lock asks the agent to drop the credential and the vault caches
+func (c *Client) Lock() error {
+	_, err := c.Do(&Request{Action: LockAction})
+	return err
+}
+
+// Stop asks the agent to stop
+func (c *Client) Stop() error {
+	_, err := c.Do(&Request{Action: StopAction})
+	return err
+}
+
+// RemoteVault is a vault served by the agent.
+// The secret list is loaded on creation, values are requested to the agent on demand.
+type RemoteVault struct {
+	client *Client
+	name   string
+
+	// mu guards secrets so that the values can be loaded concurrently
+	mu      sync.RWMutex
+	secrets map[string]paw.Item
+}
+
+// NewRemoteVault returns the named vault served by the agent
+func NewRemoteVault(client *Client, name string) (*RemoteVault, error) {
+	res, err := client.Do(&Request{Action: ListAction, Vault: name})
+	if err != nil {
+		return nil, err
+	}
+	v := &RemoteVault{
+		client:  client,
+		name:    name,
+		secrets: make(map[string]paw.Item, len(res.Items)),
+	}
+	for _, data := range res.Items {
+		item, err := paw.UnmarshalItem(data)
+		if err != nil {
+			return nil, err
+		}
+		v.secrets[item.GetMetadata().Name] = item
+	}
+	return v, nil
+}
+
+// AddItem creates or updates the secret
+func (v *RemoteVault) AddItem(secret paw.Item) error {
+	data, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	res, err := v.client.Do(&Request{Action: SetAction, Vault: v.name, Item: data})
+	if err != nil {
+		return err
+	}
+	// the agent response holds the updated attributes
+	updated, err := paw.UnmarshalItem(res.Item)
+	if err != nil {
+		return err
+	}
+	m := secret.GetMetadata()
+	m.Created = updated.GetMetadata().Created
+	m.Modified = updated.GetMetadata().Modified
+	v.mu.Lock()
+	v.secrets[m.Name] = secret
+	v.mu.Unlock()
+	return nil
+}
+
+// DeleteItem deletes the secret
+func (v *RemoteVault) DeleteItem(secret paw.Item) error {
+	name := secret.GetMetadata().Name
+	_, err := v.client.Do(&Request{Action: DeleteAction, Vault: v.name, Name: name})
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	delete(v.secrets, name)
+	v.mu.Unlock()
+	return nil
+}
+
+// GetItem returns the secret along with its value
+func (v *RemoteVault) GetItem(secret paw.Item) (paw.Item, error) {
+	name := secret.GetMetadata().Name
+	v.mu.RLock()
+	s, ok := v.secrets[name]
+	v.mu.RUnlock()
+	if ok && azure.HasValue(s) {
+		return s, nil
+	}
+	res, err := v.client.Do(&Request{Action: GetAction, Vault: v.name, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	item, err := paw.UnmarshalItem(res.Item)
+	if err != nil {
+		return nil, err
+	}
+	v.mu.Lock()
+	v.secrets[name] = item
+	v.mu.Unlock()
+	return item, nil
+}
+
+// FilterItemMetadata returns the metadata of the secrets matching the options
+func (v *RemoteVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata {
+	metadata := []*paw.Metadata{}
+	filter := opt.Name
+	v.mu.RLock()
+	for _, secret := range v.secrets {
+		m := secret.GetMetadata()
+		if opt.ItemType != 0 && (opt.ItemType&m.Type) == 0 {
+			continue
+		}
+		if filter != "" && !strings.Contains(m.Name, filter) {
+			continue
+		}
+		metadata = append(metadata, m)
+	}
+	v.mu.RUnlock()
+	// if metadata is empty try to get the secret from the agent
+	if len(metadata) == 0 && filter != "" {
+		secret, err := v.GetItem(&paw.Metadata{Name: filter})
+		if err == nil {
+			metadata = append(metadata, secret.GetMetadata())
+			return metadata
+		}
+	}
+	sort.Sort(paw.ByString(metadata))
+	return metadata
+}
+
+// errKeyNotServed is returned by Key, the vault key never leaves the agent
+var errKeyNotServed = errors.New("agent: the vault key is not served, stop the agent to access it")
+
+// Key is not supported, the stateless passwords are derived by the agent, see Secret
+func (v *RemoteVault) Key() (*paw.Key, error) {
+	return nil, errKeyNotServed
+}
+
+// CreateKey asks the agent to create the vault key, if it does not exist yet
+func (v *RemoteVault) CreateKey() error {
+	_, err := v.client.Do(&Request{Action: CreateKeyAction, Vault: v.name})
+	return err
+}
+
+// Secret generates the password of the item. The stateless passwords are derived by the agent
+func (v *RemoteVault) Secret(password *paw.Password, item paw.Item) (string, error) {
+	if !password.Stateless() {
+		return azure.GenerateSecret(nil, password, item)
+	}
+	req := &Request{Action: SecretAction, Vault: v.name}
+	if item != nil {
+		data, err := marshalWithoutValue(item)
+		if err != nil {
+			return "", err
+		}
+		req.Item = data
+	}
+	p := *password
+	p.Value = ""
+	data, err := json.Marshal(&p)
+	if err != nil {
+		return "", err
+	}
+	req.Password = data
+	res, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	return res.Secret, nil
+}
+
+// ListItems returns the sorted list of secret names
+func (v *RemoteVault) ListItems() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var secrets []string
+	for name := range v.secrets {
+		secrets = append(secrets, name)
+	}
+	sort.Strings(secrets)
+	return secrets
+}
+
+// Range calls f sequentially for each secret, without loading the value
+func (v *RemoteVault) Range(f func(name string, item paw.Item) bool) {
+	for _, name := range v.ListItems() {
+		v.mu.RLock()
+		item, ok := v.secrets[name]
+		v.mu.RUnlock()
+		if !ok {
+			// deleted meanwhile
+			continue
+		}
+		if !f(name, item) {
+			break
+		}
+	}
+}
+
+// Size returns the number of secrets
+func (v *RemoteVault) Size() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return len(v.secrets)
+}
+
+// SizeByType returns the number of secrets by item type
+func (v *RemoteVault) SizeByType(itemType paw.ItemType) int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	size := 0
+	for _, secret := range v.secrets {
+		if secret.GetMetadata().Type == itemType {
+			size++
+		}
+	}
+	return size
+}
@@ -0,0 +1,7 @@
+package agent
+
+// umask is a no-op on Windows, the socket permissions are handled by the ACL
+// of the parent directory
+func umask(mask int) int {
+	return mask
+}
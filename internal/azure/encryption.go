@@ -0,0 +1,383 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+	"lucor.dev/paw/internal/paw"
+)
+
+const (
+	// configSecretName is the name of the secret holding the vault config.
+	// The secret is reserved and not listed as an item.
+	configSecretName = "paw-config"
+	// configType is the type tag value of the vault config secret
+	configType = "config"
+	// encryptedTag is the secret tag set on the secrets whose value is age encrypted
+	// client side to the vault recipients
+	encryptedTag = "age"
+	// identityFileName is the name of the file, into the paw root, holding the
+	// local age identities used to decrypt the secret values
+	identityFileName = "identity.txt"
+	// trustedDirName is the name of the directory, into the paw root, holding for each vault
+	// the recipients trusted by the local user. The config secret is not authenticated, so the
+	// recipients changed by someone else are not used until trusted again, see TrustRecipients
+	trustedDirName = "trusted"
+)
+
+// ErrUntrustedRecipients is returned when the vault recipients differ from the ones trusted locally
+var ErrUntrustedRecipients = errors.New("the vault recipients changed since last trusted")
+
+// VaultConfig is the vault-level config shared by the team, it is stored into the config secret
+type VaultConfig struct {
+	// Encryption reports whether the secret values are age encrypted client side,
+	// so that they are not readable by who has only the Key Vault get permission
+	Encryption bool `json:"encryption"`
+	// Recipients are the age recipients (age1...) or the SSH public keys the
+	// secret values are encrypted to
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// Config returns the vault config, it is always loaded from the vault so that
+// the changes made by the other members are honoured
+func (v *SecretsVault) Config() (*VaultConfig, error) {
+	config := &VaultConfig{}
+	rsp, err := v.client.GetSecret(context.TODO(), configSecretName, nil)
+	if isNotFound(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load the vault config: %w", err)
+	}
+	if err := json.Unmarshal([]byte(*rsp.Secret.Value), config); err != nil {
+		return nil, fmt.Errorf("invalid vault config: %w", err)
+	}
+	return config, nil
+}
+
+// SetConfig stores the vault config and rewrites the secrets accordingly, i.e. encrypts
+// them to the new recipients or decrypts them once the encryption is disabled.
+// The vault key, if any, is rewritten as well and the config is trusted locally, see TrustConfig.
+// All the secret values are loaded before storing the config, so the local identity
+// must be able to decrypt them. An interrupted rewrite is completed by calling SetConfig again,
+// meanwhile each secret is still readable since it is tagged according to its encryption.
+func (v *SecretsVault) SetConfig(config *VaultConfig, progress func(done, total int)) error {
+	if err := v.checkConfig(config); err != nil {
+		return err
+	}
+
+	key, err := v.Key()
+	if err != nil && !errors.Is(err, ErrNoKey) {
+		return err
+	}
+	names := v.ListItems()
+	total := len(names) * 2
+	items := make([]paw.Item, 0, len(names))
+	for i, name := range names {
+		item, err := v.GetItem(&paw.Metadata{Name: name})
+		if err != nil {
+			return fmt.Errorf("could not load %q: %w", name, err)
+		}
+		items = append(items, item)
+		if progress != nil {
+			progress(i+1, total)
+		}
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	contentType := "paw vault config"
+	opts := &azsecrets.SetSecretOptions{
+		ContentType: &contentType,
+		Tags:        map[string]string{typeTag: configType},
+	}
+	if _, err := v.client.SetSecret(context.TODO(), configSecretName, string(data), opts); err != nil {
+		return fmt.Errorf("could not store the vault config: %w", err)
+	}
+	if err := v.TrustConfig(config); err != nil {
+		return err
+	}
+	if key != nil {
+		if err := v.setKey(key, config); err != nil {
+			return err
+		}
+	}
+
+	for i, item := range items {
+		if err := v.addItem(item, config); err != nil {
+			return fmt.Errorf("could not rewrite %q: %w", item.GetMetadata().Name, err)
+		}
+		if progress != nil {
+			progress(len(items)+i+1, total)
+		}
+	}
+	return nil
+}
+
+// checkConfig validates the config. When the encryption is enabled at least a local
+// identity must be a recipient, otherwise the secrets would not be readable anymore.
+func (v *SecretsVault) checkConfig(config *VaultConfig) error {
+	for _, r := range config.Recipients {
+		if _, err := paw.ParseRecipient(r); err != nil {
+			return err
+		}
+	}
+	if !config.Encryption {
+		return nil
+	}
+	if len(config.Recipients) == 0 {
+		return errors.New("the encryption requires at least a recipient")
+	}
+	for _, r := range IdentityRecipients(v.identities) {
+		if contains(config.Recipients, r) {
+			return nil
+		}
+	}
+	return errors.New("no local identity is a recipient, the secrets would not be readable anymore")
+}
+
+// checkRecipients returns ErrUntrustedRecipients if the recipients differ from the ones trusted locally,
+// or if the encryption has been disabled while the recipients are trusted, so that the secrets are not
+// silently stored in clear. The recipients are trusted on first use.
+func (v *SecretsVault) checkRecipients(config *VaultConfig) error {
+	trusted, err := v.TrustedRecipients()
+	if err != nil {
+		return err
+	}
+	if !config.Encryption {
+		if trusted == nil {
+			return nil
+		}
+		return fmt.Errorf("%w, the encryption has been disabled, review with: paw encryption -vault %s status", ErrUntrustedRecipients, v.name)
+	}
+	if trusted == nil {
+		return v.TrustRecipients(config.Recipients)
+	}
+	added, removed := RecipientsChanges(trusted, config.Recipients)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w, review them with: paw encryption -vault %s status", ErrUntrustedRecipients, v.name)
+}
+
+// TrustedRecipients returns the vault recipients trusted locally, nil if not trusted yet
+func (v *SecretsVault) TrustedRecipients() ([]string, error) {
+	path, err := TrustedRecipientsPath(v.name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	recipients, err := paw.ReadRecipients(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted recipients file %s: %w", path, err)
+	}
+	if recipients == nil {
+		recipients = []string{}
+	}
+	return recipients, nil
+}
+
+// TrustConfig trusts locally the vault config: the recipients are trusted when the encryption is enabled,
+// otherwise the trusted recipients are removed since the local user accepted the encryption to be disabled
+func (v *SecretsVault) TrustConfig(config *VaultConfig) error {
+	if config.Encryption {
+		return v.TrustRecipients(config.Recipients)
+	}
+	path, err := TrustedRecipientsPath(v.name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// TrustRecipients stores the recipients as trusted locally for the vault
+func (v *SecretsVault) TrustRecipients(recipients []string) error {
+	path, err := TrustedRecipientsPath(v.name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "# %s trusted recipients\n", v.name)
+	err = paw.WriteRecipients(f, recipients)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// TrustedRecipientsPath returns the path of the file holding the recipients of the vault trusted locally
+func TrustedRecipientsPath(vault string) (string, error) {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.(*paw.OSStorage).Root(), trustedDirName, vault+".txt"), nil
+}
+
+// RecipientsChanges returns the recipients added and removed compared to the trusted ones
+func RecipientsChanges(trusted []string, recipients []string) (added []string, removed []string) {
+	for _, r := range recipients {
+		if !contains(trusted, r) {
+			added = append(added, r)
+		}
+	}
+	for _, r := range trusted {
+		if !contains(recipients, r) {
+			removed = append(removed, r)
+		}
+	}
+	return added, removed
+}
+
+// encryptValue encrypts the value to the recipients, the result is armored to be stored as secret value
+func encryptValue(value string, recipients []string) (string, error) {
+	var ageRecipients []age.Recipient
+	for _, r := range recipients {
+		recipient, err := paw.ParseRecipient(r)
+		if err != nil {
+			return "", err
+		}
+		ageRecipients = append(ageRecipients, recipient)
+	}
+	if len(ageRecipients) == 0 {
+		return "", errors.New("no recipients to encrypt to")
+	}
+
+	buf := &bytes.Buffer{}
+	aw := armor.NewWriter(buf)
+	w, err := age.Encrypt(aw, ageRecipients...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, value); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := aw.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// decryptValue decrypts the armored value using the identities
+func decryptValue(value string, identities []age.Identity) (string, error) {
+	if len(identities) == 0 {
+		return "", fmt.Errorf("the secret is encrypted and no local identity is available, see the paw encryption command")
+	}
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(value)), identities...)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt the secret: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt the secret: %w", err)
+	}
+	return string(data), nil
+}
+
+// IdentityPath returns the path of the file holding the local age identities
+func IdentityPath() (string, error) {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.(*paw.OSStorage).Root(), identityFileName), nil
+}
+
+// LoadIdentities loads the local age identities, it returns no identities if the file does not exist
+func LoadIdentities() ([]age.Identity, error) {
+	path, err := IdentityPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity file %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// GenerateIdentity generates the local age identity, it fails if the identity file already exists.
+// The file has the same format of age-keygen.
+func GenerateIdentity() (*age.X25519Identity, error) {
+	path, err := IdentityPath()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(f, "# created: %s\n# public key: %s\n%s\n", time.Now().Format(time.RFC3339), identity.Recipient(), identity)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// IdentityRecipients returns the recipients of the local X25519 identities,
+// that can be added to the vault recipients
+func IdentityRecipients(identities []age.Identity) []string {
+	var recipients []string
+	for _, identity := range identities {
+		if i, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, i.Recipient().String())
+		}
+	}
+	return recipients
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
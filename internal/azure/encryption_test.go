@@ -0,0 +1,178 @@
+package azure
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestEncryptValue(t *testing.T) {
+	alice, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	bob, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	eve, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	value := `{"password":"s3cret","totp":"otpauth://totp/paw?secret=JBSWY3DPEHPK3PXP"}`
+	encrypted, err := encryptValue(value, []string{alice.Recipient().String(), bob.Recipient().String()})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "-----BEGIN AGE ENCRYPTED FILE-----"))
+	assert.NotContains(t, encrypted, "s3cret")
+
+	for _, identity := range []age.Identity{alice, bob} {
+		got, err := decryptValue(encrypted, []age.Identity{identity})
+		require.NoError(t, err)
+		assert.Equal(t, value, got)
+	}
+
+	_, err = decryptValue(encrypted, []age.Identity{eve})
+	assert.Error(t, err, "not a recipient")
+	_, err = decryptValue(encrypted, nil)
+	assert.Error(t, err, "no identities")
+	_, err = encryptValue(value, nil)
+	assert.Error(t, err, "no recipients")
+	_, err = encryptValue(value, []string{"invalid"})
+	assert.Error(t, err)
+}
+
+func TestCheckConfig(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	v := &SecretsVault{identities: []age.Identity{identity}}
+
+	tests := []struct {
+		name    string
+		config  *VaultConfig
+		wantErr bool
+	}{
+		{name: "disabled", config: &VaultConfig{}},
+		{name: "disabled with recipients", config: &VaultConfig{Recipients: []string{other.Recipient().String()}}},
+		{name: "enabled", config: &VaultConfig{Encryption: true, Recipients: []string{other.Recipient().String(), identity.Recipient().String()}}},
+		{name: "no recipients", config: &VaultConfig{Encryption: true}, wantErr: true},
+		{name: "local identity is not a recipient", config: &VaultConfig{Encryption: true, Recipients: []string{other.Recipient().String()}}, wantErr: true},
+		{name: "invalid recipient", config: &VaultConfig{Recipients: []string{"age1invalid"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.checkConfig(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckRecipients(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	alice, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	eve, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	v := &SecretsVault{name: "team", identities: []age.Identity{alice}}
+
+	config := &VaultConfig{Encryption: true, Recipients: []string{alice.Recipient().String()}}
+	trusted, err := v.TrustedRecipients()
+	require.NoError(t, err)
+	assert.Nil(t, trusted)
+	require.NoError(t, v.checkRecipients(config), "trusted on first use")
+	trusted, err = v.TrustedRecipients()
+	require.NoError(t, err)
+	assert.Equal(t, config.Recipients, trusted)
+
+	// a recipient added by someone else
+	config.Recipients = append(config.Recipients, eve.Recipient().String())
+	assert.ErrorIs(t, v.checkRecipients(config), ErrUntrustedRecipients)
+	added, removed := RecipientsChanges(trusted, config.Recipients)
+	assert.Equal(t, []string{eve.Recipient().String()}, added)
+	assert.Empty(t, removed)
+
+	require.NoError(t, v.TrustRecipients(config.Recipients))
+	assert.NoError(t, v.checkRecipients(config))
+
+	// the encryption disabled by someone else must not downgrade the secrets silently
+	disabled := &VaultConfig{Recipients: config.Recipients}
+	assert.ErrorIs(t, v.checkRecipients(disabled), ErrUntrustedRecipients)
+	require.NoError(t, v.TrustConfig(disabled))
+	trusted, err = v.TrustedRecipients()
+	require.NoError(t, err)
+	assert.Nil(t, trusted)
+	assert.NoError(t, v.checkRecipients(disabled), "the disabled encryption has been accepted")
+	require.NoError(t, v.TrustConfig(disabled), "nothing to remove")
+}
+
+func TestEncryptedKey(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+	v := &SecretsVault{identities: []age.Identity{identity}}
+
+	encrypted, err := encryptValue(key.Identity(), []string{identity.Recipient().String()})
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, key.Identity())
+	got, err := v.parseKey(encrypted, map[string]string{typeTag: keyType, encryptedTag: "true"})
+	require.NoError(t, err)
+	assert.Equal(t, key.Identity(), got.Identity())
+
+	got, err = v.parseKey(key.Identity(), map[string]string{typeTag: keyType})
+	require.NoError(t, err)
+	assert.Equal(t, key.Identity(), got.Identity())
+
+	_, err = (&SecretsVault{}).parseKey(encrypted, map[string]string{encryptedTag: "true"})
+	assert.Error(t, err, "no local identity")
+}
+
+func TestGenerateIdentity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	identities, err := LoadIdentities()
+	require.NoError(t, err)
+	assert.Empty(t, identities)
+
+	identity, err := GenerateIdentity()
+	require.NoError(t, err)
+	_, err = GenerateIdentity()
+	assert.Error(t, err, "the identity is not overwritten")
+
+	path, err := IdentityPath()
+	require.NoError(t, err)
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	identities, err = LoadIdentities()
+	require.NoError(t, err)
+	assert.Equal(t, []string{identity.Recipient().String()}, IdentityRecipients(identities))
+}
+
+func TestEncryptedSecretValue(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	login := paw.NewLogin()
+	login.Name = "db"
+	login.Password.Value = "s3cret"
+	value, _, tags, err := secretValue(login)
+	require.NoError(t, err)
+
+	encrypted, err := encryptValue(value, []string{identity.Recipient().String()})
+	require.NoError(t, err)
+	decrypted, err := decryptValue(encrypted, []age.Identity{identity})
+	require.NoError(t, err)
+
+	got, err := paw.NewItem("db", paw.LoginItemType)
+	require.NoError(t, err)
+	require.NoError(t, setValue(got, decrypted, tags))
+	assert.Equal(t, "s3cret", got.(*paw.Login).Password.Value)
+}
@@ -1,26 +1,100 @@
 package azure
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
 	"lucor.dev/paw/internal/paw"
-	"strings"
 )
 
+const (
+	// typeTag is the secret tag holding the paw item type.
+	// Secrets without the tag are handled as logins
+	typeTag = "type"
+	// totpTag is the secret tag set on logins with an attached TOTP.
+	// The secret value of these logins holds the JSON encoded loginValue
+	totpTag = "totp"
+	// fieldsTag is the secret tag set on logins with custom fields.
+	// The secret value of these logins holds the JSON encoded loginValue
+	fieldsTag = "fields"
+	// pwgenTag is the secret tag holding the JSON encoded options used to generate the password.
+	// Options longer than a tag value are split into pwgenTag, pwgenTag-1, ...
+	pwgenTag = "pwgen"
+	// maxPwgenTags is the max number of tags holding the password options,
+	// Azure allows up to 15 tags for each secret
+	maxPwgenTags = 8
+	// publicKeyTag is the secret tag holding the SSH public key in the authorized_keys format,
+	// so that the key can be listed without loading the private key.
+	// Keys longer than a tag value are split into publicKeyTag, publicKeyTag-1, ...
+	publicKeyTag = "publickey"
+	// fingerprintTag is the secret tag holding the SSH public key fingerprint
+	fingerprintTag = "fingerprint"
+	// maxContentTypeLength is the max length allowed by Azure for the secret content type
+	maxContentTypeLength = 255
+	// maxTagValueLength is the max length allowed by Azure for a tag value
+	maxTagValueLength = 256
+)
+
+// loginValue is the secret value of a login with an attached TOTP or custom fields
+type loginValue struct {
+	Password string     `json:"password"`
+	TOTP     string     `json:"totp,omitempty"`
+	Fields   paw.Fields `json:"fields,omitempty"`
+}
+
+// hasLoginValue reports whether the login secret value holds the JSON encoded loginValue
+func hasLoginValue(tags map[string]string) bool {
+	return tags[totpTag] != "" || tags[fieldsTag] != ""
+}
+
 //https://stackoverflow.com/a/46208325
-func NewAzureSecret(i interface{}) *paw.Login {
-	s := paw.NewLogin()
+func NewAzureSecret(i interface{}) paw.Item {
 	index := "secrets/"
+	var name string
+	var contentType *string
+	var attributes *azsecrets.Attributes
+	var tags map[string]string
 	switch v := i.(type) {
 	case azsecrets.Item:
-		s.SetContent(v.ContentType)
-		s.Metadata.Created = *v.Attributes.Created
-		s.Metadata.Modified = *v.Attributes.Updated
-		s.Metadata.Name = secretName(*v.ID, index)
+		name = secretName(*v.ID, index)
+		contentType, attributes, tags = v.ContentType, v.Attributes, v.Tags
 	case azsecrets.Secret:
-		s.SetContent(v.ContentType)
-		s.Metadata.Created = *v.Attributes.Created
-		s.Metadata.Modified = *v.Attributes.Updated
-		s.Metadata.Name = strings.Split(secretName(*v.ID, index), "/")[0]
+		name = strings.Split(secretName(*v.ID, index), "/")[0]
+		contentType, attributes, tags = v.ContentType, v.Attributes, v.Tags
+	}
+
+	itemType, err := paw.ItemTypeFromString(tags[typeTag])
+	if err != nil {
+		itemType = paw.LoginItemType
+	}
+	s, err := paw.NewItem(name, itemType)
+	if err != nil {
+		s, _ = paw.NewItem(name, paw.LoginItemType)
+	}
+	setContent(s, contentType)
+	switch v := s.(type) {
+	case *paw.Login:
+		setPasswordOptions(v.Password, splitTag(tags, pwgenTag))
+		if tags[totpTag] != "" {
+			// the TOTP parameters are loaded along with the secret value
+			v.TOTP = &paw.TOTP{}
+		}
+	case *paw.Password:
+		setPasswordOptions(v, splitTag(tags, pwgenTag))
+	case *paw.SSHKey:
+		v.PublicKey = splitTag(tags, publicKeyTag)
+		v.Fingerprint = tags[fingerprintTag]
+	}
+
+	m := s.GetMetadata()
+	if attributes != nil {
+		m.Created = *attributes.Created
+		m.Modified = *attributes.Updated
+		if attributes.Expires != nil {
+			m.Expires = *attributes.Expires
+		}
 	}
 	return s
 }
@@ -29,3 +103,246 @@ func secretName(id, index string) string {
 	i := strings.LastIndex(id, index)
 	return id[i+len(index):]
 }
+
+// passwordOptions returns the JSON encoded options used to generate the password.
+// Custom passwords have no options.
+func passwordOptions(p *paw.Password) (string, error) {
+	if p == nil || p.Mode == paw.CustomPassword {
+		return "", nil
+	}
+	o := *p
+	o.Value, o.Metadata, o.Note = "", nil, nil
+	data, err := json.Marshal(&o)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxPwgenTags*maxTagValueLength {
+		return "", fmt.Errorf("password options can have %d chars Max", maxPwgenTags*maxTagValueLength)
+	}
+	return string(data), nil
+}
+
+// setSplitTag sets the value into the tags key, key-1, key-2, ...
+// so that each tag value fits the Azure max length
+func setSplitTag(tags map[string]string, key string, value string) {
+	for i := 0; value != ""; i++ {
+		n := maxTagValueLength
+		if len(value) < n {
+			n = len(value)
+		}
+		k := key
+		if i > 0 {
+			k = fmt.Sprintf("%s-%d", key, i)
+		}
+		tags[k] = value[:n]
+		value = value[n:]
+	}
+}
+
+// splitTag returns the value split by setSplitTag
+func splitTag(tags map[string]string, key string) string {
+	value := tags[key]
+	for i := 1; ; i++ {
+		v, ok := tags[fmt.Sprintf("%s-%d", key, i)]
+		if !ok {
+			return value
+		}
+		value += v
+	}
+}
+
+// setPasswordOptions sets the options used to generate the password from their JSON encoding.
+// Invalid options are ignored, the password is still usable as custom password.
+func setPasswordOptions(p *paw.Password, options string) {
+	if p == nil || options == "" {
+		return
+	}
+	o := &paw.Password{}
+	if err := json.Unmarshal([]byte(options), o); err != nil {
+		return
+	}
+	o.Value, o.Metadata, o.Note = p.Value, p.Metadata, p.Note
+	*p = *o
+}
+
+// setContent sets the item fields stored into the secret content type
+func setContent(item paw.Item, data *string) {
+	if data == nil {
+		return
+	}
+	switch v := item.(type) {
+	case *paw.Login:
+		v.SetContent(data)
+	case *paw.Password:
+		v.Note.Value = *data
+	case *paw.SSHKey:
+		v.Note.Value = *data
+	case *paw.TOTP:
+		v.Note.Value = *data
+	}
+}
+
+// setValue sets the item fields stored into the secret value
+func setValue(item paw.Item, value string, tags map[string]string) error {
+	switch v := item.(type) {
+	case *paw.Login:
+		if !hasLoginValue(tags) {
+			v.Password.Value = value
+			return nil
+		}
+		lv := &loginValue{}
+		if err := json.Unmarshal([]byte(value), lv); err != nil {
+			return fmt.Errorf("invalid login value: %w", err)
+		}
+		v.Password.Value = lv.Password
+		v.Fields = lv.Fields
+		v.TOTP = nil
+		if lv.TOTP == "" {
+			return nil
+		}
+		v.TOTP = &paw.TOTP{}
+		return v.TOTP.SetURI(lv.TOTP)
+	case *paw.Note:
+		v.Value = value
+	case *paw.Password:
+		v.Value = value
+	case *paw.SSHKey:
+		return v.SetPrivateKey(value)
+	case *paw.TOTP:
+		return v.SetURI(value)
+	default:
+		return fmt.Errorf("unsupported item type %q", item.GetMetadata().Type)
+	}
+	return nil
+}
+
+// HasValue reports whether the secret value has been loaded into the item
+func HasValue(item paw.Item) bool {
+	switch v := item.(type) {
+	case *paw.Login:
+		return v.Password.Value != ""
+	case *paw.Note:
+		return v.Value != ""
+	case *paw.Password:
+		return v.Value != ""
+	case *paw.SSHKey:
+		return v.PrivateKey != ""
+	case *paw.TOTP:
+		return v.Secret != ""
+	}
+	return false
+}
+
+// WithoutValue returns a copy of the item without the secret value
+func WithoutValue(item paw.Item) (paw.Item, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	c, err := paw.UnmarshalItem(data)
+	if err != nil {
+		return nil, err
+	}
+	switch v := c.(type) {
+	case *paw.Login:
+		v.Password.Value = ""
+		if v.TOTP != nil {
+			v.TOTP = &paw.TOTP{}
+		}
+		// hidden fields are part of the secret value
+		v.Fields = nil
+	case *paw.Note:
+		v.Value = ""
+	case *paw.Password:
+		v.Value = ""
+	case *paw.SSHKey:
+		v.PrivateKey = ""
+	case *paw.TOTP:
+		v.Secret = ""
+	}
+	return c, nil
+}
+
+// ValidateItem returns an error if the item cannot be stored as secret,
+// i.e. the content type or the tags exceed the Azure limits
+func ValidateItem(item paw.Item) error {
+	_, _, _, err := secretValue(item)
+	return err
+}
+
+// secretValue returns the secret value, the content type and the tags used to store the item
+func secretValue(item paw.Item) (value string, contentType string, tags map[string]string, err error) {
+	switch v := item.(type) {
+	case *paw.Login:
+		contentType = v.Username + "|" + v.URL + "|" + v.Note.Value
+		if len(contentType) > maxContentTypeLength {
+			err = fmt.Errorf("Concatenation \"Username|URL|Note\" can have %d chars Max", maxContentTypeLength)
+		}
+		options, oerr := passwordOptions(v.Password)
+		if oerr != nil {
+			return "", "", nil, oerr
+		}
+		hasTOTP := v.TOTP != nil && v.TOTP.Secret != ""
+		if !hasTOTP && len(v.Fields) == 0 && options == "" {
+			// plain logins are not tagged to keep compatibility with the existing secrets
+			return v.Password.Value, contentType, nil, err
+		}
+		tags = map[string]string{
+			typeTag: v.Type.String(),
+		}
+		if options != "" {
+			setSplitTag(tags, pwgenTag, options)
+		}
+		if !hasTOTP && len(v.Fields) == 0 {
+			return v.Password.Value, contentType, tags, err
+		}
+		if ferr := v.Fields.Validate(); ferr != nil {
+			return "", "", nil, ferr
+		}
+		lv := &loginValue{Password: v.Password.Value, Fields: v.Fields}
+		if hasTOTP {
+			lv.TOTP = v.TOTP.URI()
+			tags[totpTag] = "true"
+		}
+		if len(v.Fields) > 0 {
+			tags[fieldsTag] = "true"
+		}
+		data, jerr := json.Marshal(lv)
+		if jerr != nil {
+			return "", "", nil, jerr
+		}
+		return string(data), contentType, tags, err
+	case *paw.Note:
+		value = v.Value
+	case *paw.Password:
+		value, contentType = v.Value, v.Note.Value
+	case *paw.SSHKey:
+		value, contentType = v.PrivateKey, v.Note.Value
+	case *paw.TOTP:
+		value, contentType = v.URI(), v.Note.Value
+	default:
+		return "", "", nil, fmt.Errorf("unsupported item type %q", item.GetMetadata().Type)
+	}
+	if len(contentType) > maxContentTypeLength {
+		err = fmt.Errorf("Note can have %d chars Max", maxContentTypeLength)
+	}
+	tags = map[string]string{
+		typeTag: item.GetMetadata().Type.String(),
+	}
+	switch v := item.(type) {
+	case *paw.Password:
+		options, oerr := passwordOptions(v)
+		if oerr != nil {
+			return "", "", nil, oerr
+		}
+		if options != "" {
+			setSplitTag(tags, pwgenTag, options)
+		}
+	case *paw.SSHKey:
+		setSplitTag(tags, publicKeyTag, v.PublicKey)
+		if v.Fingerprint != "" {
+			tags[fingerprintTag] = v.Fingerprint
+		}
+	}
+	return value, contentType, tags, err
+}
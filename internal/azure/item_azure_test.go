@@ -0,0 +1,71 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestSSHKeySecretTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType paw.SSHKeyType
+	}{
+		{name: "ed25519", keyType: paw.ED25519SSHKey},
+		{name: "rsa", keyType: paw.RSASSHKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := paw.NewSSHKey()
+			key.Name = tt.name
+			require.NoError(t, key.Generate(tt.keyType, 0))
+
+			_, _, tags, err := secretValue(key)
+			require.NoError(t, err)
+			for k, v := range tags {
+				assert.LessOrEqual(t, len(v), maxTagValueLength, k)
+			}
+
+			id := "https://vault.vault.azure.net/secrets/" + tt.name
+			item := NewAzureSecret(azsecrets.Item{ID: &id, Tags: tags})
+			got, ok := item.(*paw.SSHKey)
+			require.True(t, ok)
+			assert.Equal(t, key.PublicKey, got.PublicKey)
+			assert.Equal(t, key.Fingerprint, got.Fingerprint)
+			assert.Empty(t, got.PrivateKey)
+		})
+	}
+}
+
+func TestPasswordOptionsTags(t *testing.T) {
+	rules := "required: upper; required: lower; required: digit; required: [-().&@?'#,/\";+]; max-consecutive: 2; minlength: 20; maxlength: 64; " +
+		"allowed: [-().&@?'#,/\";+]; " + strings.Repeat("allowed: ascii-printable; ", 10)
+	require.Greater(t, len(rules), maxTagValueLength)
+
+	login := paw.NewLogin()
+	login.Name = "example"
+	login.Password.Mode = paw.StatelessPassword
+	login.Password.Length = 32
+	login.Password.Rules = rules
+	login.Password.Value = "s3cret"
+
+	_, _, tags, err := secretValue(login)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(tags), 15)
+	for k, v := range tags {
+		assert.LessOrEqual(t, len(v), maxTagValueLength, k)
+	}
+
+	id := "https://vault.vault.azure.net/secrets/example"
+	item := NewAzureSecret(azsecrets.Item{ID: &id, Tags: tags})
+	got, ok := item.(*paw.Login)
+	require.True(t, ok)
+	assert.Equal(t, rules, got.Password.Rules)
+	assert.Equal(t, 32, got.Password.Length)
+	assert.Equal(t, paw.StatelessPassword, got.Password.Mode)
+}
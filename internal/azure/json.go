@@ -3,6 +3,7 @@ package azure
 import (
 	"encoding/json"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"lucor.dev/paw/internal/breach"
 	"lucor.dev/paw/internal/paw"
 	"os"
 	"path/filepath"
@@ -14,6 +15,15 @@ type Config struct {
 	// ClientID is the ID of the application users will authenticate to.
 	ClientID string   `json:"application_id"`
 	Vaults   []string `json:"azure_vaults"`
+	// DockerVault is the vault used by the docker credential helper.
+	// Defaults to the first vault when empty
+	DockerVault string `json:"docker_vault,omitempty"`
+	// BreachURL is the k-anonymity range endpoint used to check the breached passwords,
+	// i.e. https://api.pwnedpasswords.com/range/
+	BreachURL string `json:"breach_url,omitempty"`
+	// BreachDir is the directory holding the downloaded range files.
+	// When set the breached passwords are checked offline and BreachURL is ignored
+	BreachDir string `json:"breach_dir,omitempty"`
 }
 
 // read azure config file,create file if non existent
@@ -79,3 +89,15 @@ func (c *Config) NewCredential() (*azidentity.InteractiveBrowserCredential, erro
 	}
 	return cred, err
 }
+
+// BreachChecker returns the checker for the breached passwords.
+// It returns nil if neither a range endpoint nor a range directory is configured
+func (c *Config) BreachChecker() *breach.Checker {
+	if c.BreachDir != "" {
+		return breach.NewChecker(&breach.DirSource{Dir: c.BreachDir})
+	}
+	if c.BreachURL != "" {
+		return breach.NewChecker(&breach.HTTPSource{URL: c.BreachURL})
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package azure
+
+import "lucor.dev/paw/internal/paw"
+
+// Declare conformity to Vault interface
+var _ Vault = (*SecretsVault)(nil)
+
+// Vault wraps the methods to handle the secrets of an Azure Key Vault.
+// It is implemented by SecretsVault that talks directly with Azure and by
+// the agent's remote vault that shares an authenticated session.
+type Vault interface {
+	// AddItem creates or updates the secret
+	AddItem(secret paw.Item) error
+	// DeleteItem deletes the secret
+	DeleteItem(secret paw.Item) error
+	// GetItem returns the secret along with its value
+	GetItem(secret paw.Item) (paw.Item, error)
+	// FilterItemMetadata returns the metadata of the secrets matching the options
+	FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata
+	// Key returns the vault key used to derive the stateless passwords, it is loaded on first use.
+	// ErrNoKey is returned when the vault has no key, see CreateKey
+	Key() (*paw.Key, error)
+	// CreateKey creates the vault key, if it does not exist yet
+	CreateKey() error
+	// Secret generates the password of the item. The stateless passwords are derived
+	// from the vault key, ErrNoKey is returned when the vault has no key
+	Secret(password *paw.Password, item paw.Item) (string, error)
+	// ListItems returns the sorted list of secret names
+	ListItems() []string
+	// Range calls f sequentially for each secret, without loading the value
+	Range(f func(name string, item paw.Item) bool)
+	// Size returns the number of secrets
+	Size() int
+	// SizeByType returns the number of secrets by item type
+	SizeByType(itemType paw.ItemType) int
+}
@@ -2,24 +2,44 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
 	"lucor.dev/paw/internal/paw"
-	"sort"
-	"strings"
-	"time"
+)
+
+const (
+	// keySecretName is the name of the secret holding the vault key used to derive
+	// the stateless passwords. The secret is reserved and not listed as an item.
+	keySecretName = "paw-key"
+	// keyType is the type tag value of the vault key secret
+	keyType = "key"
 )
 
 // the main structure MainView would hold the TokenCredential
 // and a map of vaults map[string]*SecretsVault
 // name here is redundant
 type SecretsVault struct {
+	name   string
 	client *azsecrets.Client
+	// mu guards secrets so that the values can be loaded concurrently
+	mu sync.RWMutex
 	// vault holds secrets and would be a cache while the program is active
-	secrets map[string]*paw.Login
-	key     *paw.Key
+	secrets map[string]paw.Item
+	// keyMu guards key that is loaded on first use
+	keyMu sync.Mutex
+	key   *paw.Key
+	// identities are the local age identities used to decrypt the encrypted secret values
+	identities []age.Identity
 }
 
 func NewSecretsVault(name string, cred azcore.TokenCredential) (*SecretsVault, error) {
@@ -39,51 +59,255 @@ func NewSecretsVault(name string, cred azcore.TokenCredential) (*SecretsVault, e
 	if err != nil {
 		return nil, err
 	}
-	key, err := paw.MakeOneTimeKey()
-	if err != nil {
-		return nil, err
-	}
 	vault := &SecretsVault{
+		name:    name,
 		client:  client,
-		secrets: make(map[string]*paw.Login),
-		key:     key,
+		secrets: make(map[string]paw.Item),
+	}
+	vault.identities, err = LoadIdentities()
+	if err != nil {
+		return nil, err
 	}
 	vault.getItems()
 	return vault, nil
 }
 
+// ErrNoKey is returned when the vault has no key to derive the stateless passwords
+var ErrNoKey = errors.New("the vault has no key to derive the stateless passwords")
+
+// Key returns the vault key used to derive the stateless passwords, it is loaded on first use.
+// ErrNoKey is returned when the vault has no key, see CreateKey
+func (v *SecretsVault) Key() (*paw.Key, error) {
+	v.keyMu.Lock()
+	defer v.keyMu.Unlock()
+	return v.loadKey()
+}
+
+// loadKey loads the current version of the vault key, keyMu must be held
+func (v *SecretsVault) loadKey() (*paw.Key, error) {
+	if v.key != nil {
+		return v.key, nil
+	}
+	rsp, err := v.client.GetSecret(context.TODO(), keySecretName, nil)
+	if isNotFound(err) {
+		return nil, ErrNoKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load the vault key: %w", err)
+	}
+	key, err := v.parseKey(*rsp.Secret.Value, rsp.Secret.Tags)
+	if err != nil {
+		return nil, err
+	}
+	v.key = key
+	return key, nil
+}
+
+// parseKey parses the value of the vault key secret, decrypting it if encrypted
+func (v *SecretsVault) parseKey(value string, tags map[string]string) (*paw.Key, error) {
+	if tags[encryptedTag] != "" {
+		var err error
+		value, err = decryptValue(value, v.identities)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the vault key: %w", err)
+		}
+	}
+	return paw.ParseKey(value)
+}
+
+// CreateKey creates the vault key, if it does not exist yet. The key is encrypted
+// to the vault recipients when the encryption is enabled.
+// Keys created concurrently by different clients are resolved in favour of the first one:
+// each client restores the oldest version of the key secret as current.
+func (v *SecretsVault) CreateKey() error {
+	v.keyMu.Lock()
+	defer v.keyMu.Unlock()
+	_, err := v.loadKey()
+	if !errors.Is(err, ErrNoKey) {
+		return err
+	}
+	config, err := v.Config()
+	if err != nil {
+		return err
+	}
+	if err := v.checkRecipients(config); err != nil {
+		return err
+	}
+
+	key, err := paw.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if err := v.setKey(key, config); err != nil {
+		return err
+	}
+	version, err := v.firstKeyVersion()
+	if err != nil {
+		return err
+	}
+	rsp, err := v.client.GetSecret(context.TODO(), keySecretName, &azsecrets.GetSecretOptions{Version: version})
+	if err != nil {
+		return fmt.Errorf("could not load the vault key: %w", err)
+	}
+	first, err := v.parseKey(*rsp.Secret.Value, rsp.Secret.Tags)
+	if err != nil {
+		return err
+	}
+	if first.Identity() != key.Identity() {
+		// another client created the key meanwhile
+		if err := v.setKey(first, config); err != nil {
+			return err
+		}
+	}
+	v.key = first
+	return nil
+}
+
+// setKey stores the key as current version of the vault key secret,
+// the key is encrypted if required by the vault config
+func (v *SecretsVault) setKey(key *paw.Key, config *VaultConfig) error {
+	value := key.Identity()
+	tags := map[string]string{typeTag: keyType}
+	if config.Encryption {
+		var err error
+		value, err = encryptValue(value, config.Recipients)
+		if err != nil {
+			return err
+		}
+		tags[encryptedTag] = "true"
+	}
+	contentType := "paw vault key"
+	opts := &azsecrets.SetSecretOptions{
+		ContentType: &contentType,
+		Tags:        tags,
+	}
+	if _, err := v.client.SetSecret(context.TODO(), keySecretName, value, opts); err != nil {
+		return fmt.Errorf("could not store the vault key: %w", err)
+	}
+	return nil
+}
+
+// firstKeyVersion returns the oldest version of the vault key secret.
+// Versions created in the same second are ordered by ID so that all the clients agree.
+func (v *SecretsVault) firstKeyVersion() (string, error) {
+	var first azsecrets.Item
+	pager := v.client.ListSecretVersions(keySecretName, nil)
+	for pager.NextPage(context.TODO()) {
+		for _, s := range pager.PageResponse().Secrets {
+			if s.ID == nil || s.Attributes == nil || s.Attributes.Created == nil {
+				continue
+			}
+			if first.ID == nil || s.Attributes.Created.Before(*first.Attributes.Created) ||
+				(s.Attributes.Created.Equal(*first.Attributes.Created) && *s.ID < *first.ID) {
+				first = s
+			}
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return "", fmt.Errorf("could not list the vault key versions: %w", err)
+	}
+	if first.ID == nil {
+		return "", ErrNoKey
+	}
+	return secretName(*first.ID, keySecretName+"/"), nil
+}
+
+// Secret generates the password of the item. The stateless passwords are derived
+// from the vault key, ErrNoKey is returned when the vault has no key
+func (v *SecretsVault) Secret(password *paw.Password, item paw.Item) (string, error) {
+	if !password.Stateless() {
+		return GenerateSecret(nil, password, item)
+	}
+	key, err := v.Key()
+	if err != nil {
+		return "", err
+	}
+	return GenerateSecret(key, password, item)
+}
+
+// GenerateSecret generates the password of the item. The key is required only to derive
+// the stateless passwords, the other ones are generated using a one time key.
+func GenerateSecret(key *paw.Key, password *paw.Password, item paw.Item) (string, error) {
+	if key == nil {
+		if password.Stateless() {
+			return "", ErrNoKey
+		}
+		var err error
+		key, err = paw.MakeOneTimeKey()
+		if err != nil {
+			return "", err
+		}
+	}
+	return key.Secret(password.SeederFor(item))
+}
+
+// isNotFound reports whether the error is the Azure response to a missing secret
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// IsReservedName reports whether the secret name is reserved, i.e. to store the vault key
+func IsReservedName(name string) bool {
+	return name == keySecretName || name == configSecretName
+}
+
+// checkName returns an error if the secret name is reserved
+func checkName(name string) error {
+	if IsReservedName(name) {
+		return fmt.Errorf("%q is a reserved name", name)
+	}
+	return nil
+}
+
 // Delete Secret From Vault
 func (v *SecretsVault) DeleteItem(secret paw.Item) error {
 	s := secret.GetMetadata()
+	if err := checkName(s.Name); err != nil {
+		return err
+	}
 	_, err := v.client.BeginDeleteSecret(context.TODO(), s.Name, nil)
 	if err != nil {
 		return err
 	}
+	v.mu.Lock()
 	delete(v.secrets, s.Name)
+	v.mu.Unlock()
 	return nil
 }
 
 // Get Secret From Vault
-// NOTE: we might need to return interface type to deal with different objects
-func (v *SecretsVault) GetItem(secret paw.Item) (*paw.Login, error) {
+func (v *SecretsVault) GetItem(secret paw.Item) (paw.Item, error) {
 	m := secret.GetMetadata()
-	if s, ok := v.secrets[m.Name]; ok {
-		if s.Password.Value != "" {
-			return s, nil
-		}
+	if err := checkName(m.Name); err != nil {
+		return nil, err
+	}
+	v.mu.RLock()
+	s, ok := v.secrets[m.Name]
+	v.mu.RUnlock()
+	if ok && HasValue(s) {
+		return s, nil
 	}
 	rsp, err := v.client.GetSecret(context.TODO(), m.Name, nil)
 	if err != nil {
 		return nil, err
 	}
 	// create or update
-	if _, ok := v.secrets[m.Name]; !ok {
-		v.secrets[m.Name] = NewAzureSecret(rsp.Secret)
-		v.secrets[m.Name].Password.Value = (*rsp.Secret.Value)
-	} else {
-		v.secrets[m.Name].Password.Value = (*rsp.Secret.Value)
+	s = NewAzureSecret(rsp.Secret)
+	value := *rsp.Secret.Value
+	if rsp.Secret.Tags[encryptedTag] != "" {
+		value, err = decryptValue(value, v.identities)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := setValue(s, value, rsp.Secret.Tags); err != nil {
+		return nil, err
 	}
-	return v.secrets[m.Name], nil
+	v.mu.Lock()
+	v.secrets[m.Name] = s
+	v.mu.Unlock()
+	return s, nil
 }
 
 // initialise keyvault objects
@@ -94,6 +318,9 @@ func (v *SecretsVault) getItems() {
 	pager := v.client.ListSecrets(nil)
 	for pager.NextPage(context.TODO()) {
 		for _, s := range pager.PageResponse().Secrets {
+			if IsReservedName(secretName(*s.ID, index)) {
+				continue
+			}
 			v.secrets[secretName(*s.ID, index)] = NewAzureSecret(s)
 		}
 	}
@@ -101,6 +328,8 @@ func (v *SecretsVault) getItems() {
 
 // list secrets from vault
 func (v *SecretsVault) ListItems() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	var secrets []string
 	for name := range v.secrets {
 		secrets = append(secrets, name)
@@ -111,42 +340,86 @@ func (v *SecretsVault) ListItems() []string {
 
 // Save Secret to Vault
 func (v *SecretsVault) AddItem(secret paw.Item) error {
-	s := secret.(*paw.Login)
-	str := s.Username + "|" + s.URL + "|" + s.Note.Value
-	if len(str) > 255 {
-		return fmt.Errorf("Concatenation \"Username|URL|Note\" can have 255 chars Max")
+	if err := checkName(secret.GetMetadata().Name); err != nil {
+		return err
+	}
+	config, err := v.Config()
+	if err != nil {
+		return err
+	}
+	if err := v.checkRecipients(config); err != nil {
+		return err
+	}
+	return v.addItem(secret, config)
+}
+
+// addItem stores the secret, the value is encrypted if required by the vault config
+func (v *SecretsVault) addItem(secret paw.Item, config *VaultConfig) error {
+	value, contentType, tags, err := secretValue(secret)
+	if err != nil {
+		return err
+	}
+	if config.Encryption {
+		value, err = encryptValue(value, config.Recipients)
+		if err != nil {
+			return err
+		}
+		if tags == nil {
+			tags = map[string]string{typeTag: secret.GetMetadata().Type.String()}
+		}
+		tags[encryptedTag] = "true"
 	}
 	optins := &azsecrets.SetSecretOptions{
-		ContentType: &str}
-	result, err := v.client.SetSecret(context.TODO(), s.Metadata.Name,
-		s.Password.Value, optins)
+		ContentType: &contentType,
+		Tags:        tags,
+	}
+	m := secret.GetMetadata()
+	result, err := v.client.SetSecret(context.TODO(), m.Name, value, optins)
 	if err != nil {
 		return err
 	}
 	// update the attributes of the secret
-	v.secrets[s.Metadata.Name] = s
-	v.secrets[s.Metadata.Name].Metadata.Created = *result.Attributes.Created
-	v.secrets[s.Metadata.Name].Metadata.Modified = *result.Attributes.Updated
+	v.mu.Lock()
+	v.secrets[m.Name] = secret
+	v.mu.Unlock()
+	m.Created = *result.Attributes.Created
+	m.Modified = *result.Attributes.Updated
 	return nil
 }
 
 func (v *SecretsVault) Size() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	return len(v.secrets)
 }
 
-func (v *SecretsVault) SizeByType(_ paw.ItemType) int {
-	return v.Size()
+func (v *SecretsVault) SizeByType(itemType paw.ItemType) int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	size := 0
+	for _, secret := range v.secrets {
+		if secret.GetMetadata().Type == itemType {
+			size++
+		}
+	}
+	return size
 }
 
 func (v *SecretsVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata {
 	metadata := []*paw.Metadata{}
 	filter := opt.Name
+	v.mu.RLock()
 	for _, secret := range v.secrets {
-		if filter != "" && !strings.Contains(secret.Name, filter) {
+		m := secret.GetMetadata()
+		if opt.ItemType != 0 && (opt.ItemType&m.Type) == 0 {
+			continue
+		}
+		if filter != "" && !strings.Contains(m.Name, filter) {
 			continue
 		}
-		metadata = append(metadata, secret.Metadata)
+		metadata = append(metadata, m)
 	}
+	v.mu.RUnlock()
 	// if metadata is empty try to get the secret from azure keyvault
 	if len(metadata) == 0 {
 		m := &paw.Metadata{
@@ -154,7 +427,7 @@ func (v *SecretsVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Me
 		}
 		secret, err := v.GetItem(m)
 		if err == nil {
-			metadata = append(metadata, secret.Metadata)
+			metadata = append(metadata, secret.GetMetadata())
 			return metadata
 		}
 	}
@@ -162,6 +435,20 @@ func (v *SecretsVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Me
 	return metadata
 }
 
-func (v *SecretsVault) Key() *paw.Key {
-	return v.key
+// Range calls f sequentially for each secret present in the vault cache.
+// If f returns false, range stops the iteration.
+// NOTE: the secret value is not loaded, use GetItem to retrieve it
+func (v *SecretsVault) Range(f func(name string, item paw.Item) bool) {
+	for _, name := range v.ListItems() {
+		v.mu.RLock()
+		item, ok := v.secrets[name]
+		v.mu.RUnlock()
+		if !ok {
+			// deleted meanwhile
+			continue
+		}
+		if !f(name, item) {
+			break
+		}
+	}
 }
@@ -0,0 +1,197 @@
+// Package breach checks whether a password appears in a data breach using the
+// k-anonymity model of the Pwned Passwords API: the SHA-1 hash of the password
+// is split into a 5 chars prefix and a suffix, only the prefix is used to look up
+// the range of the hashes sharing it, the suffix is then matched locally.
+//
+// Ranges are read from an HTTP endpoint or from a directory of range files,
+// i.e. downloaded in advance, so that the check can run fully offline.
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultURL is the range endpoint of the Pwned Passwords API
+	DefaultURL = "https://api.pwnedpasswords.com/range/"
+	// PrefixLength is the length of the hash prefix used to look up a range
+	PrefixLength = 5
+)
+
+// Source returns the range of the hash suffixes sharing the prefix.
+// Each line of the range is in the SUFFIX:COUNT format.
+type Source interface {
+	Range(prefix string) (io.ReadCloser, error)
+}
+
+// HTTPSource reads the ranges from an HTTP endpoint.
+// Only the hash prefix is sent, appended to the URL.
+type HTTPSource struct {
+	// URL is the range endpoint, i.e. DefaultURL
+	URL string
+	// Client is the HTTP client to use. Default to a client with a 10 seconds timeout
+	Client *http.Client
+}
+
+// Range implements Source
+func (s *HTTPSource) Range(prefix string) (io.ReadCloser, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(s.URL, "/")+"/"+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	// ask for padded responses so that the response size does not disclose the prefix
+	req.Header.Set("Add-Padding", "true")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("range request failed: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// DirSource reads the ranges from a directory containing a file for each prefix,
+// named PREFIX or PREFIX.txt, as produced by the Pwned Passwords downloader.
+type DirSource struct {
+	Dir string
+}
+
+// Range implements Source
+func (s *DirSource) Range(prefix string) (io.ReadCloser, error) {
+	for _, name := range []string{prefix + ".txt", prefix} {
+		f, err := os.Open(filepath.Join(s.Dir, name))
+		if err == nil {
+			return f, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("range file for prefix %s not found in %s", prefix, s.Dir)
+}
+
+// Checker checks the passwords against the breached ones.
+// Ranges are cached so that a vault can be checked with a request per prefix.
+// It is safe for concurrent use.
+type Checker struct {
+	source Source
+
+	// mu guards ranges and calls, it is not held while reading from the source
+	mu     sync.Mutex
+	ranges map[string]map[string]int
+	calls  map[string]*rangeCall
+}
+
+// rangeCall is an in-flight range read shared by the lookups of the same prefix
+type rangeCall struct {
+	done     chan struct{}
+	suffixes map[string]int
+	err      error
+}
+
+// NewChecker returns a checker that reads the ranges from the source
+func NewChecker(source Source) *Checker {
+	return &Checker{
+		source: source,
+		ranges: make(map[string]map[string]int),
+		calls:  make(map[string]*rangeCall),
+	}
+}
+
+// Hash returns the prefix and the suffix of the uppercase hex encoded SHA-1 of the password
+func Hash(password string) (prefix string, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return h[:PrefixLength], h[PrefixLength:]
+}
+
+// Count returns how many times the password appears in the breaches, zero if never
+func (c *Checker) Count(password string) (int, error) {
+	prefix, suffix := Hash(password)
+	suffixes, err := c.lookup(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("could not check the password: %w", err)
+	}
+	return suffixes[suffix], nil
+}
+
+// lookup returns the suffixes of the range, reading it from the source only once.
+// Concurrent lookups of the same prefix wait for the same read.
+func (c *Checker) lookup(prefix string) (map[string]int, error) {
+	c.mu.Lock()
+	if suffixes, ok := c.ranges[prefix]; ok {
+		c.mu.Unlock()
+		return suffixes, nil
+	}
+	if call, ok := c.calls[prefix]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.suffixes, call.err
+	}
+	call := &rangeCall{done: make(chan struct{})}
+	c.calls[prefix] = call
+	c.mu.Unlock()
+
+	call.suffixes, call.err = c.read(prefix)
+
+	c.mu.Lock()
+	delete(c.calls, prefix)
+	if call.err == nil {
+		c.ranges[prefix] = call.suffixes
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return call.suffixes, call.err
+}
+
+// read reads the range from the source
+func (c *Checker) read(prefix string) (map[string]int, error) {
+	rc, err := c.source.Range(prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return parseRange(rc)
+}
+
+// parseRange parses a range. The padding entries, with a zero count, are skipped.
+func parseRange(r io.Reader) (map[string]int, error) {
+	suffixes := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i == -1 {
+			return nil, fmt.Errorf("invalid range line %q", line)
+		}
+		count, err := strconv.Atoi(line[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid range line %q", line)
+		}
+		if count == 0 {
+			continue
+		}
+		suffixes[strings.ToUpper(line[:i])] = count
+	}
+	return suffixes, scanner.Err()
+}
@@ -0,0 +1,126 @@
+package breach
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	prefix, suffix := Hash("password")
+	assert.Equal(t, "5BAA6", prefix)
+	assert.Equal(t, "1E4C9B93F3F0682250B6CF8331B7EE68FD8", suffix)
+}
+
+func TestChecker(t *testing.T) {
+	_, suffix := Hash("password")
+	rangeFile := fmt.Sprintf("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n%s:3861493\r\n011053FD0102E94D6AE2F8B83D76FAF94F6:0\r\n", suffix)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(rangeFile), 0600))
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/range/5BAA6" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, rangeFile)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		source Source
+	}{
+		{name: "dir", source: &DirSource{Dir: dir}},
+		{name: "http", source: &HTTPSource{URL: srv.URL + "/range/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(tt.source)
+
+			count, err := c.Count("password")
+			require.NoError(t, err)
+			assert.Equal(t, 3861493, count)
+
+			// same prefix, served by the cache
+			count, err = c.Count("password")
+			require.NoError(t, err)
+			assert.Equal(t, 3861493, count)
+
+			// the range of another prefix is not available
+			_, err = c.Count("not breached")
+			assert.Error(t, err)
+		})
+	}
+
+	// only the prefixes are sent
+	other, _ := Hash("not breached")
+	assert.Equal(t, []string{"/range/5BAA6", "/range/" + other}, requests)
+}
+
+// blockingSource is a Source that blocks the reads until release is closed
+type blockingSource struct {
+	release chan struct{}
+	reads   chan string
+}
+
+func (s *blockingSource) Range(prefix string) (io.ReadCloser, error) {
+	s.reads <- prefix
+	<-s.release
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func TestCheckerConcurrentLookups(t *testing.T) {
+	source := &blockingSource{release: make(chan struct{}), reads: make(chan string, 4)}
+	c := NewChecker(source)
+
+	errs := make(chan error, 3)
+	for _, password := range []string{"password", "password", "not breached"} {
+		password := password
+		go func() {
+			_, err := c.Count(password)
+			errs <- err
+		}()
+	}
+
+	// the reads of different prefixes do not wait for each other
+	var reads []string
+	for i := 0; i < 2; i++ {
+		select {
+		case prefix := <-source.reads:
+			reads = append(reads, prefix)
+		case <-time.After(time.Second):
+			t.Fatal("the lookups must not be serialized")
+		}
+	}
+	close(source.release)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, <-errs)
+	}
+	// the lookups of the same prefix share the read
+	prefix, _ := Hash("password")
+	other, _ := Hash("not breached")
+	assert.ElementsMatch(t, []string{prefix, other}, reads)
+	assert.Empty(t, source.reads)
+}
+
+func TestParseRange(t *testing.T) {
+	_, err := parseRange(strings.NewReader("invalid"))
+	assert.Error(t, err)
+
+	got, err := parseRange(strings.NewReader("abc:2\nDEF:0\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"ABC": 2}, got)
+}
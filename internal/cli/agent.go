@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"lucor.dev/paw/internal/agent"
+	"lucor.dev/paw/internal/azure"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*AgentCmd)(nil)
+
+// AgentCmd runs and controls the local agent that keeps the vaults unlocked
+type AgentCmd struct {
+	action  string
+	timeout time.Duration
+}
+
+// Name returns the one word command name
+func (cmd *AgentCmd) Name() string {
+	return "agent"
+}
+
+// Description returns the command description
+func (cmd *AgentCmd) Description() string {
+	return "Run the agent that keeps vaults unlocked for the session"
+}
+
+// Usage displays the command usage
+func (cmd *AgentCmd) Usage() {
+	fmt.Fprintf(os.Stderr, `Usage: paw agent [-timeout DURATION] [serve|start|lock|stop]
+
+Runs the agent that holds the Azure credential and the vault caches so that
+CLI invocations and the GUI share one authenticated session.
+The agent listens on a Unix socket accessible only by the current user, the
+path can be overridden by the PAW_AGENT_SOCK environment variable.
+
+Actions:
+  serve   run the agent in the foreground (default)
+  start   run the agent in the background
+  lock    drop the credential and the vault caches
+  stop    stop the agent
+
+Options:
+  -timeout DURATION   lock the agent after being idle for DURATION. Default to %s
+`, agent.DefaultTimeout)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *AgentCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.DurationVar(&cmd.timeout, "timeout", agent.DefaultTimeout, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch fs.NArg() {
+	case 0:
+		cmd.action = "serve"
+	case 1:
+		cmd.action = fs.Arg(0)
+	default:
+		return fmt.Errorf("expected at most one action")
+	}
+	switch cmd.action {
+	case "serve", "start", "lock", "stop":
+		return nil
+	}
+	return fmt.Errorf("invalid action %q", cmd.action)
+}
+
+// Run runs the command
+func (cmd *AgentCmd) Run(conf *azure.Config) error {
+	path, err := agent.SocketPath()
+	if err != nil {
+		return err
+	}
+
+	switch cmd.action {
+	case "serve":
+		// keep running when the terminal is closed
+		signal.Ignore(syscall.SIGHUP)
+		a := agent.New(conf, cmd.timeout)
+		return a.ListenAndServe(path)
+	case "start":
+		if c, err := agent.Dial(path); err == nil {
+			c.Close()
+			return fmt.Errorf("agent already running on %s", path)
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		p := exec.Command(exe, "agent", "-timeout", cmd.timeout.String(), "serve")
+		if err := p.Start(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "paw agent started, pid %d\n", p.Process.Pid)
+		return p.Process.Release()
+	}
+
+	client, err := agent.Dial(path)
+	if err != nil {
+		return fmt.Errorf("agent is not running: %w", err)
+	}
+	defer client.Close()
+	if cmd.action == "lock" {
+		return client.Lock()
+	}
+	return client.Stop()
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+	"lucor.dev/paw/internal/agent"
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// errReported is returned by the commands that already reported the error to the user,
+// i.e. on stdout as required by the docker credential helper protocol
+var errReported = errors.New("error already reported")
+
+// Cmd wraps the methods for a paw cli command
+type Cmd interface {
+	// Name returns the one word command name
+	Name() string
+	// Description returns the command description
+	Description() string
+	// Usage displays the command usage
+	Usage()
+	// Parse parses the arguments into the command flags
+	Parse(args []string) error
+	// Run runs the command using the azure configuration
+	Run(conf *azure.Config) error
+}
+
+// commands returns the list of the available commands, sorted by name
+func commands() []Cmd {
+	cmds := []Cmd{
+		&AgentCmd{},
+		&DockerCredentialCmd{},
+		&EncryptionCmd{},
+		&ExportCmd{},
+		&GetCmd{},
+		&GitCredentialCmd{},
+		&HealthCmd{},
+		&ImportCmd{},
+		&KeyInfoCmd{},
+		&KitCmd{},
+		&LocalCmd{},
+		&MembersCmd{},
+		&OTPCmd{},
+		&PasswdCmd{},
+		&RecoverCmd{},
+		&RegenerateCmd{},
+		&RotateKeyCmd{},
+		&ShamirCmd{},
+		&SSHAgentCmd{},
+		&SSHUnlockCmd{},
+		&StrengthCmd{},
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Name() < cmds[j].Name()
+	})
+	return cmds
+}
+
+// Run runs the command specified by the args
+func Run(args []string) {
+	if len(args) == 0 {
+		usage()
+		os.Exit(1)
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+
+	var cmd Cmd
+	for _, c := range commands() {
+		if c.Name() == name {
+			cmd = c
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "paw: unknown command %q\n\n", name)
+		usage()
+		os.Exit(1)
+	}
+
+	err := cmd.Parse(args[1:])
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "paw %s: %s\n\n", cmd.Name(), err)
+		}
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	conf, err := azure.ReadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "paw %s: could not read the azure configuration: %s\n", cmd.Name(), err)
+		os.Exit(1)
+	}
+
+	// the user wordlists are needed to derive the stateless passphrases
+	if s, err := paw.NewOSStorage(); err == nil {
+		if err := paw.LoadWordlists(paw.WordlistsPath(s)); err != nil {
+			fmt.Fprintf(os.Stderr, "paw: could not load the wordlists: %s\n", err)
+		}
+	}
+
+	err = cmd.Run(conf)
+	if err == errReported {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "paw %s: %s\n", cmd.Name(), err)
+		os.Exit(1)
+	}
+}
+
+// usage displays the cli usage
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: paw [command]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.Name(), c.Description())
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run without arguments to start the graphical interface")
+}
+
+// newFlagSet returns a flag set for the command that does not exit on errors
+func newFlagSet(cmd Cmd) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	fs.Usage = func() {}
+	return fs
+}
+
+// openVaults opens the named key vaults. When no name is specified all the
+// configured vaults are opened.
+// The vaults are served by the agent, if running, otherwise a new Azure
+// authenticated session is created.
+func openVaults(conf *azure.Config, names ...string) ([]azure.Vault, error) {
+	if len(names) == 0 || (len(names) == 1 && names[0] == "") {
+		names = conf.Vaults
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no vault configured, use the -vault flag or open one from the GUI first")
+	}
+
+	var cred azcore.TokenCredential
+	open := func(name string) (azure.Vault, error) {
+		if cred == nil {
+			var err error
+			cred, err = conf.NewCredential()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return azure.NewSecretsVault(name, cred)
+	}
+	if client, err := agent.DialDefault(); err == nil {
+		open = func(name string) (azure.Vault, error) {
+			return agent.NewRemoteVault(client, name)
+		}
+	}
+
+	vaults := make([]azure.Vault, 0, len(names))
+	for _, name := range names {
+		vault, err := open(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not open vault %q: %w", name, err)
+		}
+		vaults = append(vaults, vault)
+	}
+	return vaults, nil
+}
+
+// invalidSecretNameChars matches the chars not allowed into Azure Key Vault secret names
+var invalidSecretNameChars = regexp.MustCompile(`[^0-9a-zA-Z-]+`)
+
+// toSecretName returns a valid Azure Key Vault secret name from s
+func toSecretName(s string) string {
+	name := strings.Trim(invalidSecretNameChars.ReplaceAllString(s, "-"), "-")
+	if len(name) > 127 {
+		name = name[:127]
+	}
+	return name
+}
+
+// hasItem reports whether the vault has an item with the name
+func hasItem(vault azure.Vault, name string) bool {
+	for _, n := range vault.ListItems() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeLogins calls f sequentially for each login present in the vault.
+// If f returns false, range stops the iteration.
+func rangeLogins(vault azure.Vault, f func(name string, login *paw.Login) bool) {
+	vault.Range(func(name string, item paw.Item) bool {
+		login, ok := item.(*paw.Login)
+		if !ok {
+			return true
+		}
+		return f(name, login)
+	})
+}
+
+// getLogin returns the login along with its value from the vault
+func getLogin(vault azure.Vault, item paw.Item) (*paw.Login, error) {
+	v, err := vault.GetItem(item)
+	if err != nil {
+		return nil, err
+	}
+	login, ok := v.(*paw.Login)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a login", item.GetMetadata().Name)
+	}
+	return login, nil
+}
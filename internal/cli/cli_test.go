@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// memVault is an in memory azure.Vault used for testing
+type memVault struct {
+	secrets map[string]paw.Item
+	key     *paw.Key
+}
+
+func newMemVault(items ...paw.Item) *memVault {
+	v := &memVault{secrets: map[string]paw.Item{}}
+	for _, item := range items {
+		v.secrets[item.GetMetadata().Name] = item
+	}
+	return v
+}
+
+func (v *memVault) AddItem(secret paw.Item) error {
+	v.secrets[secret.GetMetadata().Name] = secret
+	return nil
+}
+
+func (v *memVault) DeleteItem(secret paw.Item) error {
+	delete(v.secrets, secret.GetMetadata().Name)
+	return nil
+}
+
+func (v *memVault) GetItem(secret paw.Item) (paw.Item, error) {
+	s, ok := v.secrets[secret.GetMetadata().Name]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return s, nil
+}
+
+func (v *memVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata {
+	return nil
+}
+
+func (v *memVault) Key() (*paw.Key, error) {
+	if v.key == nil {
+		return nil, azure.ErrNoKey
+	}
+	return v.key, nil
+}
+
+func (v *memVault) CreateKey() error {
+	if v.key != nil {
+		return nil
+	}
+	var err error
+	v.key, err = paw.GenerateKey()
+	return err
+}
+
+func (v *memVault) Secret(password *paw.Password, item paw.Item) (string, error) {
+	if !password.Stateless() {
+		return azure.GenerateSecret(nil, password, item)
+	}
+	return azure.GenerateSecret(v.key, password, item)
+}
+
+func (v *memVault) ListItems() []string {
+	var names []string
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v *memVault) Range(f func(name string, item paw.Item) bool) {
+	for _, name := range v.ListItems() {
+		if !f(name, v.secrets[name]) {
+			break
+		}
+	}
+}
+
+func (v *memVault) Size() int {
+	return len(v.secrets)
+}
+
+func (v *memVault) SizeByType(_ paw.ItemType) int {
+	return v.Size()
+}
+
+// newTestLogin returns a login with the URL, username and password
+func newTestLogin(name, url, username, password string) *paw.Login {
+	login := paw.NewLogin()
+	login.Name = name
+	login.URL = url
+	login.Username = username
+	login.Password.Value = password
+	return login
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*DockerCredentialCmd)(nil)
+
+const (
+	// dockerSecretPrefix is the secret name prefix that identifies the registry logins
+	dockerSecretPrefix = "docker-"
+	// DockerCredentialHelperName is the executable name docker expects for the paw helper
+	DockerCredentialHelperName = "docker-credential-paw"
+)
+
+// errDockerCredentialsNotFound is the error message docker expects when no credentials are found
+var errDockerCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// DockerCredentialCmd implements the docker credential helper protocol using
+// the Login items prefixed by "docker-" as storage.
+// See https://github.com/docker/docker-credential-helpers
+type DockerCredentialCmd struct {
+	action string
+}
+
+// dockerCredential represents the credentials exchanged with docker
+type dockerCredential struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// Name returns the one word command name
+func (cmd *DockerCredentialCmd) Name() string {
+	return "docker-credential"
+}
+
+// Description returns the command description
+func (cmd *DockerCredentialCmd) Description() string {
+	return "Docker credential helper backed by Login items"
+}
+
+// Usage displays the command usage
+func (cmd *DockerCredentialCmd) Usage() {
+	fmt.Fprintf(os.Stderr, `Usage: paw docker-credential get|store|erase|list
+
+Implements the docker credential helper protocol. Registry credentials are
+stored as Logins named "%s<registry>" into the vault specified by
+"docker_vault" in the azure configuration, or the first configured vault.
+
+Link the paw executable as %s into the PATH and set
+  "credsStore": "paw"
+into ~/.docker/config.json
+`, dockerSecretPrefix, DockerCredentialHelperName)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *DockerCredentialCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one action")
+	}
+	cmd.action = fs.Arg(0)
+	switch cmd.action {
+	case "get", "store", "erase", "list":
+		return nil
+	}
+	return fmt.Errorf("invalid action %q", cmd.action)
+}
+
+// Run runs the command.
+// Docker reads the error message from stdout, so errors are reported there.
+func (cmd *DockerCredentialCmd) Run(conf *azure.Config) error {
+	err := cmd.run(conf, os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stdout, err)
+		return errReported
+	}
+	return nil
+}
+
+func (cmd *DockerCredentialCmd) run(conf *azure.Config, r io.Reader, w io.Writer) error {
+	var in []byte
+	if cmd.action != "list" {
+		var err error
+		in, err = ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	vaults, err := openVaults(conf, conf.DockerVault)
+	if err != nil {
+		return err
+	}
+	return cmd.exec(vaults[0], in, w)
+}
+
+// exec executes the action using the input read from docker and writes the response to w
+func (cmd *DockerCredentialCmd) exec(vault azure.Vault, in []byte, w io.Writer) error {
+	var err error
+	switch cmd.action {
+	case "get":
+		login := findDockerLogin(vault, string(in))
+		if login == nil {
+			return errDockerCredentialsNotFound
+		}
+		item, err := getLogin(vault, login)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(&dockerCredential{
+			ServerURL: item.URL,
+			Username:  item.Username,
+			Secret:    item.Password.Value,
+		})
+	case "store":
+		cred := &dockerCredential{}
+		if err := json.Unmarshal(in, cred); err != nil {
+			return fmt.Errorf("invalid credentials: %w", err)
+		}
+		if cred.ServerURL == "" {
+			return fmt.Errorf("no credentials server URL")
+		}
+		item := paw.NewLogin()
+		if login := findDockerLogin(vault, cred.ServerURL); login != nil {
+			item, err = getLogin(vault, login)
+			if err != nil {
+				return err
+			}
+		} else {
+			item.Name = toSecretName(dockerSecretPrefix + normalizeDockerServerURL(cred.ServerURL))
+			item.Password.Mode = paw.CustomPassword
+		}
+		item.URL = cred.ServerURL
+		item.Username = cred.Username
+		item.Password.Value = cred.Secret
+		return vault.AddItem(item)
+	case "erase":
+		login := findDockerLogin(vault, string(in))
+		if login == nil {
+			return errDockerCredentialsNotFound
+		}
+		return vault.DeleteItem(login)
+	case "list":
+		list := map[string]string{}
+		rangeLogins(vault, func(name string, login *paw.Login) bool {
+			if strings.HasPrefix(name, dockerSecretPrefix) && login.URL != "" {
+				list[login.URL] = login.Username
+			}
+			return true
+		})
+		return json.NewEncoder(w).Encode(list)
+	}
+	return nil
+}
+
+// findDockerLogin returns the registry login for serverURL, if any
+func findDockerLogin(vault azure.Vault, serverURL string) *paw.Login {
+	want := normalizeDockerServerURL(serverURL)
+	if want == "" {
+		return nil
+	}
+	var found *paw.Login
+	rangeLogins(vault, func(name string, login *paw.Login) bool {
+		if !strings.HasPrefix(name, dockerSecretPrefix) {
+			return true
+		}
+		if normalizeDockerServerURL(login.URL) == want {
+			found = login
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// normalizeDockerServerURL returns the server URL without scheme and trailing slashes
+// so that "https://index.docker.io/v1/" and "index.docker.io/v1" are the same registry
+func normalizeDockerServerURL(serverURL string) string {
+	s := strings.TrimSpace(serverURL)
+	if _, after, ok := cut(s, "://"); ok {
+		s = after
+	}
+	return strings.ToLower(strings.TrimRight(s, "/"))
+}
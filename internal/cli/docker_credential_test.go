@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDockerServerURL(t *testing.T) {
+	tests := []struct {
+		serverURL string
+		want      string
+	}{
+		{serverURL: "https://index.docker.io/v1/", want: "index.docker.io/v1"},
+		{serverURL: "index.docker.io/v1\n", want: "index.docker.io/v1"},
+		{serverURL: "Registry.example.com:5000", want: "registry.example.com:5000"},
+		{serverURL: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.serverURL, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeDockerServerURL(tt.serverURL))
+		})
+	}
+
+	assert.Equal(t, "docker-index-docker-io-v1", toSecretName(dockerSecretPrefix+normalizeDockerServerURL("https://index.docker.io/v1/")))
+}
+
+func TestDockerCredentialActions(t *testing.T) {
+	other := newTestLogin("registry-example-com", "https://registry.example.com", "user", "not-a-docker-login")
+	vault := newMemVault(other)
+	exec := func(action string, in string) (string, error) {
+		buf := &bytes.Buffer{}
+		err := (&DockerCredentialCmd{action: action}).exec(vault, []byte(in), buf)
+		return buf.String(), err
+	}
+
+	_, err := exec("get", "https://index.docker.io/v1/")
+	assert.Equal(t, errDockerCredentialsNotFound, err)
+
+	_, err = exec("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"bot","Secret":"s3cret"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker-index-docker-io-v1", "registry-example-com"}, vault.ListItems())
+
+	out, err := exec("get", "index.docker.io/v1\n")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"https://index.docker.io/v1/","Username":"bot","Secret":"s3cret"}`, out)
+
+	// the existing login is updated
+	_, err = exec("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"bot","Secret":"rotated"}`)
+	require.NoError(t, err)
+	assert.Len(t, vault.ListItems(), 2)
+	out, err = exec("get", "https://index.docker.io/v1/")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"Secret":"rotated"`)
+
+	// only the docker logins are listed
+	out, err = exec("list", "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"https://index.docker.io/v1/":"bot"}`, out)
+
+	_, err = exec("store", `{"Username":"bot"}`)
+	assert.Error(t, err, "no server URL")
+	_, err = exec("store", `invalid`)
+	assert.Error(t, err)
+
+	_, err = exec("erase", "https://index.docker.io/v1/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry-example-com"}, vault.ListItems())
+	_, err = exec("erase", "https://index.docker.io/v1/")
+	assert.Equal(t, errDockerCredentialsNotFound, err)
+	_, err = exec("get", "https://registry.example.com")
+	assert.Equal(t, errDockerCredentialsNotFound, err, "not a docker login")
+}
@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*EncryptionCmd)(nil)
+
+// EncryptionCmd manages the client side encryption of the secret values of a key vault
+type EncryptionCmd struct {
+	vault      string
+	action     string
+	recipients []string
+}
+
+// Name returns the one word command name
+func (cmd *EncryptionCmd) Name() string {
+	return "encryption"
+}
+
+// Description returns the command description
+func (cmd *EncryptionCmd) Description() string {
+	return "Manage the end-to-end encryption of a key vault"
+}
+
+// Usage displays the command usage
+func (cmd *EncryptionCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw encryption identity
+       paw encryption -vault NAME [status | enable [RECIPIENT...] | disable | add RECIPIENT | remove RECIPIENT | trust]
+
+Manages the end-to-end encryption of a key vault. Once enabled, the secret
+values are age encrypted to the team recipients before being stored, so that
+they are not readable by who has only the Key Vault get permission, i.e. the
+subscription admins. The values are decrypted using the local identity.
+
+The recipients are age recipients (age1...) or SSH public keys (ssh-ed25519
+or ssh-rsa), and are stored into the vault config secret. Enabling, disabling
+or changing the recipients rewrites all the secrets, so the local identity
+must be able to decrypt them. An interrupted rewrite is completed by running
+the command again. The vault key used to derive the stateless passwords is
+encrypted as well.
+
+The vault config secret is not authenticated, so the recipients are trusted
+locally: when changed by someone else the secrets are not stored until the
+changes are reviewed with the status action and accepted with the trust action.
+The same applies when the encryption is disabled by someone else, so that the
+secrets are never stored in clear silently. The disable action removes the
+local trust along with the encryption.
+
+Note that the names, the usernames, the URLs and the notes are not encrypted,
+and that removing a recipient does not revoke the access to the values
+already read, the passwords should be rotated as well. The values stored
+before enabling the encryption are still readable from the previous versions
+of the secrets, until purged from the Key Vault.
+
+Actions:
+  identity      print the recipient of the local identity, generating it if missing.
+                Share it with the team to be added to the vault recipients
+  status        print the encryption status and the recipients. Default action
+  enable        enable the encryption, the local identity and the RECIPIENTs
+                are added to the recipients
+  disable       disable the encryption, the secrets are decrypted
+  add           add the RECIPIENT and re-encrypt the secrets
+  remove        remove the RECIPIENT and re-encrypt the secrets
+  trust         trust the current recipients, once reviewed with the status action
+
+Options:
+  -vault NAME   the vault to use
+
+Example:
+  paw encryption -vault team add age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *EncryptionCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cmd.action = fs.Arg(0)
+	if cmd.action == "" {
+		cmd.action = "status"
+	}
+	if cmd.action != "identity" && cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+
+	if fs.NArg() > 1 {
+		cmd.recipients = fs.Args()[1:]
+	}
+	switch cmd.action {
+	case "identity", "status", "disable", "trust":
+		if len(cmd.recipients) > 0 {
+			return fmt.Errorf("unexpected arguments for the %s action", cmd.action)
+		}
+	case "add", "remove":
+		if len(cmd.recipients) != 1 {
+			return fmt.Errorf("the %s action requires a recipient", cmd.action)
+		}
+	case "enable":
+	default:
+		return fmt.Errorf("unknown action %q", cmd.action)
+	}
+	for _, r := range cmd.recipients {
+		if _, err := paw.ParseRecipient(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *EncryptionCmd) Run(conf *azure.Config) error {
+	identities, err := azure.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	local := azure.IdentityRecipients(identities)
+
+	if cmd.action == "identity" {
+		if len(local) == 0 {
+			identity, err := azure.GenerateIdentity()
+			if err != nil {
+				return err
+			}
+			path, _ := azure.IdentityPath()
+			fmt.Fprintf(os.Stderr, "Identity generated into %s, keep a backup of it\n", path)
+			local = []string{identity.Recipient().String()}
+		}
+		fmt.Println(strings.Join(local, "\n"))
+		return nil
+	}
+
+	// the agent is bypassed, the secrets are rewritten using the local identity
+	cred, err := conf.NewCredential()
+	if err != nil {
+		return err
+	}
+	vault, err := azure.NewSecretsVault(cmd.vault, cred)
+	if err != nil {
+		return err
+	}
+	config, err := vault.Config()
+	if err != nil {
+		return err
+	}
+	trusted, err := vault.TrustedRecipients()
+	if err != nil {
+		return err
+	}
+
+	switch cmd.action {
+	case "status":
+		printEncryptionStatus(config, local, trusted)
+		return nil
+	case "trust":
+		if err := vault.TrustConfig(config); err != nil {
+			return err
+		}
+		trusted, err = vault.TrustedRecipients()
+		if err != nil {
+			return err
+		}
+		printEncryptionStatus(config, local, trusted)
+		return nil
+	}
+
+	// the changes made by someone else must be reviewed before rewriting the secrets
+	if untrustedChanges(config, trusted) {
+		return fmt.Errorf("%w, review them with: paw encryption -vault %s status", azure.ErrUntrustedRecipients, cmd.vault)
+	}
+
+	wasEncrypted := config.Encryption
+	switch cmd.action {
+	case "enable":
+		if len(local) == 0 {
+			return fmt.Errorf("no local identity, run: paw encryption identity")
+		}
+		config.Encryption = true
+		config.Recipients = appendRecipients(config.Recipients, local[0])
+		config.Recipients = appendRecipients(config.Recipients, cmd.recipients...)
+	case "disable":
+		config.Encryption = false
+	case "add":
+		config.Recipients = appendRecipients(config.Recipients, cmd.recipients[0])
+	case "remove":
+		config.Recipients = removeRecipient(config.Recipients, cmd.recipients[0])
+	}
+
+	err = vault.SetConfig(config, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rRewriting the secrets %d/%d", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	printEncryptionStatus(config, local, config.Recipients)
+	if config.Encryption && !wasEncrypted {
+		fmt.Fprintln(os.Stderr, "Note: the values stored before enabling the encryption are still readable from the previous versions of the secrets, purge them from the Key Vault or rotate the passwords")
+	}
+	return nil
+}
+
+// printEncryptionStatus prints the encryption status and the recipients,
+// the local identity recipient and the changes since last trusted are marked
+func printEncryptionStatus(config *azure.VaultConfig, local []string, trusted []string) {
+	status := "disabled"
+	if config.Encryption {
+		status = "enabled"
+	}
+	fmt.Printf("Encryption: %s\n", status)
+	fmt.Println("Recipients:")
+	for _, r := range config.Recipients {
+		mark := ""
+		for _, l := range local {
+			if l == r {
+				mark = " (local identity)"
+			}
+		}
+		if trusted != nil && !containsRecipient(trusted, r) {
+			mark += " (added, not trusted)"
+		}
+		fmt.Printf("  %s%s\n", r, mark)
+	}
+	if trusted == nil {
+		return
+	}
+	_, removed := azure.RecipientsChanges(trusted, config.Recipients)
+	for _, r := range removed {
+		fmt.Printf("  %s (removed, not trusted)\n", r)
+	}
+	if !config.Encryption {
+		fmt.Println("The encryption has been disabled since last trusted, once reviewed run the trust action to accept it")
+		return
+	}
+	if untrustedChanges(config, trusted) {
+		fmt.Println("The recipients changed since last trusted, once reviewed run the trust action")
+	}
+}
+
+// untrustedChanges reports whether the config changed since the recipients were trusted locally,
+// the encryption disabled while the recipients are trusted is a change as well
+func untrustedChanges(config *azure.VaultConfig, trusted []string) bool {
+	if trusted == nil {
+		return false
+	}
+	added, removed := azure.RecipientsChanges(trusted, config.Recipients)
+	return !config.Encryption || len(added)+len(removed) > 0
+}
+
+// containsRecipient reports whether r is one of the recipients
+func containsRecipient(recipients []string, r string) bool {
+	for _, v := range recipients {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+// appendRecipients appends the recipients not already present
+func appendRecipients(recipients []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, r := range recipients {
+			if r == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			recipients = append(recipients, v)
+		}
+	}
+	return recipients
+}
+
+// removeRecipient returns the recipients without r
+func removeRecipient(recipients []string, r string) []string {
+	var res []string
+	for _, v := range recipients {
+		if v != r {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+)
+
+func TestEncryptionCmdParse(t *testing.T) {
+	const recipient = "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"
+	tests := []struct {
+		name    string
+		args    []string
+		want    *EncryptionCmd
+		wantErr bool
+	}{
+		{name: "identity", args: []string{"identity"}, want: &EncryptionCmd{action: "identity"}},
+		{name: "status", args: []string{"-vault", "team"}, want: &EncryptionCmd{vault: "team", action: "status"}},
+		{name: "enable", args: []string{"-vault", "team", "enable", recipient}, want: &EncryptionCmd{vault: "team", action: "enable", recipients: []string{recipient}}},
+		{name: "add", args: []string{"-vault", "team", "add", recipient}, want: &EncryptionCmd{vault: "team", action: "add", recipients: []string{recipient}}},
+		{name: "trust", args: []string{"-vault", "team", "trust"}, want: &EncryptionCmd{vault: "team", action: "trust"}},
+		{name: "no vault", args: []string{"status"}, wantErr: true},
+		{name: "add without recipient", args: []string{"-vault", "team", "add"}, wantErr: true},
+		{name: "invalid recipient", args: []string{"-vault", "team", "remove", "age1invalid"}, wantErr: true},
+		{name: "disable with arguments", args: []string{"-vault", "team", "disable", recipient}, wantErr: true},
+		{name: "trust with arguments", args: []string{"-vault", "team", "trust", recipient}, wantErr: true},
+		{name: "unknown action", args: []string{"-vault", "team", "rotate"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &EncryptionCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cmd)
+		})
+	}
+}
+
+func TestRecipientsUpdate(t *testing.T) {
+	recipients := appendRecipients([]string{"age1a"}, "age1b", "age1a", "age1c")
+	assert.Equal(t, []string{"age1a", "age1b", "age1c"}, recipients)
+	assert.Equal(t, []string{"age1a", "age1c"}, removeRecipient(recipients, "age1b"))
+}
+
+func TestUntrustedChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *azure.VaultConfig
+		trusted []string
+		want    bool
+	}{
+		{name: "not trusted yet", config: &azure.VaultConfig{Encryption: true, Recipients: []string{"age1a"}}},
+		{name: "unchanged", config: &azure.VaultConfig{Encryption: true, Recipients: []string{"age1a"}}, trusted: []string{"age1a"}},
+		{name: "recipient added", config: &azure.VaultConfig{Encryption: true, Recipients: []string{"age1a", "age1b"}}, trusted: []string{"age1a"}, want: true},
+		{name: "encryption disabled", config: &azure.VaultConfig{Recipients: []string{"age1a"}}, trusted: []string{"age1a"}, want: true},
+		{name: "disabled accepted", config: &azure.VaultConfig{Recipients: []string{"age1a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, untrustedChanges(tt.config, tt.trusted))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/exporter"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*ExportCmd)(nil)
+
+// ExportCmd exports the vault items
+type ExportCmd struct {
+	names      []string
+	vault      string
+	format     exporter.Format
+	output     string
+	recipients stringsFlag
+	plaintext  bool
+}
+
+// stringsFlag is a flag that can be specified multiple times
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// Name returns the one word command name
+func (cmd *ExportCmd) Name() string {
+	return "export"
+}
+
+// Description returns the command description
+func (cmd *ExportCmd) Description() string {
+	return "Export the vault items"
+}
+
+// Usage displays the command usage
+func (cmd *ExportCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw export -format FORMAT [-vault NAME] [-o FILE] [-r RECIPIENT] [-plaintext] [ITEM...]
+
+Exports the vault items, or only the specified ones, along with their values.
+
+Formats:
+  age         JSON archive encrypted with age, readable with the age tool.
+              Encrypted to the -r recipients or to a passphrase read from the standard input
+  bitwarden   Bitwarden unencrypted JSON
+  csv         CSV with the name, url, username, password, note, totp and folder columns
+  keepass     KeePass 2.x XML
+
+The bitwarden, csv and keepass formats are NOT encrypted and require the
+-plaintext flag to acknowledge it.
+
+Options:
+  -format FORMAT   the export format: age, bitwarden, csv or keepass
+  -vault NAME      the vault to export. Required if more than one vault is configured
+  -o FILE          the output file. Default to the standard output
+  -r RECIPIENT     the age recipient, i.e. age1... Can be repeated
+  -plaintext       acknowledge the export is not encrypted
+
+Example:
+  paw export -format age -r age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p -o vault.json.age
+  age -d -i key.txt -o vault.json vault.json.age`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *ExportCmd) Parse(args []string) error {
+	var format string
+	fs := newFlagSet(cmd)
+	fs.StringVar(&format, "format", "", "")
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.StringVar(&cmd.output, "o", "", "")
+	fs.Var(&cmd.recipients, "r", "")
+	fs.BoolVar(&cmd.plaintext, "plaintext", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cmd.names = fs.Args()
+
+	if format == "" {
+		return fmt.Errorf("the format is required")
+	}
+	var err error
+	cmd.format, err = exporter.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	if cmd.format.Plaintext() && !cmd.plaintext {
+		return fmt.Errorf("%s\nUse the -plaintext flag to acknowledge it", exporter.PlaintextWarning)
+	}
+	if !cmd.format.Plaintext() && cmd.plaintext {
+		return fmt.Errorf("the -plaintext flag is not allowed with the %s format", cmd.format)
+	}
+	if cmd.format != exporter.Age && len(cmd.recipients) > 0 {
+		return fmt.Errorf("recipients are supported only by the age format")
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *ExportCmd) Run(conf *azure.Config) error {
+	name := cmd.vault
+	if name == "" {
+		if len(conf.Vaults) != 1 {
+			return fmt.Errorf("more than one vault configured, use the -vault flag")
+		}
+		name = conf.Vaults[0]
+	}
+
+	opts := exporter.Options{Vault: name, Recipients: cmd.recipients}
+	if cmd.format == exporter.Age && len(cmd.recipients) == 0 {
+		fmt.Fprintln(os.Stderr, "Enter the passphrase to encrypt the archive:")
+		passphrase, err := readPassword(os.Stdin)
+		if err != nil {
+			return err
+		}
+		opts.Passphrase = passphrase
+	}
+
+	vaults, err := openVaults(conf, name)
+	if err != nil {
+		return err
+	}
+	items, err := exporter.Load(vaults[0], cmd.names, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rLoading items %d/%d", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	if cmd.format.Plaintext() {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", exporter.PlaintextWarning)
+	}
+
+	var w io.Writer = os.Stdout
+	if cmd.output != "" {
+		f, err := os.OpenFile(cmd.output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := exporter.Export(w, cmd.format, items, opts); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d items exported\n", len(items))
+	return nil
+}
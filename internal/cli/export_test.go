@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCmdParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "age", args: []string{"-format", "age", "-o", "vault.json.age"}},
+		{name: "age recipients", args: []string{"-format", "age", "-r", "age1a", "-r", "age1b", "github"}},
+		{name: "plaintext acknowledged", args: []string{"-format", "bitwarden", "-plaintext"}},
+		{name: "plaintext not acknowledged", args: []string{"-format", "csv"}, wantErr: true},
+		{name: "plaintext flag with age", args: []string{"-format", "age", "-plaintext"}, wantErr: true},
+		{name: "recipients with plaintext", args: []string{"-format", "keepass", "-plaintext", "-r", "age1a"}, wantErr: true},
+		{name: "missing format", args: []string{}, wantErr: true},
+		{name: "invalid format", args: []string{"-format", "pdf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &ExportCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	cmd := &ExportCmd{}
+	require.NoError(t, cmd.Parse([]string{"-format", "age", "-r", "age1a", "-r", "age1b", "github", "gitlab"}))
+	assert.Equal(t, []string{"age1a", "age1b"}, []string(cmd.recipients))
+	assert.Equal(t, []string{"github", "gitlab"}, cmd.names)
+}
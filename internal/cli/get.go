@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*GetCmd)(nil)
+
+// GetCmd prints a value of an item addressed by reference
+type GetCmd struct {
+	ref   *reference
+	vault string
+}
+
+// Name returns the one word command name
+func (cmd *GetCmd) Name() string {
+	return "get"
+}
+
+// Description returns the command description
+func (cmd *GetCmd) Description() string {
+	return "Print a value of an item"
+}
+
+// Usage displays the command usage
+func (cmd *GetCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw get [-vault NAME] ITEM[#SELECTOR]
+
+Prints the value of the item selected by the reference. Without selector
+the password of logins and passwords, the value of notes, the private key
+of SSH keys and the current code of one-time passwords is printed.
+
+Selectors:
+  #password       the password
+  #username       the login username
+  #url            the login URL
+  #note           the note
+  #otp            the current one-time password
+  #field=LABEL    the custom field value, labels are case insensitive
+
+Options:
+  -vault NAME   the vault to use. Default to all the configured vaults
+
+Example:
+  paw get 'postgres-prod#field=port'`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *GetCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one reference")
+	}
+	ref, err := parseReference(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	cmd.ref = ref
+	return nil
+}
+
+// Run runs the command
+func (cmd *GetCmd) Run(conf *azure.Config) error {
+	vaults, err := openVaults(conf, cmd.vault)
+	if err != nil {
+		return err
+	}
+	for _, vault := range vaults {
+		item, err := vault.GetItem(&paw.Metadata{Name: cmd.ref.Item})
+		if err != nil {
+			continue
+		}
+		v, err := cmd.ref.Value(item)
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	}
+	return fmt.Errorf("item %q not found", cmd.ref.Item)
+}
+
+// reference addresses a value of an item using the ITEM[#SELECTOR] syntax
+type reference struct {
+	Item     string
+	Selector string
+	// Field is the custom field label for the field selector
+	Field string
+}
+
+// parseReference parses the ITEM[#SELECTOR] reference
+func parseReference(s string) (*reference, error) {
+	item, selector, _ := cut(s, "#")
+	if item == "" {
+		return nil, fmt.Errorf("invalid reference %q: item is empty", s)
+	}
+	ref := &reference{Item: item, Selector: selector}
+	switch selector {
+	case "", "password", "username", "url", "note", "otp":
+		return ref, nil
+	}
+	if label := strings.TrimPrefix(selector, "field="); label != selector && label != "" {
+		ref.Selector, ref.Field = "field", label
+		return ref, nil
+	}
+	return nil, fmt.Errorf("invalid reference %q: unknown selector %q", s, selector)
+}
+
+// Value returns the item value selected by the reference
+func (ref *reference) Value(item paw.Item) (string, error) {
+	var v string
+	var ok bool
+	switch ref.Selector {
+	case "":
+		v, ok = defaultValue(item)
+	case "password":
+		switch i := item.(type) {
+		case *paw.Login:
+			v, ok = i.Password.Value, true
+		case *paw.Password:
+			v, ok = i.Value, true
+		}
+	case "username":
+		if i, isLogin := item.(*paw.Login); isLogin {
+			v, ok = i.Username, true
+		}
+	case "url":
+		if i, isLogin := item.(*paw.Login); isLogin {
+			v, ok = i.URL, true
+		}
+	case "note":
+		v, ok = itemNote(item)
+	case "otp":
+		totp, err := itemTOTP(item)
+		if err != nil {
+			return "", err
+		}
+		return totp.Code(time.Now())
+	case "field":
+		if i, isLogin := item.(*paw.Login); isLogin {
+			var f *paw.Field
+			if f, ok = i.Fields.Get(ref.Field); ok {
+				v = f.Value
+			}
+		}
+		if !ok {
+			return "", fmt.Errorf("%q has no field %q", ref.Item, ref.Field)
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("%q is a %s item, selector %q not supported", ref.Item, item.GetMetadata().Type, ref.Selector)
+	}
+	return v, nil
+}
+
+// defaultValue returns the value printed when the reference has no selector
+func defaultValue(item paw.Item) (string, bool) {
+	switch i := item.(type) {
+	case *paw.Login:
+		return i.Password.Value, true
+	case *paw.Password:
+		return i.Value, true
+	case *paw.Note:
+		return i.Value, true
+	case *paw.SSHKey:
+		return i.PrivateKey, true
+	case *paw.TOTP:
+		code, err := i.Code(time.Now())
+		return code, err == nil
+	}
+	return "", false
+}
+
+// itemNote returns the note of the item
+func itemNote(item paw.Item) (string, bool) {
+	var note *paw.Note
+	switch i := item.(type) {
+	case *paw.Login:
+		note = i.Note
+	case *paw.Password:
+		note = i.Note
+	case *paw.Note:
+		note = i
+	case *paw.SSHKey:
+		note = i.Note
+	case *paw.TOTP:
+		note = i.Note
+	default:
+		return "", false
+	}
+	if note == nil {
+		return "", true
+	}
+	return note.Value, true
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    *reference
+		wantErr bool
+	}{
+		{ref: "db", want: &reference{Item: "db"}},
+		{ref: "db#username", want: &reference{Item: "db", Selector: "username"}},
+		{ref: "db#field=port", want: &reference{Item: "db", Selector: "field", Field: "port"}},
+		{ref: "db#field=tenant id", want: &reference{Item: "db", Selector: "field", Field: "tenant id"}},
+		{ref: "#password", wantErr: true},
+		{ref: "db#field=", wantErr: true},
+		{ref: "db#secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := parseReference(tt.ref)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReferenceValue(t *testing.T) {
+	login := paw.NewLogin()
+	login.Name = "db"
+	login.Username = "admin"
+	login.URL = "postgres://db.example.com"
+	login.Password.Value = "secret"
+	login.Note.Value = "production"
+	login.Fields = paw.Fields{
+		{Label: "Host", Value: "db.example.com", Kind: paw.TextField},
+		{Label: "Port", Value: "5432", Kind: paw.TextField},
+	}
+
+	note := paw.NewNote()
+	note.Name = "note"
+	note.Value = "text"
+
+	tests := []struct {
+		ref     string
+		item    paw.Item
+		want    string
+		wantErr bool
+	}{
+		{ref: "db", item: login, want: "secret"},
+		{ref: "db#password", item: login, want: "secret"},
+		{ref: "db#username", item: login, want: "admin"},
+		{ref: "db#url", item: login, want: "postgres://db.example.com"},
+		{ref: "db#note", item: login, want: "production"},
+		{ref: "db#field=port", item: login, want: "5432"},
+		{ref: "db#field=HOST", item: login, want: "db.example.com"},
+		{ref: "db#field=tenant", item: login, wantErr: true},
+		{ref: "db#otp", item: login, wantErr: true},
+		{ref: "note", item: note, want: "text"},
+		{ref: "note#note", item: note, want: "text"},
+		{ref: "note#username", item: note, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, err := parseReference(tt.ref)
+			require.NoError(t, err)
+			got, err := ref.Value(tt.item)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*GitCredentialCmd)(nil)
+
+// GitCredentialCmd implements the git credential helper protocol using the
+// Login items as storage.
+// See https://git-scm.com/docs/git-credential
+type GitCredentialCmd struct {
+	action string
+	vault  string
+}
+
+// Name returns the one word command name
+func (cmd *GitCredentialCmd) Name() string {
+	return "git-credential"
+}
+
+// Description returns the command description
+func (cmd *GitCredentialCmd) Description() string {
+	return "Git credential helper backed by Login items"
+}
+
+// Usage displays the command usage
+func (cmd *GitCredentialCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw git-credential [-vault NAME] get|store|erase
+
+Implements the git credential helper protocol reading the credential
+attributes from stdin. Logins are matched on the URL, username and
+password are returned from the vault.
+
+Options:
+  -vault NAME   the vault to use. The get action searches all the configured
+                vaults if not specified, store and erase use the first one.
+
+Configure git to use paw as credential helper:
+  git config --global credential.helper '!paw git-credential'`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *GitCredentialCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one action")
+	}
+	cmd.action = fs.Arg(0)
+	switch cmd.action {
+	case "get", "store", "erase":
+		return nil
+	}
+	return fmt.Errorf("invalid action %q", cmd.action)
+}
+
+// Run runs the command
+func (cmd *GitCredentialCmd) Run(conf *azure.Config) error {
+	cred, err := readGitCredential(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if cred.Host == "" {
+		return fmt.Errorf("the host attribute is required")
+	}
+
+	names := []string{cmd.vault}
+	if cmd.action != "get" && cmd.vault == "" && len(conf.Vaults) > 0 {
+		names = conf.Vaults[:1]
+	}
+	vaults, err := openVaults(conf, names...)
+	if err != nil {
+		return err
+	}
+
+	switch cmd.action {
+	case "get":
+		return cmd.get(vaults, cred, os.Stdout)
+	case "store":
+		return cmd.store(vaults[0], cred)
+	case "erase":
+		return cmd.erase(vaults[0], cred)
+	}
+	return nil
+}
+
+// get writes the username and password of the best matching login, if any.
+// Not finding a matching login is not an error: git will try the next helper.
+func (cmd *GitCredentialCmd) get(vaults []azure.Vault, cred *gitCredential, w io.Writer) error {
+	vault, login := findGitLogin(vaults, cred)
+	if login == nil {
+		return nil
+	}
+	item, err := getLogin(vault, login)
+	if err != nil {
+		return err
+	}
+	cred.Username = item.Username
+	cred.Password = item.Password.Value
+	return cred.write(w)
+}
+
+// store updates the password of the matching login or creates a new one
+func (cmd *GitCredentialCmd) store(vault azure.Vault, cred *gitCredential) error {
+	if cred.Username == "" || cred.Password == "" {
+		return nil
+	}
+
+	var item *paw.Login
+	_, login := findGitLogin([]azure.Vault{vault}, cred)
+	if login != nil {
+		var err error
+		item, err = getLogin(vault, login)
+		if err != nil {
+			return err
+		}
+		if item.Username == cred.Username && item.Password.Value == cred.Password {
+			return nil
+		}
+	} else {
+		name := cred.secretName()
+		if hasItem(vault, name) {
+			// i.e. a login for the same host created before the username was part of the name
+			return fmt.Errorf("the item %q already exists", name)
+		}
+		item = paw.NewLogin()
+		item.Name = name
+		item.URL = cred.url()
+		item.Password.Mode = paw.CustomPassword
+	}
+	item.Username = cred.Username
+	item.Password.Value = cred.Password
+	return vault.AddItem(item)
+}
+
+// erase deletes the matching login. When the password is specified the login
+// is deleted only if it matches, so a rotated password is never removed.
+func (cmd *GitCredentialCmd) erase(vault azure.Vault, cred *gitCredential) error {
+	_, login := findGitLogin([]azure.Vault{vault}, cred)
+	if login == nil {
+		return nil
+	}
+	item, err := getLogin(vault, login)
+	if err != nil {
+		return err
+	}
+	if cred.Password != "" && item.Password.Value != cred.Password {
+		return nil
+	}
+	return vault.DeleteItem(item)
+}
+
+// findGitLogin returns the login that best matches the credential along with
+// its vault. Vaults are searched in order.
+func findGitLogin(vaults []azure.Vault, cred *gitCredential) (azure.Vault, *paw.Login) {
+	var found *paw.Login
+	var foundVault azure.Vault
+	bestScore := -1
+	for _, vault := range vaults {
+		v := vault
+		rangeLogins(v, func(name string, login *paw.Login) bool {
+			score := cred.match(login.URL, login.Username)
+			if score > bestScore {
+				bestScore = score
+				found = login
+				foundVault = v
+			}
+			return true
+		})
+	}
+	return foundVault, found
+}
+
+// gitCredential represents the attributes exchanged with git
+type gitCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// readGitCredential reads the credential attributes from r until a blank line or EOF.
+func readGitCredential(r io.Reader) (*gitCredential, error) {
+	cred := &gitCredential{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid credential attribute %q", line)
+		}
+		switch key {
+		case "protocol":
+			cred.Protocol = value
+		case "host":
+			cred.Host = value
+		case "path":
+			cred.Path = value
+		case "username":
+			cred.Username = value
+		case "password":
+			cred.Password = value
+		case "url":
+			u, err := url.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid url attribute: %w", err)
+			}
+			cred.Protocol = u.Scheme
+			cred.Host = u.Host
+			cred.Path = strings.TrimPrefix(u.Path, "/")
+			if u.User != nil {
+				cred.Username = u.User.Username()
+				if p, ok := u.User.Password(); ok {
+					cred.Password = p
+				}
+			}
+		}
+	}
+	return cred, scanner.Err()
+}
+
+// write writes the credential attributes to w
+func (c *gitCredential) write(w io.Writer) error {
+	attrs := []struct{ key, value string }{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+	}
+	for _, attr := range attrs {
+		if attr.value == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", attr.key, attr.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match returns a score for the login URL and username against the credential.
+// A negative score means no match, higher scores mean more specific matches.
+func (c *gitCredential) match(rawurl string, username string) int {
+	if rawurl == "" {
+		return -1
+	}
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "//" + rawurl
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return -1
+	}
+	if !strings.EqualFold(u.Host, c.Host) {
+		return -1
+	}
+	if u.Scheme != "" && c.Protocol != "" && u.Scheme != c.Protocol {
+		return -1
+	}
+	if c.Username != "" && username != "" && username != c.Username {
+		return -1
+	}
+
+	path := trimGitPath(u.Path)
+	if path == "" {
+		return 1
+	}
+	reqPath := trimGitPath(c.Path)
+	if reqPath == "" {
+		// the request is not path specific (credential.useHttpPath not set)
+		return 0
+	}
+	if reqPath != path && !strings.HasPrefix(reqPath, path+"/") {
+		return -1
+	}
+	return len(path) + 1
+}
+
+// url returns the URL for the credential
+func (c *gitCredential) url() string {
+	u := &url.URL{
+		Scheme: c.Protocol,
+		Host:   c.Host,
+		Path:   "/" + c.Path,
+	}
+	if c.Path == "" {
+		u.Path = ""
+	}
+	return u.String()
+}
+
+// secretName returns a valid Azure Key Vault secret name for the credential.
+// The username is part of the name, so that the logins of different users of
+// the same host do not overwrite each other.
+func (c *gitCredential) secretName() string {
+	name := "git-" + c.Host
+	if path := trimGitPath(c.Path); path != "" {
+		name += "-" + path
+	}
+	return toSecretName(name + "-" + c.Username)
+}
+
+// trimGitPath normalizes a repository path for comparison
+func trimGitPath(p string) string {
+	return strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+}
+
+// cut slices s around the first instance of sep
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
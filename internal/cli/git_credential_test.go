@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestGitCredentialRoundTrip(t *testing.T) {
+	in := "protocol=https\nhost=git.example.com\npath=org/repo.git\nusername=bot\n\nignored=true\n"
+	cred, err := readGitCredential(strings.NewReader(in))
+	require.NoError(t, err)
+
+	want := &gitCredential{
+		Protocol: "https",
+		Host:     "git.example.com",
+		Path:     "org/repo.git",
+		Username: "bot",
+	}
+	assert.Equal(t, want, cred)
+
+	cred.Password = "secret"
+	var buf bytes.Buffer
+	err = cred.write(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "protocol=https\nhost=git.example.com\npath=org/repo.git\nusername=bot\npassword=secret\n", buf.String())
+}
+
+func TestGitCredentialURLAttribute(t *testing.T) {
+	cred, err := readGitCredential(strings.NewReader("url=https://bot@git.example.com:8443/org/repo\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "https", cred.Protocol)
+	assert.Equal(t, "git.example.com:8443", cred.Host)
+	assert.Equal(t, "org/repo", cred.Path)
+	assert.Equal(t, "bot", cred.Username)
+}
+
+func TestGitCredentialMatch(t *testing.T) {
+	cred := &gitCredential{
+		Protocol: "https",
+		Host:     "git.example.com",
+		Path:     "org/repo.git",
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		username string
+		want     int
+	}{
+		{name: "empty url", url: "", want: -1},
+		{name: "host only", url: "git.example.com", want: 1},
+		{name: "host case insensitive", url: "https://GIT.example.com", want: 1},
+		{name: "different host", url: "https://git.example.org", want: -1},
+		{name: "different protocol", url: "http://git.example.com", want: -1},
+		{name: "path prefix", url: "https://git.example.com/org", want: 4},
+		{name: "exact path", url: "https://git.example.com/org/repo", want: 9},
+		{name: "other path", url: "https://git.example.com/other", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cred.match(tt.url, tt.username))
+		})
+	}
+
+	cred.Username = "bot"
+	assert.Equal(t, -1, cred.match("git.example.com", "someone"))
+	assert.Equal(t, 1, cred.match("git.example.com", "bot"))
+}
+
+func TestGitCredentialSecretName(t *testing.T) {
+	cred := &gitCredential{
+		Protocol: "https",
+		Host:     "git.example.com:8443",
+		Path:     "org/repo.git",
+	}
+	assert.Equal(t, "git-git-example-com-8443-org-repo", cred.secretName())
+
+	cred.Username = "bot@example.com"
+	assert.Equal(t, "git-git-example-com-8443-org-repo-bot-example-com", cred.secretName())
+	assert.Equal(t, "https://git.example.com:8443/org/repo.git", cred.url())
+}
+
+func TestGitCredentialStore(t *testing.T) {
+	cmd := &GitCredentialCmd{}
+	alice := newTestLogin("git-git-example-com", "https://git.example.com", "alice", "alice-secret")
+	vault := newMemVault(alice)
+
+	// a different user of the same host does not overwrite the existing login
+	bob := &gitCredential{Protocol: "https", Host: "git.example.com", Username: "bob", Password: "bob-secret"}
+	require.NoError(t, cmd.store(vault, bob))
+	assert.Equal(t, []string{"git-git-example-com", "git-git-example-com-bob"}, vault.ListItems())
+	assert.Equal(t, "alice-secret", alice.Password.Value)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cmd.get([]azure.Vault{vault}, &gitCredential{Protocol: "https", Host: "git.example.com", Username: "bob"}, buf))
+	assert.Contains(t, buf.String(), "password=bob-secret")
+
+	// the same user updates the password
+	bob.Password = "rotated"
+	require.NoError(t, cmd.store(vault, bob))
+	assert.Len(t, vault.ListItems(), 2)
+	assert.Equal(t, "rotated", vault.secrets["git-git-example-com-bob"].(*paw.Login).Password.Value)
+
+	// an item with the same name that is not a matching login is never overwritten
+	note := paw.NewNote()
+	note.Name = "git-git-example-com-carol"
+	vault.secrets[note.Name] = note
+	carol := &gitCredential{Protocol: "https", Host: "git.example.com", Username: "carol", Password: "carol-secret"}
+	assert.Error(t, cmd.store(vault, carol))
+	assert.Equal(t, note, vault.secrets[note.Name])
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/breach"
+	"lucor.dev/paw/internal/health"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*HealthCmd)(nil)
+
+// HealthCmd reports the weak, reused, stale and not expiring secrets
+type HealthCmd struct {
+	vault     string
+	breachDir string
+	breachURL string
+	format    string
+	days      int
+	minScore  int
+}
+
+// Name returns the one word command name
+func (cmd *HealthCmd) Name() string {
+	return "health"
+}
+
+// Description returns the command description
+func (cmd *HealthCmd) Description() string {
+	return "Report the weak, reused and stale secrets"
+}
+
+// Usage displays the command usage
+func (cmd *HealthCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw health [-vault NAME] [-format text|json|csv] [-days N] [-min-score N]
+                  [-breach-dir DIR | -breach-url URL]
+
+Scans all the items of the vault and reports the weak passwords, the
+passwords reused across secrets, the secrets not modified in N days, the
+secrets without or past their expiry and the logins with a plain HTTP URL.
+The secret values are never printed.
+
+When a breach range directory or endpoint is configured, the passwords found
+in a data breach are reported too. Only the first 5 chars of the SHA-1 hash
+of the passwords are sent to the endpoint.
+
+Options:
+  -vault NAME      the vault to use. Default to all the configured vaults
+  -format FORMAT   the output format: text, json or csv. Default to text
+  -days N          the days after which a secret is reported as not modified. Default to 90
+  -min-score N     the min password strength score, from 0 to 4. Default to 3
+  -breach-dir DIR  the directory of the downloaded range files, to check offline
+  -breach-url URL  the range endpoint, i.e. https://api.pwnedpasswords.com/range/
+
+Example:
+  paw health -format csv > health.csv`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *HealthCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.StringVar(&cmd.format, "format", "text", "")
+	fs.IntVar(&cmd.days, "days", int(health.DefaultMaxAge/(24*time.Hour)), "")
+	fs.IntVar(&cmd.minScore, "min-score", health.DefaultMinScore, "")
+	fs.StringVar(&cmd.breachDir, "breach-dir", "", "")
+	fs.StringVar(&cmd.breachURL, "breach-url", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	switch cmd.format {
+	case "text", "json", "csv":
+	default:
+		return fmt.Errorf("invalid format %q, expected text, json or csv", cmd.format)
+	}
+	if cmd.days < 1 {
+		return fmt.Errorf("days must be a positive number")
+	}
+	if cmd.minScore < 0 || cmd.minScore > 4 {
+		return fmt.Errorf("min score must be between 0 and 4")
+	}
+	if cmd.breachDir != "" && cmd.breachURL != "" {
+		return fmt.Errorf("breach-dir and breach-url are mutually exclusive")
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *HealthCmd) Run(conf *azure.Config) error {
+	names := conf.Vaults
+	if cmd.vault != "" {
+		names = []string{cmd.vault}
+	}
+	vaults, err := openVaults(conf, names...)
+	if err != nil {
+		return err
+	}
+
+	opts := health.Options{
+		MaxAge:   time.Duration(cmd.days) * 24 * time.Hour,
+		MinScore: cmd.minScore,
+		Breach:   conf.BreachChecker(),
+	}
+	switch {
+	case cmd.breachDir != "":
+		opts.Breach = breach.NewChecker(&breach.DirSource{Dir: cmd.breachDir})
+	case cmd.breachURL != "":
+		opts.Breach = breach.NewChecker(&breach.HTTPSource{URL: cmd.breachURL})
+	}
+	reports := make([]*health.Report, 0, len(vaults))
+	for i, vault := range vaults {
+		r, err := health.Scan(names[i], vault, opts, nil)
+		if err != nil {
+			return fmt.Errorf("could not scan vault %q: %w", names[i], err)
+		}
+		reports = append(reports, r)
+	}
+	return writeHealth(os.Stdout, cmd.format, reports)
+}
+
+// writeHealth writes the reports in the specified format
+func writeHealth(w io.Writer, format string, reports []*health.Report) error {
+	switch format {
+	case "json":
+		return health.WriteJSON(w, reports...)
+	case "csv":
+		return health.WriteCSV(w, reports...)
+	}
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := r.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
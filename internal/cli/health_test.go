@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/health"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestHealthCmdParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "default", args: []string{}},
+		{name: "json", args: []string{"-format", "json", "-days", "30"}},
+		{name: "invalid format", args: []string{"-format", "xml"}, wantErr: true},
+		{name: "invalid days", args: []string{"-days", "0"}, wantErr: true},
+		{name: "invalid score", args: []string{"-min-score", "5"}, wantErr: true},
+		{name: "unexpected arg", args: []string{"item"}, wantErr: true},
+		{name: "breach dir", args: []string{"-breach-dir", "/tmp/ranges"}},
+		{name: "breach dir and url", args: []string{"-breach-dir", "/tmp/ranges", "-breach-url", "http://localhost"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &HealthCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWriteHealth(t *testing.T) {
+	login := paw.NewLogin()
+	login.Name = "example.com"
+	login.URL = "http://example.com"
+	login.Password.Value = "password"
+
+	r := health.Check([]paw.Item{login}, health.DefaultOptions())
+	r.Vault = "test"
+
+	var buf bytes.Buffer
+	require.NoError(t, writeHealth(&buf, "csv", []*health.Report{r}))
+	assert.Contains(t, buf.String(), "test,insecure-url,example.com,login,http://example.com\n")
+	assert.NotContains(t, buf.String(), "password")
+
+	buf.Reset()
+	require.NoError(t, writeHealth(&buf, "text", []*health.Report{r}))
+	assert.Contains(t, buf.String(), "Weak passwords (1)\n")
+}
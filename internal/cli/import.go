@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/importer"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*ImportCmd)(nil)
+
+// ImportCmd imports the items exported by other password managers
+type ImportCmd struct {
+	file       string
+	vault      string
+	format     importer.Format
+	prefix     string
+	mapping    map[string]string
+	duplicates importer.Duplicates
+	dryRun     bool
+	yes        bool
+}
+
+// Name returns the one word command name
+func (cmd *ImportCmd) Name() string {
+	return "import"
+}
+
+// Description returns the command description
+func (cmd *ImportCmd) Description() string {
+	return "Import the items exported by other password managers"
+}
+
+// Usage displays the command usage
+func (cmd *ImportCmd) Usage() {
+	formats := make([]string, 0, len(importer.Formats()))
+	for _, f := range importer.Formats() {
+		formats = append(formats, string(f))
+	}
+	fmt.Fprintf(os.Stderr, `Usage: paw import -format FORMAT [-vault NAME] [-prefix PREFIX] [-map MAPPING]
+                  [-duplicates skip|rename|overwrite] [-dry-run] [-y] FILE
+
+Imports the logins, notes and passwords exported by other password managers.
+The planned actions are displayed before writing into the vault. Entries whose
+content is already in the vault are always skipped so that an import can be
+run again.
+
+Formats:
+  bitwarden   Bitwarden unencrypted JSON export
+  keepass     KeePass 2.x XML export
+  1pux        1Password 1PUX export
+  lastpass    LastPass CSV export
+  chrome      Chrome passwords CSV export
+  firefox     Firefox logins CSV export
+  csv         CSV with the name, url, username, password, note, totp and folder columns
+  env         dotenv file, each variable is imported as a password
+
+Options:
+  -format FORMAT     the export format: %s
+  -vault NAME        the vault to import into. Required if more than one vault is configured
+  -prefix PREFIX     the prefix of the secret names, i.e. the team name
+  -map MAPPING       the CSV columns mapping, i.e. name=Title,note=Comments
+  -duplicates MODE   how to handle the names already taken: skip, rename or overwrite. Default to skip
+  -dry-run           display the planned actions without writing into the vault
+  -y                 do not ask for confirmation
+
+Example:
+  paw import -format bitwarden -prefix team-a- -dry-run bitwarden_export.json
+`, strings.Join(formats, ", "))
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *ImportCmd) Parse(args []string) error {
+	var format, mapping, duplicates string
+	fs := newFlagSet(cmd)
+	fs.StringVar(&format, "format", "", "")
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.StringVar(&cmd.prefix, "prefix", "", "")
+	fs.StringVar(&mapping, "map", "", "")
+	fs.StringVar(&duplicates, "duplicates", string(importer.SkipDuplicates), "")
+	fs.BoolVar(&cmd.dryRun, "dry-run", false, "")
+	fs.BoolVar(&cmd.yes, "y", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one file")
+	}
+	cmd.file = fs.Arg(0)
+
+	if format == "" {
+		return fmt.Errorf("the format is required")
+	}
+	var err error
+	cmd.format, err = importer.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	cmd.mapping, err = importer.ParseMapping(mapping)
+	if err != nil {
+		return err
+	}
+	switch d := importer.Duplicates(duplicates); d {
+	case importer.SkipDuplicates, importer.RenameDuplicates, importer.OverwriteDuplicates:
+		cmd.duplicates = d
+	default:
+		return fmt.Errorf("invalid duplicates mode %q, expected skip, rename or overwrite", duplicates)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *ImportCmd) Run(conf *azure.Config) error {
+	name := cmd.vault
+	if name == "" {
+		if len(conf.Vaults) != 1 {
+			return fmt.Errorf("more than one vault configured, use the -vault flag")
+		}
+		name = conf.Vaults[0]
+	}
+
+	f, err := os.Open(cmd.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	res, err := importer.Parse(cmd.format, f, importer.Options{Prefix: cmd.prefix, Mapping: cmd.mapping})
+	if err != nil {
+		return err
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+
+	vaults, err := openVaults(conf, name)
+	if err != nil {
+		return err
+	}
+	vault := vaults[0]
+	steps, err := importer.Plan(res.Entries, vault, cmd.duplicates)
+	if err != nil {
+		return err
+	}
+	printPlan(os.Stdout, steps)
+
+	if cmd.dryRun || !hasChanges(steps) {
+		return nil
+	}
+	if !cmd.yes {
+		ok, err := confirm(os.Stdin, os.Stdout, fmt.Sprintf("Import into vault %q?", name))
+		if err != nil || !ok {
+			return err
+		}
+	}
+	n, err := importer.Apply(vault, steps)
+	fmt.Fprintf(os.Stdout, "%d items imported\n", n)
+	return err
+}
+
+// printPlan prints the planned actions along with a summary
+func printPlan(w io.Writer, steps []*importer.Step) {
+	counts := map[importer.Action]int{}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tNAME\tTYPE\tSOURCE\tREASON")
+	for _, s := range steps {
+		counts[s.Action]++
+		m := s.Item.GetMetadata()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Action, m.Name, m.Type, s.Source, s.Reason)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d to create, %d to overwrite, %d to skip\n", counts[importer.Create], counts[importer.Overwrite], counts[importer.Skip])
+}
+
+// hasChanges reports whether any step writes into the vault
+func hasChanges(steps []*importer.Step) bool {
+	for _, s := range steps {
+		if s.Action != importer.Skip {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm asks for a confirmation reading the answer from r
+func confirm(r io.Reader, w io.Writer, question string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
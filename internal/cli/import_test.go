@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/importer"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestImportCmdParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "bitwarden", args: []string{"-format", "bitwarden", "export.json"}},
+		{name: "csv mapping", args: []string{"-format", "csv", "-map", "name=Title", "-duplicates", "rename", "export.csv"}},
+		{name: "missing format", args: []string{"export.json"}, wantErr: true},
+		{name: "invalid format", args: []string{"-format", "xml", "export.xml"}, wantErr: true},
+		{name: "invalid mapping", args: []string{"-format", "csv", "-map", "title", "export.csv"}, wantErr: true},
+		{name: "invalid duplicates", args: []string{"-format", "env", "-duplicates", "merge", ".env"}, wantErr: true},
+		{name: "missing file", args: []string{"-format", "env"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &ImportCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	note := paw.NewNote()
+	note.Name = "readme"
+	steps := []*importer.Step{
+		{Entry: &importer.Entry{Item: note, Source: "Notes/readme"}, Action: importer.Create},
+		{Entry: &importer.Entry{Item: note, Source: "Notes/readme"}, Action: importer.Skip, Reason: "already imported"},
+	}
+	var buf bytes.Buffer
+	printPlan(&buf, steps)
+	assert.Contains(t, buf.String(), "create  readme  note  Notes/readme")
+	assert.Contains(t, buf.String(), "1 to create, 0 to overwrite, 1 to skip\n")
+	assert.True(t, hasChanges(steps))
+	assert.False(t, hasChanges(steps[1:]))
+}
+
+func TestConfirm(t *testing.T) {
+	var buf bytes.Buffer
+	ok, err := confirm(strings.NewReader("y\n"), &buf, "Import?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Import? [y/N] ", buf.String())
+
+	ok, err = confirm(strings.NewReader(""), &buf, "Import?")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
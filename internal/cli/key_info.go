@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*KeyInfoCmd)(nil)
+
+// KeyInfoCmd displays the key metadata of a local vault
+type KeyInfoCmd struct {
+	vault string
+}
+
+// Name returns the one word command name
+func (cmd *KeyInfoCmd) Name() string {
+	return "key-info"
+}
+
+// Description returns the command description
+func (cmd *KeyInfoCmd) Description() string {
+	return "Display the key metadata of a local vault"
+}
+
+// Usage displays the command usage
+func (cmd *KeyInfoCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw key-info -vault NAME
+
+Displays the metadata of the key of a local vault: the key ID, the public key,
+the creation date and the scrypt work factor. The metadata are read from the
+key file header, so the vault is not unlocked.
+
+Options:
+  -vault NAME   the local vault name`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *KeyInfoCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *KeyInfoCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	info, err := s.(*paw.OSStorage).VaultKeyInfo(cmd.vault)
+	if err != nil {
+		return err
+	}
+	printKeyInfo(os.Stdout, info)
+	return nil
+}
+
+// printKeyInfo prints the key metadata
+func printKeyInfo(w io.Writer, info *paw.KeyInfo) {
+	fmt.Fprintf(w, "ID:          %s\n", info.ID)
+	fmt.Fprintf(w, "Public key:  %s\n", info.PublicKey)
+	if !info.Created.IsZero() {
+		fmt.Fprintf(w, "Created:     %s\n", info.Created.Format(time.RFC3339))
+	}
+	if info.WorkFactor > 0 {
+		fmt.Fprintf(w, "Work factor: %d\n", info.WorkFactor)
+	}
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/kit"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/shamir"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*KitCmd)(nil)
+
+// KitCmd writes the emergency kit of a local vault
+type KitCmd struct {
+	vault     string
+	shares    int
+	threshold int
+	outputDir string
+	format    string
+	unlock    unlockOptions
+}
+
+// Name returns the one word command name
+func (cmd *KitCmd) Name() string {
+	return "kit"
+}
+
+// Description returns the command description
+func (cmd *KitCmd) Description() string {
+	return "Write the emergency kit of a local vault"
+}
+
+// Usage displays the command usage
+func (cmd *KitCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw kit -vault NAME [-i FILE | -ssh-agent] [-shares N -threshold M] [-o DIR] [-format png|text]
+
+Writes the emergency kit of a local vault: a printable page that holds the
+vault key as text and QR code, so that the vault can be recovered using the
+recover command if the device holding the key is lost.
+
+The key can be split into N Shamir shares, one per page, so that any M of them
+are required to recover it while fewer shares reveal nothing about the key.
+
+WARNING: anyone with the kit, or with M shares, can read the vault.
+Print the pages, store them offline and delete the files.
+
+The files are written into DIR, default to the current directory, and are
+named NAME-kit.png or NAME-kit-share-I-of-N.png. Existing files are not
+overwritten.
+
+The password is read from the standard input, unless the vault is unlocked
+using an SSH key.
+
+Options:
+  -vault NAME   the local vault name
+  -i FILE       unlock the vault using the SSH private key FILE
+  -ssh-agent    unlock the vault using the keys held by the ssh-agent
+  -shares N     split the key into N shares, up to 255
+  -threshold M  the number of shares required to recover the key, at least 2
+  -o DIR        the directory where to write the kit
+  -format       the kit format: png or text. Default to png`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *KitCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.shares, "shares", 0, "")
+	fs.IntVar(&cmd.threshold, "threshold", 0, "")
+	fs.StringVar(&cmd.outputDir, "o", ".", "")
+	fs.StringVar(&cmd.format, "format", "png", "")
+	cmd.unlock.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if cmd.format != "png" && cmd.format != "text" {
+		return fmt.Errorf("unsupported format %q", cmd.format)
+	}
+	if cmd.shares == 0 && cmd.threshold == 0 {
+		return nil
+	}
+	if cmd.threshold < 2 || cmd.shares < cmd.threshold || cmd.shares > shamir.MaxShares {
+		return fmt.Errorf("the threshold must be at least 2 and not greater than the shares, up to %d", shamir.MaxShares)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *KitCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	key, err := cmd.unlock.loadKey(storage, cmd.vault)
+	if err != nil {
+		return err
+	}
+	k, err := kit.New(cmd.vault, key, cmd.shares, cmd.threshold)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < k.Pages(); i++ {
+		name := filepath.Join(cmd.outputDir, kitFileName(k, i, cmd.format))
+		if err := writeNewFile(name, func(w io.Writer) error {
+			if cmd.format == "text" {
+				return k.WriteText(w, i)
+			}
+			return k.WritePNG(w, i)
+		}); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Written %s\n", name)
+	}
+	fmt.Fprintf(os.Stderr, "Key ID %s, checksum %s\n", k.KeyID, k.Checksum)
+	return nil
+}
+
+// kitFileName returns the file name of the page i of the kit
+func kitFileName(k *kit.Kit, i int, format string) string {
+	ext := "png"
+	if format == "text" {
+		ext = "txt"
+	}
+	if k.Threshold == 0 {
+		return fmt.Sprintf("%s-kit.%s", k.Vault, ext)
+	}
+	return fmt.Sprintf("%s-kit-share-%d-of-%d.%s", k.Vault, i+1, k.Pages(), ext)
+}
+
+// writeNewFile writes a file readable only by the owner, it fails if the file exists
+func writeNewFile(name string, write func(w io.Writer) error) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	return f.Close()
+}
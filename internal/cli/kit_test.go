@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/kit"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestKitCmdParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "identity", args: []string{"-vault", "team"}},
+		{name: "shares", args: []string{"-vault", "team", "-shares", "5", "-threshold", "3", "-format", "text"}},
+		{name: "no vault", args: []string{"-shares", "3", "-threshold", "2"}, wantErr: true},
+		{name: "threshold too low", args: []string{"-vault", "team", "-shares", "3", "-threshold", "1"}, wantErr: true},
+		{name: "threshold greater than shares", args: []string{"-vault", "team", "-shares", "2", "-threshold", "3"}, wantErr: true},
+		{name: "too many shares", args: []string{"-vault", "team", "-shares", "256", "-threshold", "3"}, wantErr: true},
+		{name: "missing threshold", args: []string{"-vault", "team", "-shares", "3"}, wantErr: true},
+		{name: "format", args: []string{"-vault", "team", "-format", "pdf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&KitCmd{}).Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRecoverCmdParse(t *testing.T) {
+	cmd := &RecoverCmd{}
+	require.NoError(t, cmd.Parse([]string{"-vault", "team", "-force", "share1.txt", "share2.txt"}))
+	assert.Equal(t, "team", cmd.vault)
+	assert.True(t, cmd.force)
+	assert.Equal(t, []string{"share1.txt", "share2.txt"}, cmd.files)
+	assert.Equal(t, paw.DefaultWorkFactor, cmd.workFactor)
+
+	assert.Error(t, (&RecoverCmd{}).Parse([]string{}))
+	assert.Error(t, (&RecoverCmd{}).Parse([]string{"-vault", "team", "-work-factor", "9"}))
+}
+
+func TestKitRoundTrip(t *testing.T) {
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+	k, err := kit.New("team", key, 3, 2)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < k.Pages(); i++ {
+		name := filepath.Join(dir, kitFileName(k, i, "text"))
+		require.NoError(t, writeNewFile(name, func(w io.Writer) error {
+			return k.WriteText(w, i)
+		}))
+		files = append(files, name)
+	}
+	assert.Equal(t, filepath.Join(dir, "team-kit-share-1-of-3.txt"), files[0])
+	fi, err := os.Stat(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// the existing pages are not overwritten
+	assert.Error(t, writeNewFile(files[0], func(w io.Writer) error { return nil }))
+
+	cmd := &RecoverCmd{files: files[1:]}
+	secrets, err := cmd.readSecrets()
+	require.NoError(t, err)
+	got, checksum, err := kit.Recover(secrets)
+	require.NoError(t, err)
+	assert.Equal(t, key.Identity(), got.Identity())
+	assert.Equal(t, k.Checksum, checksum)
+}
+
+func TestKitRecoverCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	s, err := paw.NewOSStorageRooted(home)
+	require.NoError(t, err)
+	storage := s.(*paw.OSStorage)
+
+	setStdin(t, "password\npassword\n")
+	local := &LocalCmd{vault: "team", action: "init", workFactor: paw.MinWorkFactor}
+	require.NoError(t, local.Run(nil))
+	key, err := storage.LoadVaultKey("team", "password")
+	require.NoError(t, err)
+	require.NoError(t, setLocalPassword(storage, "team", key, "db-admin", "s3cret", time.Now()))
+
+	dir := t.TempDir()
+	setStdin(t, "password\n")
+	kitCmd := &KitCmd{vault: "team", shares: 3, threshold: 2, outputDir: dir, format: "text"}
+	require.NoError(t, kitCmd.Run(nil))
+	files, err := filepath.Glob(filepath.Join(dir, "team-kit-share-*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	recoverCmd := &RecoverCmd{vault: "team", files: files[1:], workFactor: paw.MinWorkFactor}
+	assert.Error(t, recoverCmd.Run(nil), "the existing key must not be overwritten")
+
+	setStdin(t, "new password\nnew password\n")
+	recoverCmd.force = true
+	require.NoError(t, recoverCmd.Run(nil))
+
+	_, err = storage.LoadVaultKey("team", "password")
+	assert.Error(t, err)
+	recovered, err := storage.LoadVaultKey("team", "new password")
+	require.NoError(t, err)
+	assert.Equal(t, key.Identity(), recovered.Identity())
+	item, err := storage.LoadVaultItem("team", recovered, paw.PasswordItemType, "db-admin")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", item.(*paw.Password).Value)
+}
+
+// setStdin replaces the standard input read by the commands with the input
+func setStdin(t *testing.T, input string) {
+	old := stdin
+	stdin = bufio.NewReader(strings.NewReader(input))
+	t.Cleanup(func() { stdin = old })
+}
+
+func TestReadSecretLines(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("PAW-SHARE-1ABC\n paw-share-1def \n\ns3cret\n"))
+	secrets, err := readSecretLines(r)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PAW-SHARE-1ABC", "paw-share-1def"}, secrets)
+
+	// the following lines are left to read the password
+	password, err := readPassword(r)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", password)
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*LocalCmd)(nil)
+
+// LocalCmd creates a local vault and manages its password items
+type LocalCmd struct {
+	vault      string
+	action     string
+	name       string
+	workFactor int
+	unlock     unlockOptions
+}
+
+// Name returns the one word command name
+func (cmd *LocalCmd) Name() string {
+	return "local"
+}
+
+// Description returns the command description
+func (cmd *LocalCmd) Description() string {
+	return "Create a local vault and manage its passwords"
+}
+
+// Usage displays the command usage
+func (cmd *LocalCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw local -vault NAME [-work-factor N] init
+       paw local -vault NAME [-i FILE | -ssh-agent] [list | set ITEM | get ITEM]
+
+Creates a local vault and manages its passwords. The vault is stored into
+$HOME/.paw/storage/NAME: the key protected by the vault password into key.age
+and each item, encrypted to the key and to the members, into the items
+directory. See the members, passwd, rotate-key, ssh-unlock and kit commands.
+
+The vault password is read from the standard input, unless the vault is
+unlocked using an SSH key, followed by the password to set, one per line.
+
+Actions:
+  init          create the vault, the password is asked twice
+  list          print the type and the name of the items. Default action
+  set           store the password ITEM, creating or replacing it
+  get           print the value of the password ITEM
+
+Options:
+  -vault NAME   the local vault name
+  -i FILE       unlock the vault using the SSH private key FILE
+  -ssh-agent    unlock the vault using the keys held by the ssh-agent
+  -work-factor N
+                the scrypt work factor that protects the key, from 10 to 22.
+                Each increment doubles the time to unlock. Default to 18
+
+Example:
+  paw local -vault team init
+  paw local -vault team set db-admin`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *LocalCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.workFactor, "work-factor", paw.DefaultWorkFactor, "")
+	cmd.unlock.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if cmd.workFactor < paw.MinWorkFactor || cmd.workFactor > paw.MaxWorkFactor {
+		return fmt.Errorf("the work factor must be between %d and %d", paw.MinWorkFactor, paw.MaxWorkFactor)
+	}
+
+	cmd.action = fs.Arg(0)
+	switch cmd.action {
+	case "":
+		cmd.action = "list"
+		fallthrough
+	case "init", "list":
+		if fs.NArg() > 1 {
+			return fmt.Errorf("unexpected arguments for the %s action", cmd.action)
+		}
+	case "set", "get":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("the %s action requires an item name", cmd.action)
+		}
+		cmd.name = fs.Arg(1)
+	default:
+		return fmt.Errorf("unknown action %q", cmd.action)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *LocalCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	if cmd.action == "init" {
+		password, err := readNewPassword(stdin)
+		if err != nil {
+			return err
+		}
+		key, err := storage.CreateVault(cmd.vault, password, cmd.workFactor)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Vault %q created, key ID %s\n", cmd.vault, key.Info().ID)
+		return nil
+	}
+
+	key, err := cmd.unlock.loadKey(storage, cmd.vault)
+	if err != nil {
+		return err
+	}
+
+	switch cmd.action {
+	case "set":
+		fmt.Fprintf(os.Stderr, "Enter the password of %s:\n", cmd.name)
+		value, err := readPassword(stdin)
+		if err != nil {
+			return err
+		}
+		return setLocalPassword(storage, cmd.vault, key, cmd.name, value, time.Now())
+	case "get":
+		item, err := storage.LoadVaultItem(cmd.vault, key, paw.PasswordItemType, cmd.name)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("item %q not found", cmd.name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(item.(*paw.Password).Value)
+		return nil
+	}
+
+	items, err := storage.VaultItems(cmd.vault, key)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		m := item.GetMetadata()
+		fmt.Printf("%s\t%s\n", m.Type, m.Name)
+	}
+	return nil
+}
+
+// setLocalPassword stores the password item into the local vault, the creation date
+// of an existing item is kept
+func setLocalPassword(storage *paw.OSStorage, vault string, key *paw.Key, name string, value string, now time.Time) error {
+	password := paw.NewCustomPassword()
+	password.Name = name
+	password.Created = now
+	existing, err := storage.LoadVaultItem(vault, key, paw.PasswordItemType, name)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not load %q: %w", name, err)
+	}
+	if existing != nil {
+		password.Created = existing.GetMetadata().Created
+	}
+	password.Modified = now
+	password.Value = value
+	return storage.StoreVaultItem(vault, key, password)
+}
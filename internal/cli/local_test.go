@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestLocalCmdParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantAction string
+		wantErr    bool
+	}{
+		{name: "default list", args: []string{"-vault", "team"}, wantAction: "list"},
+		{name: "init", args: []string{"-vault", "team", "-work-factor", "10", "init"}, wantAction: "init"},
+		{name: "set", args: []string{"-vault", "team", "set", "db"}, wantAction: "set"},
+		{name: "get", args: []string{"-vault", "team", "-ssh-agent", "get", "db"}, wantAction: "get"},
+		{name: "missing vault", args: []string{"init"}, wantErr: true},
+		{name: "missing item", args: []string{"-vault", "team", "get"}, wantErr: true},
+		{name: "invalid work factor", args: []string{"-vault", "team", "-work-factor", "9", "init"}, wantErr: true},
+		{name: "unknown action", args: []string{"-vault", "team", "delete", "db"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &LocalCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, cmd.action)
+		})
+	}
+}
+
+func TestSetLocalPassword(t *testing.T) {
+	s, err := paw.NewOSStorageRooted(t.TempDir())
+	require.NoError(t, err)
+	storage := s.(*paw.OSStorage)
+	key, err := storage.CreateVault("team", "password", paw.MinWorkFactor)
+	require.NoError(t, err)
+
+	created := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, setLocalPassword(storage, "team", key, "db", "s3cret", created))
+	modified := created.Add(time.Hour)
+	require.NoError(t, setLocalPassword(storage, "team", key, "db", "an0ther", modified))
+
+	item, err := storage.LoadVaultItem("team", key, paw.PasswordItemType, "db")
+	require.NoError(t, err)
+	password := item.(*paw.Password)
+	assert.Equal(t, "an0ther", password.Value)
+	assert.True(t, created.Equal(password.Created), "the creation date is kept")
+	assert.True(t, modified.Equal(password.Modified))
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*MembersCmd)(nil)
+
+// MembersCmd manages the recipients of a shared local vault
+type MembersCmd struct {
+	vault     string
+	action    string
+	recipient string
+	unlock    unlockOptions
+}
+
+// Name returns the one word command name
+func (cmd *MembersCmd) Name() string {
+	return "members"
+}
+
+// Description returns the command description
+func (cmd *MembersCmd) Description() string {
+	return "Manage the members of a shared local vault"
+}
+
+// Usage displays the command usage
+func (cmd *MembersCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw members -vault NAME [-i FILE | -ssh-agent] [list | add RECIPIENT | remove RECIPIENT]
+
+Manages the members of a shared local vault. The items are encrypted to all
+the members, so that the vault can be shared, i.e. in a Git repo, without
+sharing the master password.
+
+A member is identified by an age recipient (age1...) or by an SSH public key
+(ssh-ed25519 or ssh-rsa). Adding or removing a member re-encrypts all the
+items, the vault password is read from the standard input unless the vault
+is unlocked using an SSH key, see the ssh-unlock command.
+
+Note that removing a member does not revoke the access to the items
+already shared, the passwords should be rotated as well. A member cannot
+unlock the vault with paw, the items can be decrypted using the member
+identity with the age CLI, i.e. age -d -i ~/.ssh/id_ed25519 items/TYPE/NAME.age
+
+Options:
+  -vault NAME   the local vault name
+  -i FILE       unlock the vault using the SSH private key FILE
+  -ssh-agent    unlock the vault using the keys held by the ssh-agent
+
+Example:
+  paw members -vault team add "$(cat ~/.ssh/id_ed25519.pub)"`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *MembersCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	cmd.unlock.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+
+	cmd.action = fs.Arg(0)
+	switch cmd.action {
+	case "":
+		cmd.action = "list"
+		fallthrough
+	case "list":
+		if fs.NArg() > 1 {
+			return fmt.Errorf("unexpected arguments for the list action")
+		}
+	case "add", "remove":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("the %s action requires a recipient", cmd.action)
+		}
+		cmd.recipient = fs.Arg(1)
+		if _, err := paw.ParseRecipient(cmd.recipient); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown action %q", cmd.action)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *MembersCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	if cmd.action == "list" {
+		recipients, err := storage.VaultRecipients(cmd.vault)
+		if err != nil {
+			return err
+		}
+		return paw.WriteRecipients(os.Stdout, recipients)
+	}
+
+	key, err := cmd.unlock.loadKey(storage, cmd.vault)
+	if err != nil {
+		return err
+	}
+
+	if cmd.action == "add" {
+		err = key.AddRecipient(cmd.recipient)
+	} else {
+		err = key.RemoveRecipient(cmd.recipient)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the items and the recipients are stored together
+	n, err := storage.ReencryptVault(cmd.vault, key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d items re-encrypted to %d recipients\n", n, len(key.Recipients()))
+	return nil
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembersCmdParse(t *testing.T) {
+	recipient := "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"
+	tests := []struct {
+		name       string
+		args       []string
+		wantAction string
+		wantErr    bool
+	}{
+		{name: "default list", args: []string{"-vault", "team"}, wantAction: "list"},
+		{name: "list", args: []string{"-vault", "team", "list"}, wantAction: "list"},
+		{name: "add", args: []string{"-vault", "team", "add", recipient}, wantAction: "add"},
+		{name: "remove", args: []string{"-vault", "team", "remove", recipient}, wantAction: "remove"},
+		{name: "missing vault", args: []string{"list"}, wantErr: true},
+		{name: "missing recipient", args: []string{"-vault", "team", "add"}, wantErr: true},
+		{name: "invalid recipient", args: []string{"-vault", "team", "add", "age1invalid"}, wantErr: true},
+		{name: "unknown action", args: []string{"-vault", "team", "rename"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &MembersCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, cmd.action)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*OTPCmd)(nil)
+
+// OTPCmd prints or sets the one-time password of TOTP and Login items
+type OTPCmd struct {
+	name  string
+	vault string
+	uri   string
+}
+
+// Name returns the one word command name
+func (cmd *OTPCmd) Name() string {
+	return "otp"
+}
+
+// Description returns the command description
+func (cmd *OTPCmd) Description() string {
+	return "Print or set the one-time password of an item"
+}
+
+// Usage displays the command usage
+func (cmd *OTPCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw otp [-vault NAME] [-set URI] ITEM
+
+Prints the current one-time password of the TOTP or Login item.
+
+Options:
+  -vault NAME   the vault to use. Items are searched into all the configured
+                vaults if not specified, -set uses the first one.
+  -set URI      set the otpauth URI, or the base32 secret, of the item.
+                Logins get the TOTP attached, otherwise a TOTP item is created.`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *OTPCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.StringVar(&cmd.uri, "set", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one item")
+	}
+	cmd.name = fs.Arg(0)
+	return nil
+}
+
+// Run runs the command
+func (cmd *OTPCmd) Run(conf *azure.Config) error {
+	names := []string{cmd.vault}
+	if cmd.uri != "" && cmd.vault == "" && len(conf.Vaults) > 0 {
+		names = conf.Vaults[:1]
+	}
+	vaults, err := openVaults(conf, names...)
+	if err != nil {
+		return err
+	}
+
+	if cmd.uri != "" {
+		return cmd.set(vaults[0])
+	}
+	code, err := cmd.code(vaults, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println(code)
+	return nil
+}
+
+// code returns the one-time password of the item named as the command item
+// found first into the vaults
+func (cmd *OTPCmd) code(vaults []azure.Vault, t time.Time) (string, error) {
+	for _, vault := range vaults {
+		if !hasItem(vault, cmd.name) {
+			continue
+		}
+		item, err := vault.GetItem(&paw.Metadata{Name: cmd.name})
+		if err != nil {
+			return "", fmt.Errorf("could not load %q: %w", cmd.name, err)
+		}
+		totp, err := itemTOTP(item)
+		if err != nil {
+			return "", err
+		}
+		return totp.Code(t)
+	}
+	return "", fmt.Errorf("item %q not found", cmd.name)
+}
+
+// set attaches the TOTP to the login named as the command item or creates a TOTP item
+func (cmd *OTPCmd) set(vault azure.Vault) error {
+	totp := &paw.TOTP{}
+	if err := totp.SetURI(cmd.uri); err != nil {
+		return err
+	}
+
+	if !hasItem(vault, cmd.name) {
+		totp.Metadata = &paw.Metadata{Name: cmd.name, Type: paw.TOTPItemType}
+		totp.Note = &paw.Note{}
+		return vault.AddItem(totp)
+	}
+
+	var item paw.Item
+	existing, err := vault.GetItem(&paw.Metadata{Name: cmd.name})
+	if err != nil {
+		return fmt.Errorf("could not load %q: %w", cmd.name, err)
+	}
+	switch v := existing.(type) {
+	case *paw.Login:
+		v.TOTP = totp
+		item = v
+	case *paw.TOTP:
+		totp.Metadata, totp.Note = v.Metadata, v.Note
+		item = totp
+	default:
+		return fmt.Errorf("%q is a %s item, expected login or totp", cmd.name, existing.GetMetadata().Type)
+	}
+	return vault.AddItem(item)
+}
+
+// itemTOTP returns the TOTP of the item
+func itemTOTP(item paw.Item) (*paw.TOTP, error) {
+	switch v := item.(type) {
+	case *paw.TOTP:
+		return v, nil
+	case *paw.Login:
+		if v.TOTP != nil && v.TOTP.Secret != "" {
+			return v.TOTP, nil
+		}
+	}
+	return nil, fmt.Errorf("%q has no one-time password", item.GetMetadata().Name)
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestItemTOTP(t *testing.T) {
+	totp := paw.NewTOTP()
+	require.NoError(t, totp.SetURI("otpauth://totp/admin?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ"))
+
+	login := paw.NewLogin()
+	login.Name = "admin"
+
+	tests := []struct {
+		name    string
+		item    paw.Item
+		want    *paw.TOTP
+		wantErr bool
+	}{
+		{name: "totp", item: totp, want: totp},
+		{name: "login without totp", item: login, wantErr: true},
+		{name: "login with totp", item: &paw.Login{Metadata: login.Metadata, TOTP: totp}, want: totp},
+		{name: "login with placeholder", item: &paw.Login{Metadata: login.Metadata, TOTP: &paw.TOTP{}}, wantErr: true},
+		{name: "note", item: paw.NewNote(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := itemTOTP(tt.item)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// failingVault is a memVault that fails to load the items
+type failingVault struct {
+	*memVault
+}
+
+func (v *failingVault) GetItem(secret paw.Item) (paw.Item, error) {
+	return nil, errors.New("forbidden")
+}
+
+func TestOTPSet(t *testing.T) {
+	uri := "otpauth://totp/admin?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ"
+
+	t.Run("new item", func(t *testing.T) {
+		vault := newMemVault()
+		cmd := &OTPCmd{name: "admin", uri: uri}
+		require.NoError(t, cmd.set(vault))
+		item, err := vault.GetItem(&paw.Metadata{Name: "admin"})
+		require.NoError(t, err)
+		assert.Equal(t, paw.TOTPItemType, item.GetMetadata().Type)
+	})
+
+	t.Run("login", func(t *testing.T) {
+		login := newTestLogin("admin", "https://example.com", "admin", "s3cret")
+		vault := newMemVault(login)
+		cmd := &OTPCmd{name: "admin", uri: uri}
+		require.NoError(t, cmd.set(vault))
+		item, err := vault.GetItem(&paw.Metadata{Name: "admin"})
+		require.NoError(t, err)
+		require.IsType(t, &paw.Login{}, item)
+		assert.Equal(t, "s3cret", item.(*paw.Login).Password.Value)
+		assert.NotNil(t, item.(*paw.Login).TOTP)
+	})
+
+	t.Run("load error", func(t *testing.T) {
+		login := newTestLogin("admin", "https://example.com", "admin", "s3cret")
+		vault := &failingVault{newMemVault(login)}
+		cmd := &OTPCmd{name: "admin", uri: uri}
+		assert.Error(t, cmd.set(vault))
+		assert.Equal(t, login, vault.secrets["admin"], "the existing item must not be overwritten")
+	})
+}
+
+func TestOTPCode(t *testing.T) {
+	totp := paw.NewTOTP()
+	totp.Name = "admin"
+	require.NoError(t, totp.SetURI("otpauth://totp/admin?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ"))
+	now := time.Now()
+	want, err := totp.Code(now)
+	require.NoError(t, err)
+
+	cmd := &OTPCmd{name: "admin"}
+	got, err := cmd.code([]azure.Vault{newMemVault(), newMemVault(totp)}, now)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = (&OTPCmd{name: "missing"}).code([]azure.Vault{newMemVault(totp)}, now)
+	assert.EqualError(t, err, `item "missing" not found`)
+
+	// the load errors are not reported as not found
+	_, err = cmd.code([]azure.Vault{&failingVault{newMemVault(totp)}}, now)
+	assert.EqualError(t, err, `could not load "admin": forbidden`)
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*PasswdCmd)(nil)
+
+// PasswdCmd changes the password that protects the key of a local vault
+type PasswdCmd struct {
+	vault      string
+	workFactor int
+	unlock     unlockOptions
+}
+
+// Name returns the one word command name
+func (cmd *PasswdCmd) Name() string {
+	return "passwd"
+}
+
+// Description returns the command description
+func (cmd *PasswdCmd) Description() string {
+	return "Change the password of a local vault"
+}
+
+// Usage displays the command usage
+func (cmd *PasswdCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw passwd -vault NAME [-i FILE | -ssh-agent] [-work-factor N]
+
+Changes the password, or the scrypt work factor, that protects the key of a
+local vault. The key does not change, so the items are not re-encrypted. Use
+the rotate-key command if the key could be compromised.
+
+A higher work factor is recommended on shared workstations, a lower one can be
+used to speed up the unlock, i.e. in CI.
+
+The current and the new password are read from the standard input, one per line.
+The current password is not required if the vault is unlocked using an SSH key.
+
+Options:
+  -vault NAME   the local vault name
+  -i FILE       unlock the vault using the SSH private key FILE
+  -ssh-agent    unlock the vault using the keys held by the ssh-agent
+  -work-factor N
+                the scrypt work factor that protects the key, from 10 to 22.
+                Each increment doubles the time to unlock. Default to 18`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *PasswdCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.workFactor, "work-factor", paw.DefaultWorkFactor, "")
+	cmd.unlock.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if cmd.workFactor < paw.MinWorkFactor || cmd.workFactor > paw.MaxWorkFactor {
+		return fmt.Errorf("the work factor must be between %d and %d", paw.MinWorkFactor, paw.MaxWorkFactor)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *PasswdCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	key, err := cmd.unlock.loadKey(storage, cmd.vault)
+	if err != nil {
+		return err
+	}
+	password, err := readNewPassword(stdin)
+	if err != nil {
+		return err
+	}
+	if err := storage.StoreVaultKey(cmd.vault, key, password, cmd.workFactor); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Password changed")
+	return nil
+}
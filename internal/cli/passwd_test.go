@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestReadNewPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "match", input: "s3cret\ns3cret\n", want: "s3cret"},
+		{name: "mismatch", input: "s3cret\nsecret\n", wantErr: true},
+		{name: "empty", input: "\n\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readNewPassword(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPasswdCmdParse(t *testing.T) {
+	cmd := &PasswdCmd{}
+	require.NoError(t, cmd.Parse([]string{"-vault", "team", "-i", "id_ed25519"}))
+	assert.Equal(t, "team", cmd.vault)
+	assert.Equal(t, "id_ed25519", cmd.unlock.identity)
+
+	assert.Error(t, (&PasswdCmd{}).Parse([]string{}))
+	assert.Error(t, (&RotateKeyCmd{}).Parse([]string{"-y"}))
+	require.NoError(t, (&RotateKeyCmd{}).Parse([]string{"-vault", "team", "-ssh-agent", "-y"}))
+}
+
+func TestWorkFactorFlag(t *testing.T) {
+	cmd := &PasswdCmd{}
+	require.NoError(t, cmd.Parse([]string{"-vault", "ci"}))
+	assert.Equal(t, paw.DefaultWorkFactor, cmd.workFactor)
+
+	cmd = &PasswdCmd{}
+	require.NoError(t, cmd.Parse([]string{"-vault", "ci", "-work-factor", "10"}))
+	assert.Equal(t, 10, cmd.workFactor)
+
+	assert.Error(t, (&PasswdCmd{}).Parse([]string{"-vault", "ci", "-work-factor", "9"}))
+	assert.Error(t, (&RotateKeyCmd{}).Parse([]string{"-vault", "ci", "-work-factor", "23"}))
+}
+
+func TestPrintKeyInfo(t *testing.T) {
+	buf := &bytes.Buffer{}
+	key, err := paw.MakeKey("secret", paw.MinWorkFactor, buf)
+	require.NoError(t, err)
+	info, err := paw.ReadKeyInfo(buf)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	printKeyInfo(out, info)
+	assert.Contains(t, out.String(), info.ID)
+	assert.Contains(t, out.String(), key.Recipients()[0])
+	assert.Contains(t, out.String(), "Work factor: 10")
+}
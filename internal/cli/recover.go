@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/kit"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*RecoverCmd)(nil)
+
+// RecoverCmd recovers the key of a local vault from the emergency kit
+type RecoverCmd struct {
+	vault      string
+	files      []string
+	force      bool
+	workFactor int
+}
+
+// Name returns the one word command name
+func (cmd *RecoverCmd) Name() string {
+	return "recover"
+}
+
+// Description returns the command description
+func (cmd *RecoverCmd) Description() string {
+	return "Recover the key of a local vault from the emergency kit"
+}
+
+// Usage displays the command usage
+func (cmd *RecoverCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw recover -vault NAME [-work-factor N] [-force] [FILE...]
+
+Recovers the key of a local vault from the emergency kit written by the kit
+command and protects it with a new password. The vault items must be restored
+into the vault directory, i.e. from a backup.
+
+The identity or the shares are read from the text pages of the kit FILE, or
+typed into the standard input one per line followed by an empty line. Spaces,
+dashes and case are ignored, and typos are detected by the checksum.
+Compare the printed key ID and checksum with the ones of the kit.
+
+The new password is then read from the standard input, one per line.
+
+Options:
+  -vault NAME   the local vault name
+  -work-factor N
+                the scrypt work factor that protects the key, from 10 to 22.
+                Each increment doubles the time to unlock. Default to 18
+  -force        overwrite the existing key of the vault`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *RecoverCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.workFactor, "work-factor", paw.DefaultWorkFactor, "")
+	fs.BoolVar(&cmd.force, "force", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if cmd.workFactor < paw.MinWorkFactor || cmd.workFactor > paw.MaxWorkFactor {
+		return fmt.Errorf("the work factor must be between %d and %d", paw.MinWorkFactor, paw.MaxWorkFactor)
+	}
+	cmd.files = fs.Args()
+	return nil
+}
+
+// Run runs the command
+func (cmd *RecoverCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	if _, err := storage.VaultKeyInfo(cmd.vault); err == nil && !cmd.force {
+		return fmt.Errorf("the vault %q has already a key, use -force to overwrite it", cmd.vault)
+	}
+
+	secrets, err := cmd.readSecrets()
+	if err != nil {
+		return err
+	}
+	key, checksum, err := kit.Recover(secrets)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Recovered key ID %s, checksum %s\n", key.Info().ID, checksum)
+
+	password, err := readNewPassword(stdin)
+	if err != nil {
+		return err
+	}
+	if err := storage.StoreVaultKey(cmd.vault, key, password, cmd.workFactor); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Key recovered")
+	return nil
+}
+
+// readSecrets reads the secrets from the kit files or, if none, from the standard input
+// until an empty line
+func (cmd *RecoverCmd) readSecrets() ([]string, error) {
+	if len(cmd.files) == 0 {
+		fmt.Fprintln(os.Stderr, "Enter the identity or the shares, one per line, followed by an empty line:")
+		return readSecretLines(stdin)
+	}
+
+	var secrets []string
+	for _, name := range cmd.files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := kit.ReadSecrets(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no identity or share found into %s", name)
+		}
+		secrets = append(secrets, v...)
+	}
+	return secrets, nil
+}
+
+// readSecretLines reads the secrets one per line until an empty line,
+// so that the following lines of r are left to read the new password
+func readSecretLines(r *bufio.Reader) ([]string, error) {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+		if err == io.EOF {
+			break
+		}
+	}
+	return kit.ReadSecrets(strings.NewReader(strings.Join(lines, "\n")))
+}
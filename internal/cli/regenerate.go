@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*RegenerateCmd)(nil)
+
+// RegenerateCmd prints a stateless password deriving it from the vault key
+type RegenerateCmd struct {
+	name    string
+	vault   string
+	counter int
+}
+
+// Name returns the one word command name
+func (cmd *RegenerateCmd) Name() string {
+	return "regenerate"
+}
+
+// Description returns the command description
+func (cmd *RegenerateCmd) Description() string {
+	return "Print a stateless password without reading it from the vault"
+}
+
+// Usage displays the command usage
+func (cmd *RegenerateCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw regenerate [-vault NAME] [-counter N] ITEM
+
+Derives again the stateless password of the Login or Password item from the
+vault key, the item name and the counter. Only the item options are read,
+the stored secret value is not.
+
+Options:
+  -vault NAME   the vault to use. Default to all the configured vaults
+  -counter N    the counter to use instead of the item one, i.e. to recover
+                the password before a rotation`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *RegenerateCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.counter, "counter", 0, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one item")
+	}
+	if cmd.counter < 0 {
+		return fmt.Errorf("counter must be a positive number")
+	}
+	cmd.name = fs.Arg(0)
+	return nil
+}
+
+// Run runs the command
+func (cmd *RegenerateCmd) Run(conf *azure.Config) error {
+	vaults, err := openVaults(conf, cmd.vault)
+	if err != nil {
+		return err
+	}
+	for _, vault := range vaults {
+		// the vault cache holds the items without the secret value
+		var item paw.Item
+		vault.Range(func(name string, i paw.Item) bool {
+			if name == cmd.name {
+				item = i
+				return false
+			}
+			return true
+		})
+		if item == nil {
+			continue
+		}
+		secret, err := statelessPassword(vault, item, cmd.counter)
+		if err != nil {
+			return err
+		}
+		fmt.Println(secret)
+		return nil
+	}
+	return fmt.Errorf("item %q not found", cmd.name)
+}
+
+// statelessPassword derives the stateless password of the item.
+// The counter, if greater than zero, overrides the item one.
+func statelessPassword(vault azure.Vault, item paw.Item, counter int) (string, error) {
+	var password *paw.Password
+	switch i := item.(type) {
+	case *paw.Login:
+		password = i.Password
+	case *paw.Password:
+		password = i
+	}
+	name := item.GetMetadata().Name
+	if password == nil || password.Mode != paw.StatelessPassword {
+		return "", fmt.Errorf("%q has no stateless password", name)
+	}
+	if counter > 0 {
+		p := *password
+		p.Counter = counter
+		password = &p
+	}
+	return vault.Secret(password, item)
+}
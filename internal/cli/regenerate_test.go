@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestStatelessPassword(t *testing.T) {
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+	vault := &memVault{secrets: map[string]paw.Item{}, key: key}
+
+	login := paw.NewLogin()
+	login.Name = "example.com"
+	login.Password = paw.NewStatelessPassword()
+
+	want, err := key.Secret(login.Password.SeederFor(login))
+	require.NoError(t, err)
+
+	// the item returned by the vault listing has no value
+	listed := paw.NewLogin()
+	listed.Name = "example.com"
+	listed.Password = paw.NewStatelessPassword()
+
+	random := paw.NewLogin()
+	random.Name = "random"
+	random.Password = paw.NewRandomPassword()
+
+	tests := []struct {
+		name    string
+		vault   azure.Vault
+		item    paw.Item
+		counter int
+		wantErr bool
+	}{
+		{name: "stateless login", vault: vault, item: listed},
+		{name: "random login", vault: vault, item: random, wantErr: true},
+		{name: "no vault key", vault: newMemVault(), item: listed, wantErr: true},
+		{name: "note", vault: vault, item: paw.NewNote(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := statelessPassword(tt.vault, tt.item, tt.counter)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("counter", func(t *testing.T) {
+		rotated, err := statelessPassword(vault, listed, 2)
+		require.NoError(t, err)
+		assert.NotEqual(t, want, rotated)
+		assert.Equal(t, paw.StatelessPasswordDefaultCounter, listed.Password.Counter)
+
+		listed.Password.Counter = 2
+		got, err := statelessPassword(vault, listed, 0)
+		require.NoError(t, err)
+		assert.Equal(t, rotated, got)
+	})
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*RotateKeyCmd)(nil)
+
+// RotateKeyCmd rotates the key of a local vault
+type RotateKeyCmd struct {
+	vault      string
+	yes        bool
+	workFactor int
+	unlock     unlockOptions
+}
+
+// Name returns the one word command name
+func (cmd *RotateKeyCmd) Name() string {
+	return "rotate-key"
+}
+
+// Description returns the command description
+func (cmd *RotateKeyCmd) Description() string {
+	return "Rotate the key of a local vault"
+}
+
+// Usage displays the command usage
+func (cmd *RotateKeyCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw rotate-key -vault NAME [-i FILE | -ssh-agent] [-work-factor N] [-y]
+
+Generates a new key for a local vault and re-encrypts all the items to it,
+i.e. when the key could be compromised. The members of a shared vault are kept.
+
+The rotation is staged and committed only once all the items have been
+re-encrypted. An interrupted rotation is rolled back, or completed if already
+committed, the next time the vault is unlocked.
+
+WARNING: the stateless passwords are derived from the key, so they change as
+well and must be updated on the related services. The key wrapped to the SSH
+keys is removed, run the ssh-unlock command again to restore it.
+
+The current and the new password are read from the standard input, one per line.
+The current password is not required if the vault is unlocked using an SSH key.
+
+Options:
+  -vault NAME   the local vault name
+  -i FILE       unlock the vault using the SSH private key FILE
+  -ssh-agent    unlock the vault using the keys held by the ssh-agent
+  -work-factor N
+                the scrypt work factor that protects the key, from 10 to 22.
+                Each increment doubles the time to unlock. Default to 18
+  -y            do not ask for confirmation`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *RotateKeyCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.workFactor, "work-factor", paw.DefaultWorkFactor, "")
+	fs.BoolVar(&cmd.yes, "y", false, "")
+	cmd.unlock.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	if cmd.workFactor < paw.MinWorkFactor || cmd.workFactor > paw.MaxWorkFactor {
+		return fmt.Errorf("the work factor must be between %d and %d", paw.MinWorkFactor, paw.MaxWorkFactor)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *RotateKeyCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	key, err := cmd.unlock.loadKey(storage, cmd.vault)
+	if err != nil {
+		return err
+	}
+	password, err := readNewPassword(stdin)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.yes {
+		ok, err := confirm(stdin, os.Stderr, "The stateless passwords will change. Rotate the key?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	newKey, err := storage.RotateVaultKey(cmd.vault, key, password, cmd.workFactor)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Key rotated, the new public key is %s\n", newKey.Recipients()[0])
+	return nil
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/kit"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/shamir"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*ShamirCmd)(nil)
+
+// ShamirCmd splits a secret value into Shamir shares and combines them back
+type ShamirCmd struct {
+	action    string
+	vault     string
+	shares    int
+	threshold int
+	outputDir string
+	ref       *reference
+	files     []string
+}
+
+// Name returns the one word command name
+func (cmd *ShamirCmd) Name() string {
+	return "shamir"
+}
+
+// Description returns the command description
+func (cmd *ShamirCmd) Description() string {
+	return "Split a secret into shares and combine them back"
+}
+
+// Usage displays the command usage
+func (cmd *ShamirCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw shamir [-vault NAME] -shares N -threshold M [-o DIR] split [ITEM[#SELECTOR]]
+       paw shamir combine [FILE...]
+
+Splits a secret value into N Shamir shares, so that any M of them are required
+to combine the secret while fewer shares reveal nothing about it, i.e. a
+break-glass password held jointly by several people.
+
+The split action splits the value of the item selected by the reference, see
+the get command, or the value read from the standard input. The shares are
+printed one per line, or written into DIR as NAME-share-I-of-N.txt files.
+Use the kit command to split the key of a local vault.
+
+The combine action reads the shares from the FILEs, or typed into the standard
+input one per line followed by an empty line, and prints the secret. Spaces,
+dashes and case are ignored, and typos are detected by the checksum.
+
+Options:
+  -vault NAME   the vault to use. Default to all the configured vaults
+  -shares N     the number of shares, up to 255
+  -threshold M  the number of shares required to combine the secret, at least 2
+  -o DIR        the directory where to write the shares
+
+Example:
+  paw shamir -shares 5 -threshold 3 -o /media/usb split domain-admin
+  paw shamir combine domain-admin-share-1-of-5.txt domain-admin-share-4-of-5.txt domain-admin-share-5-of-5.txt`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *ShamirCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.IntVar(&cmd.shares, "shares", 0, "")
+	fs.IntVar(&cmd.threshold, "threshold", 0, "")
+	fs.StringVar(&cmd.outputDir, "o", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cmd.action = fs.Arg(0)
+	switch cmd.action {
+	case "split":
+		if fs.NArg() > 2 {
+			return fmt.Errorf("unexpected arguments for the split action")
+		}
+		if cmd.threshold < 2 || cmd.shares < cmd.threshold || cmd.shares > shamir.MaxShares {
+			return fmt.Errorf("the threshold must be at least 2 and not greater than the shares, up to %d", shamir.MaxShares)
+		}
+		if fs.NArg() == 2 {
+			ref, err := parseReference(fs.Arg(1))
+			if err != nil {
+				return err
+			}
+			cmd.ref = ref
+		}
+	case "combine":
+		cmd.files = fs.Args()[1:]
+	case "":
+		return fmt.Errorf("the action is required")
+	default:
+		return fmt.Errorf("unknown action %q", cmd.action)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *ShamirCmd) Run(conf *azure.Config) error {
+	if cmd.action == "combine" {
+		return cmd.combine()
+	}
+	return cmd.split(conf)
+}
+
+// split splits the secret value and writes the shares
+func (cmd *ShamirCmd) split(conf *azure.Config) error {
+	name, secret, err := cmd.secret(conf)
+	if err != nil {
+		return err
+	}
+	shares, err := shamir.Split([]byte(secret), cmd.shares, cmd.threshold)
+	if err != nil {
+		return err
+	}
+
+	for i, share := range shares {
+		v, err := share.Encode()
+		if err != nil {
+			return err
+		}
+		if cmd.outputDir == "" {
+			fmt.Println(v)
+			continue
+		}
+		path := filepath.Join(cmd.outputDir, fmt.Sprintf("%s-share-%d-of-%d.txt", name, i+1, len(shares)))
+		if err := writeNewFile(path, func(w io.Writer) error {
+			return writeShare(w, name, share, v, len(shares))
+		}); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Written %s\n", path)
+	}
+	return nil
+}
+
+// secret returns the name and the value of the secret to split
+func (cmd *ShamirCmd) secret(conf *azure.Config) (string, string, error) {
+	if cmd.ref == nil {
+		fmt.Fprintln(os.Stderr, "Enter the secret:")
+		secret, err := readPassword(stdin)
+		return "secret", secret, err
+	}
+
+	vaults, err := openVaults(conf, cmd.vault)
+	if err != nil {
+		return "", "", err
+	}
+	for _, vault := range vaults {
+		item, err := vault.GetItem(&paw.Metadata{Name: cmd.ref.Item})
+		if err != nil {
+			continue
+		}
+		v, err := cmd.ref.Value(item)
+		if err != nil {
+			return "", "", err
+		}
+		if v == "" {
+			return "", "", fmt.Errorf("the value of %q is empty", cmd.ref.Item)
+		}
+		return cmd.ref.Item, v, nil
+	}
+	return "", "", fmt.Errorf("item %q not found", cmd.ref.Item)
+}
+
+// combine reads the shares and prints the secret
+func (cmd *ShamirCmd) combine() error {
+	var secrets []string
+	if len(cmd.files) == 0 {
+		fmt.Fprintln(os.Stderr, "Enter the shares, one per line, followed by an empty line:")
+		v, err := readSecretLines(stdin)
+		if err != nil {
+			return err
+		}
+		secrets = v
+	}
+	for _, name := range cmd.files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		v, err := kit.ReadSecrets(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		if len(v) == 0 {
+			return fmt.Errorf("no share found into %s", name)
+		}
+		secrets = append(secrets, v...)
+	}
+
+	secret, err := combineShares(secrets)
+	if err != nil {
+		return err
+	}
+	fmt.Println(secret)
+	return nil
+}
+
+// combineShares combines the encoded shares into the secret value
+func combineShares(secrets []string) (string, error) {
+	var shares []shamir.Share
+	for _, v := range secrets {
+		share, err := shamir.ParseShare(v)
+		if err != nil {
+			return "", err
+		}
+		shares = append(shares, share)
+	}
+	secret, err := shamir.Combine(shares)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(secret) {
+		return "", fmt.Errorf("the shares do not hold a text value, use the recover command for the shares of a vault key")
+	}
+	return string(secret), nil
+}
+
+// writeShare writes the share as text, along with the instructions to combine it
+func writeShare(w io.Writer, name string, share shamir.Share, encoded string, n int) error {
+	lines := []string{
+		"PAW SECRET SHARE",
+		"",
+		fmt.Sprintf("Secret: %s", name),
+		fmt.Sprintf("Share %d of %d, %d shares are required to combine the secret.", share.Index, n, share.Threshold),
+		"",
+		encoded,
+		"",
+		"Combine the secret using: paw shamir combine",
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
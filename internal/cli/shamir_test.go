@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/kit"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/shamir"
+)
+
+func TestShamirCmdParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *ShamirCmd
+		wantErr bool
+	}{
+		{
+			name: "split stdin",
+			args: []string{"-shares", "3", "-threshold", "2", "split"},
+			want: &ShamirCmd{action: "split", shares: 3, threshold: 2},
+		},
+		{
+			name: "split item",
+			args: []string{"-vault", "team", "-shares", "5", "-threshold", "3", "-o", "out", "split", "domain-admin#password"},
+			want: &ShamirCmd{action: "split", vault: "team", shares: 5, threshold: 3, outputDir: "out", ref: &reference{Item: "domain-admin", Selector: "password"}},
+		},
+		{
+			name: "combine",
+			args: []string{"combine", "a.txt", "b.txt"},
+			want: &ShamirCmd{action: "combine", files: []string{"a.txt", "b.txt"}},
+		},
+		{name: "no action", args: []string{}, wantErr: true},
+		{name: "unknown action", args: []string{"join"}, wantErr: true},
+		{name: "no shares", args: []string{"split"}, wantErr: true},
+		{name: "threshold greater than shares", args: []string{"-shares", "2", "-threshold", "3", "split"}, wantErr: true},
+		{name: "invalid reference", args: []string{"-shares", "3", "-threshold", "2", "split", "item#unknown"}, wantErr: true},
+		{name: "too many arguments", args: []string{"-shares", "3", "-threshold", "2", "split", "a", "b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &ShamirCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cmd)
+		})
+	}
+}
+
+func TestCombineShares(t *testing.T) {
+	secret := "correct horse battery staple, the break-glass password"
+	shares, err := shamir.Split([]byte(secret), 5, 3)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	var files []string
+	for i, share := range shares {
+		v, err := share.Encode()
+		require.NoError(t, err)
+		name := filepath.Join(dir, fmt.Sprintf("share-%d.txt", i+1))
+		f, err := os.Create(name)
+		require.NoError(t, err)
+		require.NoError(t, writeShare(f, "domain-admin", share, v, len(shares)))
+		require.NoError(t, f.Close())
+		files = append(files, name)
+	}
+
+	var secrets []string
+	for _, name := range files[2:] {
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		v, err := kit.ReadSecrets(f)
+		f.Close()
+		require.NoError(t, err)
+		secrets = append(secrets, v...)
+	}
+	got, err := combineShares(secrets)
+	require.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	_, err = combineShares(secrets[1:])
+	assert.Error(t, err, "fewer shares than the threshold")
+
+	// the shares of a vault key are recovered by the recover command
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+	k, err := kit.New("team", key, 3, 2)
+	require.NoError(t, err)
+	_, err = combineShares(k.Secrets[:2])
+	assert.Error(t, err)
+}
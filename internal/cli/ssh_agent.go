@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/sshagent"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*SSHAgentCmd)(nil)
+
+// SSHAgentCmd runs an ssh-agent serving the SSH keys from the vaults
+type SSHAgentCmd struct {
+	vault     string
+	socket    string
+	noConfirm bool
+}
+
+// Name returns the one word command name
+func (cmd *SSHAgentCmd) Name() string {
+	return "ssh-agent"
+}
+
+// Description returns the command description
+func (cmd *SSHAgentCmd) Description() string {
+	return "Run an ssh-agent serving the SSH keys from the vaults"
+}
+
+// Usage displays the command usage
+func (cmd *SSHAgentCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw ssh-agent [-vault NAME] [-socket PATH] [-no-confirm]
+
+Runs an ssh-agent that serves the SSH key items from the vaults. The private
+keys never touch the disk and each signature must be confirmed using the
+program specified by SSH_ASKPASS or, if not set, the controlling terminal.
+
+Options:
+  -vault NAME    serve only the keys from the vault. Default to all vaults
+  -socket PATH   the agent socket path. Default to ssh-agent.sock into the paw root
+  -no-confirm    do not ask the confirmation before each signature
+
+Set SSH_AUTH_SOCK to the socket path to use the agent.`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *SSHAgentCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.StringVar(&cmd.socket, "socket", "", "")
+	fs.BoolVar(&cmd.noConfirm, "no-confirm", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *SSHAgentCmd) Run(conf *azure.Config) error {
+	path := cmd.socket
+	if path == "" {
+		s, err := paw.NewOSStorage()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(s.Root(), "ssh-agent.sock")
+	}
+
+	vaults, err := openVaults(conf, cmd.vault)
+	if err != nil {
+		return err
+	}
+
+	var confirm sshagent.ConfirmFunc = sshagent.Confirm
+	if cmd.noConfirm {
+		confirm = nil
+	}
+
+	fmt.Printf("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", path)
+	return sshagent.ListenAndServe(path, sshagent.New(vaults, confirm))
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*SSHUnlockCmd)(nil)
+
+// SSHUnlockCmd allows to unlock a local vault using SSH keys instead of the password
+type SSHUnlockCmd struct {
+	vault      string
+	publicKeys []string
+	agent      bool
+	unlock     unlockOptions
+}
+
+// Name returns the one word command name
+func (cmd *SSHUnlockCmd) Name() string {
+	return "ssh-unlock"
+}
+
+// Description returns the command description
+func (cmd *SSHUnlockCmd) Description() string {
+	return "Allow to unlock a local vault using SSH keys"
+}
+
+// Usage displays the command usage
+func (cmd *SSHUnlockCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw ssh-unlock -vault NAME [-agent] [-i FILE | -ssh-agent] PUBLIC_KEY_FILE...
+
+Wraps the key of the local vault to the SSH public keys, so that the vault can
+be unlocked using the matching private key, as alternative to the password.
+Only ssh-ed25519 and ssh-rsa keys are supported. Running the command again
+replaces the SSH keys allowed to unlock the vault.
+
+The ssh-agent cannot decrypt, so to unlock the vault using the keys held by the
+agent the -agent flag must be specified. A challenge is signed by the agent using
+the keys matching the public keys, and the signatures are used to wrap the key.
+
+The vault key is unlocked using the password read from the standard input,
+unless -i or -ssh-agent are specified.
+
+Options:
+  -vault NAME   the local vault name
+  -agent        allow to unlock the vault using the keys held by the ssh-agent
+  -i FILE       unlock the vault using the SSH private key FILE
+  -ssh-agent    unlock the vault using the keys held by the ssh-agent
+
+Example:
+  paw ssh-unlock -vault team -agent ~/.ssh/id_ed25519.pub
+  paw members -vault team -ssh-agent add age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *SSHUnlockCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	fs.BoolVar(&cmd.agent, "agent", false, "")
+	cmd.unlock.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cmd.vault == "" {
+		return fmt.Errorf("the vault is required")
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("at least a public key file is required")
+	}
+	for _, name := range fs.Args() {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		pk := strings.TrimSpace(string(b))
+		if _, err := paw.ParseRecipient(pk); err != nil || !strings.HasPrefix(pk, "ssh-") {
+			return fmt.Errorf("%s is not an ssh-ed25519 or ssh-rsa public key", name)
+		}
+		cmd.publicKeys = append(cmd.publicKeys, pk)
+	}
+	return nil
+}
+
+// Run runs the command
+func (cmd *SSHUnlockCmd) Run(conf *azure.Config) error {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return err
+	}
+	storage := s.(*paw.OSStorage)
+
+	key, err := cmd.unlock.loadKey(storage, cmd.vault)
+	if err != nil {
+		return err
+	}
+
+	var signers []ssh.Signer
+	if cmd.agent {
+		agentSigners, closeAgent, err := sshAgentSigners()
+		if err != nil {
+			return err
+		}
+		defer closeAgent()
+		signers = matchingSigners(agentSigners, cmd.publicKeys)
+		if len(signers) == 0 {
+			return fmt.Errorf("none of the public keys is held by the ssh-agent")
+		}
+	}
+
+	if err := storage.StoreVaultSSHKey(cmd.vault, key, cmd.publicKeys, signers); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "The vault can be unlocked using %d SSH keys\n", len(cmd.publicKeys))
+	return nil
+}
+
+// matchingSigners returns the signers matching the public keys in the authorized_keys format
+func matchingSigners(signers []ssh.Signer, publicKeys []string) []ssh.Signer {
+	var matching []ssh.Signer
+	for _, signer := range signers {
+		for _, pk := range publicKeys {
+			parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pk))
+			if err != nil {
+				continue
+			}
+			if bytes.Equal(parsed.Marshal(), signer.PublicKey().Marshal()) {
+				matching = append(matching, signer)
+				break
+			}
+		}
+	}
+	return matching
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHUnlockCmdParse(t *testing.T) {
+	dir := t.TempDir()
+	signer := generateSigner(t)
+	pubFile := filepath.Join(dir, "id_ed25519.pub")
+	require.NoError(t, os.WriteFile(pubFile, ssh.MarshalAuthorizedKey(signer.PublicKey()), 0600))
+	ageFile := filepath.Join(dir, "age.pub")
+	require.NoError(t, os.WriteFile(ageFile, []byte("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p\n"), 0600))
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "public key", args: []string{"-vault", "team", pubFile}},
+		{name: "agent", args: []string{"-vault", "team", "-agent", "-ssh-agent", pubFile}},
+		{name: "missing vault", args: []string{pubFile}, wantErr: true},
+		{name: "missing public key", args: []string{"-vault", "team"}, wantErr: true},
+		{name: "not an SSH key", args: []string{"-vault", "team", ageFile}, wantErr: true},
+		{name: "missing file", args: []string{"-vault", "team", filepath.Join(dir, "missing")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &SSHUnlockCmd{}
+			err := cmd.Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, cmd.publicKeys, 1)
+		})
+	}
+}
+
+func TestMatchingSigners(t *testing.T) {
+	s1 := generateSigner(t)
+	s2 := generateSigner(t)
+	publicKeys := []string{string(ssh.MarshalAuthorizedKey(s2.PublicKey()))}
+
+	matching := matchingSigners([]ssh.Signer{s1, s2}, publicKeys)
+	require.Len(t, matching, 1)
+	assert.Equal(t, s2.PublicKey().Marshal(), matching[0].PublicKey().Marshal())
+}
+
+func generateSigner(t *testing.T) ssh.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer
+}
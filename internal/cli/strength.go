@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/strength"
+)
+
+// Declare conformity to Cmd interface
+var _ Cmd = (*StrengthCmd)(nil)
+
+// StrengthCmd estimates the strength of a password
+type StrengthCmd struct {
+	name  string
+	vault string
+}
+
+// Name returns the one word command name
+func (cmd *StrengthCmd) Name() string {
+	return "strength"
+}
+
+// Description returns the command description
+func (cmd *StrengthCmd) Description() string {
+	return "Estimate the strength of a password"
+}
+
+// Usage displays the command usage
+func (cmd *StrengthCmd) Usage() {
+	fmt.Fprintln(os.Stderr, `Usage: paw strength [-vault NAME] [ITEM]
+
+Estimates the strength of the password of the Login or Password item.
+Without item the password is read from the standard input, so that it is
+not stored into the shell history.
+
+Options:
+  -vault NAME   the vault to use. Default to all the configured vaults
+
+Example:
+  paw strength < password.txt`)
+}
+
+// Parse parses the arguments into the command flags
+func (cmd *StrengthCmd) Parse(args []string) error {
+	fs := newFlagSet(cmd)
+	fs.StringVar(&cmd.vault, "vault", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one item")
+	}
+	cmd.name = fs.Arg(0)
+	return nil
+}
+
+// Run runs the command
+func (cmd *StrengthCmd) Run(conf *azure.Config) error {
+	if cmd.name == "" {
+		password, err := readPassword(os.Stdin)
+		if err != nil {
+			return err
+		}
+		printStrength(os.Stdout, strength.Estimate(password))
+		return nil
+	}
+
+	vaults, err := openVaults(conf, cmd.vault)
+	if err != nil {
+		return err
+	}
+	for _, vault := range vaults {
+		item, err := vault.GetItem(&paw.Metadata{Name: cmd.name})
+		if err != nil {
+			continue
+		}
+		password, inputs, err := itemPassword(item)
+		if err != nil {
+			return err
+		}
+		printStrength(os.Stdout, strength.Estimate(password, inputs...))
+		return nil
+	}
+	return fmt.Errorf("item %q not found", cmd.name)
+}
+
+// readPassword reads the password from the first line of r
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password is empty")
+	}
+	return password, nil
+}
+
+// itemPassword returns the password of the item along with the user inputs
+// to match as dictionary words
+func itemPassword(item paw.Item) (string, []string, error) {
+	name := item.GetMetadata().Name
+	switch i := item.(type) {
+	case *paw.Login:
+		return i.Password.Value, []string{name, i.Username}, nil
+	case *paw.Password:
+		return i.Value, []string{name}, nil
+	}
+	return "", nil, fmt.Errorf("%q is a %s item, it has no password", name, item.GetMetadata().Type)
+}
+
+// printStrength prints the strength estimation
+func printStrength(w io.Writer, r *strength.Result) {
+	fmt.Fprintf(w, "Score:      %d/4 (%s)\n", r.Score, r.Label())
+	fmt.Fprintf(w, "Entropy:    %.0f bits\n", r.Entropy)
+	fmt.Fprintf(w, "Crack time: %s\n", r.CrackTimeText())
+	if warning := r.Warning(); warning != "" {
+		fmt.Fprintf(w, "Warning:    %s\n", warning)
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/strength"
+)
+
+func TestReadPassword(t *testing.T) {
+	got, err := readPassword(strings.NewReader("s3cret pass\r\nignored\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret pass", got)
+
+	_, err = readPassword(strings.NewReader("\n"))
+	assert.Error(t, err)
+}
+
+func TestItemPassword(t *testing.T) {
+	login := paw.NewLogin()
+	login.Name = "example.com"
+	login.Username = "admin"
+	login.Password.Value = "admin2021"
+
+	password, inputs, err := itemPassword(login)
+	require.NoError(t, err)
+	assert.Equal(t, "admin2021", password)
+	assert.Equal(t, []string{"example.com", "admin"}, inputs)
+
+	_, _, err = itemPassword(paw.NewNote())
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	printStrength(&buf, strength.Estimate(password, inputs...))
+	assert.Contains(t, buf.String(), "Score:      0/4 (Very weak)")
+	assert.Contains(t, buf.String(), "Warning:    Avoid the item name and the username")
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// stdin is shared by the commands that read more than a value from the standard input
+var stdin = bufio.NewReader(os.Stdin)
+
+// unlockOptions holds the options to unlock the key of a local vault.
+// The key is unlocked using the password, unless an SSH identity or the ssh-agent is specified.
+type unlockOptions struct {
+	identity string
+	sshAgent bool
+}
+
+func (o *unlockOptions) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.identity, "i", "", "")
+	fs.BoolVar(&o.sshAgent, "ssh-agent", false, "")
+}
+
+// loadKey loads the key of the local vault
+func (o *unlockOptions) loadKey(storage *paw.OSStorage, name string) (*paw.Key, error) {
+	if o.identity != "" {
+		pemBytes, err := os.ReadFile(o.identity)
+		if err != nil {
+			return nil, err
+		}
+		identity, err := paw.ParseSSHIdentity(pemBytes, func() ([]byte, error) {
+			fmt.Fprintf(os.Stderr, "Enter the passphrase for %s:\n", o.identity)
+			passphrase, err := readPassword(stdin)
+			return []byte(passphrase), err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return storage.LoadVaultSSHKey(name, identity)
+	}
+
+	if o.sshAgent {
+		signers, closeAgent, err := sshAgentSigners()
+		if err != nil {
+			return nil, err
+		}
+		defer closeAgent()
+		var identities []age.Identity
+		for _, signer := range signers {
+			identity, err := paw.SSHAgentIdentity(signer)
+			if err != nil {
+				// unsupported key type
+				continue
+			}
+			identities = append(identities, identity)
+		}
+		if len(identities) == 0 {
+			return nil, fmt.Errorf("no ssh-ed25519 or ssh-rsa keys found into the ssh-agent")
+		}
+		return storage.LoadVaultSSHKey(name, identities...)
+	}
+
+	fmt.Fprintln(os.Stderr, "Enter the vault password:")
+	password, err := readPassword(stdin)
+	if err != nil {
+		return nil, err
+	}
+	return storage.LoadVaultKey(name, password)
+}
+
+// readNewPassword reads the new vault password asking to repeat it
+func readNewPassword(r io.Reader) (string, error) {
+	fmt.Fprintln(os.Stderr, "Enter the new vault password:")
+	password, err := readPassword(r)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintln(os.Stderr, "Repeat the new vault password:")
+	repeated, err := readPassword(r)
+	if err != nil {
+		return "", err
+	}
+	if password != repeated {
+		return "", fmt.Errorf("the passwords do not match")
+	}
+	return password, nil
+}
+
+// sshAgentSigners returns the signers of the keys held by the ssh-agent listening on SSH_AUTH_SOCK.
+// The returned func must be called to close the agent connection once the signers are not used anymore.
+func sshAgentSigners() ([]ssh.Signer, func() error, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, nil, fmt.Errorf("SSH_AUTH_SOCK is not set, is the ssh-agent running?")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to the ssh-agent: %w", err)
+	}
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return signers, conn.Close, nil
+}
@@ -0,0 +1,197 @@
+// Package exporter exports the vault items to the formats supported by other
+// password managers and to an age encrypted archive.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Format is the format of the export
+type Format string
+
+const (
+	// Bitwarden is the Bitwarden unencrypted JSON format
+	Bitwarden Format = "bitwarden"
+	// CSV is the CSV format with the name, url, username, password, note, totp and folder columns
+	CSV Format = "csv"
+	// KeePass is the KeePass 2.x XML format
+	KeePass Format = "keepass"
+	// Age is the paw JSON archive encrypted with age
+	Age Format = "age"
+)
+
+// Formats returns the supported formats
+func Formats() []Format {
+	return []Format{Bitwarden, CSV, KeePass, Age}
+}
+
+// ParseFormat returns the format from its name
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+// Plaintext reports whether the secrets are written unencrypted
+func (f Format) Plaintext() bool {
+	return f != Age
+}
+
+// Ext returns the file extension of the format
+func (f Format) Ext() string {
+	switch f {
+	case Bitwarden:
+		return ".json"
+	case CSV:
+		return ".csv"
+	case KeePass:
+		return ".xml"
+	}
+	return ".json.age"
+}
+
+// PlaintextWarning is the warning displayed before writing a plaintext export
+const PlaintextWarning = "The export is NOT encrypted: anyone with access to the file can read all the secrets. " +
+	"Store it on an encrypted disk and delete it as soon as possible, or use the age format."
+
+// Load loads the items along with their values. Items are loaded concurrently and
+// returned sorted by name. Empty names means all the vault items.
+// The optional progress func is called after each item is loaded, possibly concurrently.
+func Load(vault azure.Vault, names []string, progress func(done, total int)) ([]paw.Item, error) {
+	if len(names) == 0 {
+		names = vault.ListItems()
+	}
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	items := make([]paw.Item, len(names))
+	errs := make([]error, len(names))
+	jobs := make(chan int)
+
+	var mu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	workers := runtime.NumCPU()
+	if workers > len(names) {
+		workers = len(names)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				item, err := vault.GetItem(&paw.Metadata{Name: names[i]})
+				if err != nil {
+					errs[i] = fmt.Errorf("could not load %q: %w", names[i], err)
+				}
+				items[i] = item
+				if progress != nil {
+					mu.Lock()
+					done++
+					progress(done, len(names))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// Options holds the export options
+type Options struct {
+	// Vault is the vault name, used as folder for the plaintext formats
+	Vault string
+	// Passphrase is the passphrase used to encrypt the age archive
+	Passphrase string
+	// Recipients are the age recipients used to encrypt the age archive,
+	// i.e. age1... public keys. They replace the passphrase
+	Recipients []string
+}
+
+// Export writes the items to w
+func Export(w io.Writer, format Format, items []paw.Item, opts Options) error {
+	switch format {
+	case Bitwarden:
+		return writeBitwarden(w, records(items))
+	case CSV:
+		return writeCSV(w, opts.Vault, records(items))
+	case KeePass:
+		return writeKeePass(w, opts.Vault, records(items))
+	case Age:
+		return writeAge(w, items, opts)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// record is the format agnostic representation of an item for the plaintext formats.
+// Notes and SSH keys are exported as notes, the other items as logins.
+type record struct {
+	Name     string
+	Note     bool
+	Username string
+	Password string
+	URL      string
+	Notes    string
+	TOTP     string
+	Fields   paw.Fields
+}
+
+func records(items []paw.Item) []*record {
+	records := make([]*record, 0, len(items))
+	for _, item := range items {
+		r := &record{Name: item.GetMetadata().Name}
+		switch v := item.(type) {
+		case *paw.Login:
+			r.Username, r.URL, r.Fields = v.Username, v.URL, v.Fields
+			if v.Password != nil {
+				r.Password = v.Password.Value
+			}
+			if v.Note != nil {
+				r.Notes = v.Note.Value
+			}
+			if v.TOTP != nil && v.TOTP.Secret != "" {
+				r.TOTP = v.TOTP.URI()
+			}
+		case *paw.Password:
+			r.Password = v.Value
+			if v.Note != nil {
+				r.Notes = v.Note.Value
+			}
+		case *paw.Note:
+			r.Note, r.Notes = true, v.Value
+		case *paw.TOTP:
+			r.TOTP = v.URI()
+			if v.Note != nil {
+				r.Notes = v.Note.Value
+			}
+		case *paw.SSHKey:
+			r.Note = true
+			r.Notes = v.PrivateKey
+			r.Fields = paw.Fields{{Label: "public key", Value: v.PublicKey, Kind: paw.TextField}}
+		}
+		records = append(records, r)
+	}
+	return records
+}
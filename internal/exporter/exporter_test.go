@@ -0,0 +1,232 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/importer"
+	"lucor.dev/paw/internal/paw"
+)
+
+// memVault is an in memory azure.Vault used for testing
+type memVault struct {
+	mu      sync.Mutex
+	secrets map[string]paw.Item
+}
+
+func (v *memVault) AddItem(secret paw.Item) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secrets[secret.GetMetadata().Name] = secret
+	return nil
+}
+
+func (v *memVault) DeleteItem(secret paw.Item) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.secrets, secret.GetMetadata().Name)
+	return nil
+}
+
+func (v *memVault) GetItem(secret paw.Item) (paw.Item, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.secrets[secret.GetMetadata().Name]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return s, nil
+}
+
+func (v *memVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata {
+	return nil
+}
+
+func (v *memVault) Key() (*paw.Key, error) {
+	return nil, azure.ErrNoKey
+}
+
+func (v *memVault) CreateKey() error {
+	return azure.ErrNoKey
+}
+
+func (v *memVault) Secret(password *paw.Password, item paw.Item) (string, error) {
+	return azure.GenerateSecret(nil, password, item)
+}
+
+func (v *memVault) ListItems() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var names []string
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v *memVault) Range(f func(name string, item paw.Item) bool) {
+	for _, name := range v.ListItems() {
+		item, _ := v.GetItem(&paw.Metadata{Name: name})
+		if !f(name, item) {
+			break
+		}
+	}
+}
+
+func (v *memVault) Size() int {
+	return len(v.ListItems())
+}
+
+func (v *memVault) SizeByType(_ paw.ItemType) int {
+	return v.Size()
+}
+
+func testItems(t *testing.T) []paw.Item {
+	login := paw.NewLogin()
+	login.Name = "github"
+	login.Username = "alice"
+	login.Password.Value = "s3cret"
+	login.URL = "https://github.com"
+	login.Note.Value = "2FA enabled"
+	login.Fields = paw.Fields{{Label: "recovery", Value: "abc", Kind: paw.HiddenField}}
+	login.TOTP = &paw.TOTP{}
+	require.NoError(t, login.TOTP.SetURI("JBSWY3DPEHPK3PXP"))
+
+	note := paw.NewNote()
+	note.Name = "readme"
+	note.Value = "just a note"
+
+	password := paw.NewCustomPassword()
+	password.Name = "router"
+	password.Value = "r0uter"
+
+	return []paw.Item{login, note, password}
+}
+
+func TestLoad(t *testing.T) {
+	vault := &memVault{secrets: map[string]paw.Item{}}
+	for i := 0; i < 50; i++ {
+		note := paw.NewNote()
+		note.Name = fmt.Sprintf("note-%02d", i)
+		note.Value = "value"
+		require.NoError(t, vault.AddItem(note))
+	}
+
+	var calls int
+	items, err := Load(vault, nil, func(done, total int) {
+		calls++
+		assert.Equal(t, 50, total)
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 50)
+	assert.Equal(t, 50, calls)
+	assert.Equal(t, "note-00", items[0].GetMetadata().Name)
+	assert.Equal(t, "note-49", items[49].GetMetadata().Name)
+
+	items, err = Load(vault, []string{"note-02", "note-01"}, nil)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "note-01", items[0].GetMetadata().Name)
+
+	_, err = Load(vault, []string{"missing"}, nil)
+	assert.Error(t, err)
+}
+
+// TestExportRoundTrip checks the plaintext exports can be imported back
+func TestExportRoundTrip(t *testing.T) {
+	tests := []struct {
+		export  Format
+		import_ importer.Format
+	}{
+		{export: Bitwarden, import_: importer.Bitwarden},
+		{export: CSV, import_: importer.CSV},
+		{export: KeePass, import_: importer.KeePass},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.export), func(t *testing.T) {
+			assert.True(t, tt.export.Plaintext())
+
+			var buf bytes.Buffer
+			require.NoError(t, Export(&buf, tt.export, testItems(t), Options{Vault: "myvault"}))
+
+			res, err := importer.Parse(tt.import_, &buf, importer.Options{})
+			require.NoError(t, err)
+			require.Len(t, res.Entries, 3)
+
+			login := res.Entries[0].Item.(*paw.Login)
+			assert.Equal(t, "github", login.Name)
+			assert.Equal(t, "alice", login.Username)
+			assert.Equal(t, "s3cret", login.Password.Value)
+			assert.Equal(t, "https://github.com", login.URL)
+			assert.Equal(t, "JBSWY3DPEHPK3PXP", login.TOTP.Secret)
+
+			note := res.Entries[1].Item
+			assert.Equal(t, "readme", note.GetMetadata().Name)
+
+			// passwords are exported as logins without username
+			router := res.Entries[2].Item.(*paw.Login)
+			assert.Equal(t, "r0uter", router.Password.Value)
+		})
+	}
+}
+
+func TestExportAge(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		identity age.Identity
+	}{
+		{
+			name:     "passphrase",
+			opts:     Options{Vault: "myvault", Passphrase: "correct horse battery staple"},
+			identity: mustScryptIdentity(t, "correct horse battery staple"),
+		},
+		{
+			name:     "recipient",
+			opts:     Options{Vault: "myvault", Recipients: []string{identity.Recipient().String()}},
+			identity: identity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.False(t, Age.Plaintext())
+			var buf bytes.Buffer
+			require.NoError(t, Export(&buf, Age, testItems(t), tt.opts))
+			assert.NotContains(t, buf.String(), "s3cret")
+
+			r, err := age.Decrypt(&buf, tt.identity)
+			require.NoError(t, err)
+			var archive Archive
+			require.NoError(t, json.NewDecoder(r).Decode(&archive))
+			assert.Equal(t, "myvault", archive.Vault)
+			require.Len(t, archive.Items, 3)
+
+			item, err := paw.UnmarshalItem(archive.Items[0])
+			require.NoError(t, err)
+			assert.Equal(t, "s3cret", item.(*paw.Login).Password.Value)
+		})
+	}
+
+	var buf bytes.Buffer
+	assert.Error(t, Export(&buf, Age, testItems(t), Options{}))
+	assert.Error(t, Export(&buf, Age, testItems(t), Options{Recipients: []string{"invalid"}}))
+}
+
+func mustScryptIdentity(t *testing.T, passphrase string) age.Identity {
+	identity, err := age.NewScryptIdentity(passphrase)
+	require.NoError(t, err)
+	return identity
+}
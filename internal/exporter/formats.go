@@ -0,0 +1,239 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"filippo.io/age"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// Bitwarden item and field types
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+
+	bitwardenTextField   = 0
+	bitwardenHiddenField = 1
+)
+
+type bitwardenField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+type bitwardenURI struct {
+	Match *int   `json:"match"`
+	URI   string `json:"uri"`
+}
+
+type bitwardenLoginData struct {
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	TOTP     string         `json:"totp"`
+	URIs     []bitwardenURI `json:"uris"`
+}
+
+type bitwardenItem struct {
+	Type       int                 `json:"type"`
+	Name       string              `json:"name"`
+	Notes      string              `json:"notes"`
+	Favorite   bool                `json:"favorite"`
+	Fields     []bitwardenField    `json:"fields,omitempty"`
+	Login      *bitwardenLoginData `json:"login,omitempty"`
+	SecureNote *struct {
+		Type int `json:"type"`
+	} `json:"secureNote,omitempty"`
+}
+
+// writeBitwarden writes the records in the Bitwarden unencrypted JSON format
+func writeBitwarden(w io.Writer, records []*record) error {
+	export := struct {
+		Encrypted bool            `json:"encrypted"`
+		Folders   []struct{}      `json:"folders"`
+		Items     []bitwardenItem `json:"items"`
+	}{Folders: []struct{}{}, Items: []bitwardenItem{}}
+
+	for _, r := range records {
+		item := bitwardenItem{Name: r.Name, Notes: r.Notes}
+		for _, f := range r.Fields {
+			typ := bitwardenTextField
+			if f.Kind == paw.HiddenField {
+				typ = bitwardenHiddenField
+			}
+			item.Fields = append(item.Fields, bitwardenField{Name: f.Label, Value: f.Value, Type: typ})
+		}
+		if r.Note {
+			item.Type = bitwardenSecureNote
+			item.SecureNote = &struct {
+				Type int `json:"type"`
+			}{}
+		} else {
+			item.Type = bitwardenLogin
+			item.Login = &bitwardenLoginData{Username: r.Username, Password: r.Password, TOTP: r.TOTP, URIs: []bitwardenURI{}}
+			if r.URL != "" {
+				item.Login.URIs = append(item.Login.URIs, bitwardenURI{URI: r.URL})
+			}
+		}
+		export.Items = append(export.Items, item)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
+
+// writeCSV writes the records as CSV, the columns are the ones of the generic CSV importer.
+// Custom fields are not supported by the format and are appended to the note.
+func writeCSV(w io.Writer, folder string, records []*record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "url", "username", "password", "note", "totp", "folder"}); err != nil {
+		return err
+	}
+	for _, r := range records {
+		note := r.Notes
+		for _, f := range r.Fields {
+			if note != "" {
+				note += "\n"
+			}
+			note += fmt.Sprintf("%s: %s", f.Label, f.Value)
+		}
+		if err := cw.Write([]string{r.Name, r.URL, r.Username, r.Password, note, r.TOTP, folder}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type keePassValue struct {
+	Value     string `xml:",chardata"`
+	Protected string `xml:"ProtectInMemory,attr,omitempty"`
+}
+
+type keePassString struct {
+	Key   string       `xml:"Key"`
+	Value keePassValue `xml:"Value"`
+}
+
+type keePassEntry struct {
+	Strings []keePassString `xml:"String"`
+}
+
+type keePassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    struct {
+		Generator    string `xml:"Generator"`
+		DatabaseName string `xml:"DatabaseName"`
+	} `xml:"Meta"`
+	Root struct {
+		Group struct {
+			Name    string         `xml:"Name"`
+			Entries []keePassEntry `xml:"Entry"`
+		} `xml:"Group"`
+	} `xml:"Root"`
+}
+
+// writeKeePass writes the records in the KeePass 2.x XML format that can be imported
+// by KeePass and KeePassXC. The TOTP is stored as otp attribute, as KeePassXC does.
+func writeKeePass(w io.Writer, folder string, records []*record) error {
+	var file keePassFile
+	file.Meta.Generator = paw.ID
+	file.Meta.DatabaseName = folder
+	file.Root.Group.Name = folder
+
+	for _, r := range records {
+		var e keePassEntry
+		add := func(key, value string, protected bool) {
+			if value == "" && key != "Title" {
+				return
+			}
+			v := keePassValue{Value: value}
+			if protected {
+				v.Protected = "True"
+			}
+			e.Strings = append(e.Strings, keePassString{Key: key, Value: v})
+		}
+		add("Title", r.Name, false)
+		add("UserName", r.Username, false)
+		add("Password", r.Password, true)
+		add("URL", r.URL, false)
+		add("Notes", r.Notes, false)
+		add("otp", r.TOTP, true)
+		for _, f := range r.Fields {
+			add(f.Label, f.Value, f.Kind == paw.HiddenField)
+		}
+		file.Root.Group.Entries = append(file.Root.Group.Entries, e)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(file); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Archive is the content of the age encrypted archive
+type Archive struct {
+	Version string            `json:"version"`
+	Vault   string            `json:"vault"`
+	Created time.Time         `json:"created"`
+	Items   []json.RawMessage `json:"items"`
+}
+
+// ArchiveVersion is the version of the age archive format
+const ArchiveVersion = paw.Version
+
+// writeAge writes the items as JSON archive encrypted with age, so that can be
+// decrypted with the age tool, i.e. age -d -o vault.json vault.json.age
+func writeAge(w io.Writer, items []paw.Item, opts Options) error {
+	var recipients []age.Recipient
+	for _, r := range opts.Recipients {
+		recipient, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", r, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		if opts.Passphrase == "" {
+			return fmt.Errorf("a passphrase or a recipient is required to encrypt the archive")
+		}
+		recipient, err := age.NewScryptRecipient(opts.Passphrase)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	archive := Archive{Version: ArchiveVersion, Vault: opts.Vault, Created: time.Now(), Items: []json.RawMessage{}}
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		archive.Items = append(archive.Items, data)
+	}
+
+	e, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(e)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(archive); err != nil {
+		return err
+	}
+	return e.Close()
+}
@@ -0,0 +1,308 @@
+// Package health implements the vault health report that flags weak and
+// reused passwords, stale and not expiring secrets and insecure URLs.
+package health
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/breach"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/strength"
+)
+
+// Issue is the kind of problem found by the report
+type Issue string
+
+const (
+	// WeakPassword flags the passwords with a score below the min one
+	WeakPassword Issue = "weak"
+	// ReusedPassword flags the passwords used by more than one secret
+	ReusedPassword Issue = "reused"
+	// StaleSecret flags the secrets not modified since the max age
+	StaleSecret Issue = "stale"
+	// MissingExpiry flags the secrets without an expiration date
+	MissingExpiry Issue = "missing-expiry"
+	// ExpiredSecret flags the secrets already expired
+	ExpiredSecret Issue = "expired"
+	// InsecureURL flags the logins with a plain HTTP URL
+	InsecureURL Issue = "insecure-url"
+	// BreachedPassword flags the passwords found in a data breach
+	BreachedPassword Issue = "breached"
+)
+
+// Issues returns the issues in the order used by the report
+func Issues() []Issue {
+	return []Issue{BreachedPassword, WeakPassword, ReusedPassword, ExpiredSecret, StaleSecret, MissingExpiry, InsecureURL}
+}
+
+// Title returns a human readable title for the issue
+func (i Issue) Title() string {
+	switch i {
+	case BreachedPassword:
+		return "Breached passwords"
+	case WeakPassword:
+		return "Weak passwords"
+	case ReusedPassword:
+		return "Reused passwords"
+	case StaleSecret:
+		return "Not modified recently"
+	case MissingExpiry:
+		return "Missing expiry"
+	case ExpiredSecret:
+		return "Expired"
+	case InsecureURL:
+		return "Insecure URLs"
+	}
+	return string(i)
+}
+
+const (
+	// DefaultMaxAge is the default age after which a secret is flagged as stale
+	DefaultMaxAge = 90 * 24 * time.Hour
+	// DefaultMinScore is the default min strength score, see strength.Result
+	DefaultMinScore = 3
+)
+
+// Options holds the report options
+type Options struct {
+	// MaxAge is the age after which a secret is flagged as stale
+	MaxAge time.Duration
+	// MinScore is the min password strength score
+	MinScore int
+	// Now is the time the report refers to, zero means the current time
+	Now time.Time
+	// Breach is the checker for the breached passwords, nil to skip the check
+	Breach *breach.Checker
+}
+
+// DefaultOptions returns the default report options
+func DefaultOptions() Options {
+	return Options{MaxAge: DefaultMaxAge, MinScore: DefaultMinScore}
+}
+
+// Finding is an issue found for an item.
+// Findings never hold the secret values.
+type Finding struct {
+	Issue  Issue        `json:"issue"`
+	Item   string       `json:"item"`
+	Type   paw.ItemType `json:"-"`
+	Detail string       `json:"detail,omitempty"`
+}
+
+// MarshalJSON marshals the finding with the item type name
+func (f *Finding) MarshalJSON() ([]byte, error) {
+	type finding Finding
+	return json.Marshal(&struct {
+		*finding
+		Type string `json:"type"`
+	}{finding: (*finding)(f), Type: f.Type.String()})
+}
+
+// Report is the health report of a vault
+type Report struct {
+	Vault    string     `json:"vault"`
+	Created  time.Time  `json:"created"`
+	Items    int        `json:"items"`
+	Findings []*Finding `json:"findings"`
+	Warnings []string   `json:"warnings,omitempty"`
+}
+
+// Scan loads the items of the vault, along with their values, and checks them.
+// Values already loaded are served by the vault cache.
+// The optional progress func is called after each item is loaded.
+func Scan(name string, vault azure.Vault, opts Options, progress func(done, total int)) (*Report, error) {
+	names := vault.ListItems()
+	items := make([]paw.Item, 0, len(names))
+	var err error
+	vault.Range(func(_ string, item paw.Item) bool {
+		var loaded paw.Item
+		loaded, err = vault.GetItem(item)
+		if err != nil {
+			err = fmt.Errorf("could not load %q: %w", item.GetMetadata().Name, err)
+			return false
+		}
+		items = append(items, loaded)
+		if progress != nil {
+			progress(len(items), len(names))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := Check(items, opts)
+	r.Vault = name
+	return r, nil
+}
+
+// Check checks the items and returns the report.
+// A breach check error, i.e. a missing range file, stops the breach check
+// and is reported as warning so that the other checks are not affected.
+func Check(items []paw.Item, opts Options) *Report {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	r := &Report{Created: now, Items: len(items), Findings: []*Finding{}}
+	add := func(issue Issue, m *paw.Metadata, detail string) {
+		r.Findings = append(r.Findings, &Finding{Issue: issue, Item: m.Name, Type: m.Type, Detail: detail})
+	}
+
+	checker := opts.Breach
+	// passwords maps the password values to the names of the items using them
+	passwords := map[string][]string{}
+	for _, item := range items {
+		m := item.GetMetadata()
+
+		password, inputs, url := itemValues(item)
+		if password != "" {
+			passwords[password] = append(passwords[password], m.Name)
+			if checker != nil {
+				count, err := checker.Count(password)
+				switch {
+				case err != nil:
+					r.Warnings = append(r.Warnings, "breach check skipped: "+err.Error())
+					checker = nil
+				case count > 0:
+					add(BreachedPassword, m, fmt.Sprintf("found %d times in data breaches", count))
+				}
+			}
+			if s := strength.Estimate(password, inputs...); s.Score < opts.MinScore {
+				add(WeakPassword, m, fmt.Sprintf("%s, cracked in %s", s.Label(), s.CrackTimeText()))
+			}
+		}
+
+		switch {
+		case m.Expires.IsZero():
+			add(MissingExpiry, m, "")
+		case m.Expires.Before(now):
+			add(ExpiredSecret, m, "expired on "+m.Expires.Format("2006-01-02"))
+		}
+
+		if !m.Modified.IsZero() && opts.MaxAge > 0 && now.Sub(m.Modified) > opts.MaxAge {
+			days := int(now.Sub(m.Modified).Hours() / 24)
+			add(StaleSecret, m, fmt.Sprintf("not modified for %d days", days))
+		}
+
+		if strings.HasPrefix(strings.ToLower(url), "http://") {
+			add(InsecureURL, m, url)
+		}
+	}
+
+	for _, item := range items {
+		m := item.GetMetadata()
+		password, _, _ := itemValues(item)
+		others := without(passwords[password], m.Name)
+		if password == "" || len(others) == 0 {
+			continue
+		}
+		add(ReusedPassword, m, "also used by "+strings.Join(others, ", "))
+	}
+
+	order := map[Issue]int{}
+	for i, issue := range Issues() {
+		order[issue] = i
+	}
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Issue != b.Issue {
+			return order[a.Issue] < order[b.Issue]
+		}
+		return a.Item < b.Item
+	})
+	return r
+}
+
+// itemValues returns the password of the item, the user inputs to match
+// as dictionary words and the URL
+func itemValues(item paw.Item) (password string, inputs []string, url string) {
+	name := item.GetMetadata().Name
+	switch i := item.(type) {
+	case *paw.Login:
+		return i.Password.Value, []string{name, i.Username}, i.URL
+	case *paw.Password:
+		return i.Value, []string{name}, ""
+	}
+	return "", nil, ""
+}
+
+// without returns the names except the specified one
+func without(names []string, name string) []string {
+	var others []string
+	for _, n := range names {
+		if n != name {
+			others = append(others, n)
+		}
+	}
+	sort.Strings(others)
+	return others
+}
+
+// Group returns the findings of the issue
+func (r *Report) Group(issue Issue) []*Finding {
+	var findings []*Finding
+	for _, f := range r.Findings {
+		if f.Issue == issue {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// WriteJSON writes the reports JSON encoded as an array
+func WriteJSON(w io.Writer, reports ...*Report) error {
+	if reports == nil {
+		reports = []*Report{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// WriteCSV writes the findings of the reports as CSV with the vault, issue,
+// item, type and detail columns
+func WriteCSV(w io.Writer, reports ...*Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"vault", "issue", "item", "type", "detail"}); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		for _, f := range r.Findings {
+			if err := cw.Write([]string{r.Vault, string(f.Issue), f.Item, f.Type.String(), f.Detail}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteText writes the findings grouped by issue in a human readable format
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Vault %s: %d items, %d findings\n", r.Vault, r.Items, len(r.Findings))
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+	for _, issue := range Issues() {
+		findings := r.Group(issue)
+		if len(findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d)\n", issue.Title(), len(findings))
+		for _, f := range findings {
+			if f.Detail == "" {
+				fmt.Fprintf(w, "  %s\n", f.Item)
+				continue
+			}
+			fmt.Fprintf(w, "  %s: %s\n", f.Item, f.Detail)
+		}
+	}
+	return nil
+}
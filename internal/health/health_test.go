@@ -0,0 +1,145 @@
+package health
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/breach"
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	login := paw.NewLogin()
+	login.Name = "example.com"
+	login.Username = "admin"
+	login.URL = "http://example.com/login"
+	login.Password.Value = "admin2021"
+	login.Modified = now.AddDate(0, 0, -200)
+	login.Expires = now.AddDate(0, 1, 0)
+
+	password := paw.NewPassword()
+	password.Name = "wifi"
+	password.Value = "admin2021"
+	password.Modified = now.AddDate(0, 0, -10)
+	password.Expires = now.AddDate(0, 0, -1)
+
+	strong := paw.NewLogin()
+	strong.Name = "secure.example.com"
+	strong.URL = "https://secure.example.com"
+	strong.Password.Value = "v9#Lq2!zR7@wXk4$"
+	strong.Modified = now
+	strong.Expires = now.AddDate(1, 0, 0)
+
+	note := paw.NewNote()
+	note.Name = "note"
+	note.Modified = now
+
+	r := Check([]paw.Item{login, password, strong, note}, Options{MaxAge: DefaultMaxAge, MinScore: DefaultMinScore, Now: now})
+	assert.Equal(t, 4, r.Items)
+
+	got := map[Issue][]string{}
+	for _, f := range r.Findings {
+		got[f.Issue] = append(got[f.Issue], f.Item)
+		assert.NotContains(t, f.Detail, "admin2021")
+	}
+	assert.Equal(t, map[Issue][]string{
+		WeakPassword:   {"example.com", "wifi"},
+		ReusedPassword: {"example.com", "wifi"},
+		ExpiredSecret:  {"wifi"},
+		StaleSecret:    {"example.com"},
+		MissingExpiry:  {"note"},
+		InsecureURL:    {"example.com"},
+	}, got)
+
+	reused := r.Group(ReusedPassword)
+	require.Len(t, reused, 2)
+	assert.Equal(t, "also used by wifi", reused[0].Detail)
+	assert.Equal(t, "not modified for 200 days", r.Group(StaleSecret)[0].Detail)
+}
+
+func TestCheckNoIssues(t *testing.T) {
+	now := time.Now()
+	strong := paw.NewLogin()
+	strong.Name = "secure.example.com"
+	strong.Password.Value = "v9#Lq2!zR7@wXk4$"
+	strong.Modified = now
+	strong.Expires = now.AddDate(1, 0, 0)
+
+	r := Check([]paw.Item{strong}, DefaultOptions())
+	assert.Empty(t, r.Findings)
+}
+
+func TestReportExport(t *testing.T) {
+	r := &Report{
+		Vault: "test",
+		Items: 1,
+		Findings: []*Finding{
+			{Issue: InsecureURL, Item: "example.com", Type: paw.LoginItemType, Detail: "http://example.com"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, r))
+	var v []struct {
+		Vault    string
+		Findings []map[string]string
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &v))
+	require.Len(t, v, 1)
+	assert.Equal(t, "test", v[0].Vault)
+	assert.Equal(t, []map[string]string{
+		{"issue": "insecure-url", "item": "example.com", "type": "login", "detail": "http://example.com"},
+	}, v[0].Findings)
+
+	buf.Reset()
+	require.NoError(t, WriteCSV(&buf, r))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"vault", "issue", "item", "type", "detail"},
+		{"test", "insecure-url", "example.com", "login", "http://example.com"},
+	}, records)
+
+	buf.Reset()
+	require.NoError(t, r.WriteText(&buf))
+	assert.Equal(t, "Vault test: 1 items, 1 findings\n\nInsecure URLs (1)\n  example.com: http://example.com\n", buf.String())
+}
+
+func TestCheckBreached(t *testing.T) {
+	dir := t.TempDir()
+	prefix, suffix := breach.Hash("admin2021")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(suffix+":42\n"), 0600))
+
+	login := paw.NewLogin()
+	login.Name = "example.com"
+	login.Password.Value = "admin2021"
+
+	other := paw.NewPassword()
+	other.Name = "other"
+	other.Value = "v9#Lq2!zR7@wXk4$"
+
+	opts := DefaultOptions()
+	opts.Breach = breach.NewChecker(&breach.DirSource{Dir: dir})
+
+	r := Check([]paw.Item{login}, opts)
+	breached := r.Group(BreachedPassword)
+	require.Len(t, breached, 1)
+	assert.Equal(t, "found 42 times in data breaches", breached[0].Detail)
+	assert.Empty(t, r.Warnings)
+
+	// the range file of the other password is missing
+	r = Check([]paw.Item{other, login}, opts)
+	assert.Empty(t, r.Group(BreachedPassword))
+	require.Len(t, r.Warnings, 1)
+	assert.Contains(t, r.Warnings[0], "breach check skipped")
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// Bitwarden item and field types
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+
+	bitwardenHiddenField = 1
+)
+
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []struct {
+		Type     int    `json:"type"`
+		Name     string `json:"name"`
+		Notes    string `json:"notes"`
+		FolderID string `json:"folderId"`
+		Fields   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+			Type  int    `json:"type"`
+		} `json:"fields"`
+		Login *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			TOTP     string `json:"totp"`
+			URIs     []struct {
+				URI string `json:"uri"`
+			} `json:"uris"`
+		} `json:"login"`
+	} `json:"items"`
+}
+
+// parseBitwarden parses the Bitwarden unencrypted JSON export.
+// Logins and secure notes are imported, cards and identities are skipped.
+func parseBitwarden(r io.Reader) (*Result, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+	if export.Encrypted {
+		return nil, fmt.Errorf("encrypted exports are not supported")
+	}
+
+	folders := map[string]string{}
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	res := &Result{}
+	for _, i := range export.Items {
+		source := path.Join(folders[i.FolderID], i.Name)
+		switch i.Type {
+		case bitwardenLogin:
+			login := newLogin(i.Name, "", "", "", i.Notes)
+			if i.Login != nil {
+				login.Username = i.Login.Username
+				login.Password.Value = i.Login.Password
+				if len(i.Login.URIs) > 0 {
+					login.URL = i.Login.URIs[0].URI
+				}
+				if err := setTOTP(login, i.Login.TOTP); err != nil {
+					res.warnf("%s: %s", source, err)
+				}
+			}
+			for _, f := range i.Fields {
+				kind := paw.TextField
+				if f.Type == bitwardenHiddenField {
+					kind = paw.HiddenField
+				}
+				login.Fields = append(login.Fields, &paw.Field{Label: f.Name, Value: f.Value, Kind: kind})
+			}
+			res.Entries = append(res.Entries, &Entry{Item: login, Source: source})
+		case bitwardenSecureNote:
+			res.Entries = append(res.Entries, &Entry{Item: newNote(i.Name, i.Notes), Source: source})
+		default:
+			res.warnf("%s: unsupported item type %d, skipped", source, i.Type)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// csvFields are the item fields that can be mapped to the CSV columns
+var csvFields = []string{"name", "url", "username", "password", "note", "totp", "folder"}
+
+// csvMappings holds the default mapping of the item fields to the CSV columns for each format
+var csvMappings = map[Format]map[string]string{
+	LastPass: {"name": "name", "url": "url", "username": "username", "password": "password", "note": "extra", "totp": "totp", "folder": "grouping"},
+	Chrome:   {"name": "name", "url": "url", "username": "username", "password": "password", "note": "note"},
+	Firefox:  {"url": "url", "username": "username", "password": "password"},
+	CSV:      {"name": "name", "url": "url", "username": "username", "password": "password", "note": "note", "totp": "totp", "folder": "folder"},
+}
+
+// lastPassSecureNoteURL is the URL used by LastPass to mark the secure notes
+const lastPassSecureNoteURL = "http://sn"
+
+// ParseMapping parses the mapping of the item fields to the CSV columns
+// in the field=column,field=column format, i.e. name=title,note=comments
+func ParseMapping(s string) (map[string]string, error) {
+	mapping := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return mapping, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.IndexByte(kv, '=')
+		if i == -1 {
+			return nil, fmt.Errorf("invalid mapping %q: expected field=column", kv)
+		}
+		field := strings.ToLower(strings.TrimSpace(kv[:i]))
+		if !isCSVField(field) {
+			return nil, fmt.Errorf("invalid mapping %q: field must be one of %s", kv, strings.Join(csvFields, ", "))
+		}
+		mapping[field] = strings.TrimSpace(kv[i+1:])
+	}
+	return mapping, nil
+}
+
+func isCSVField(field string) bool {
+	for _, f := range csvFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCSV parses the CSV export with an header row. Each row is imported as a login,
+// the LastPass secure notes and the rows with only a note as notes. The mapping overrides the format defaults.
+func parseCSV(format Format, r io.Reader, mapping map[string]string) (*Result, error) {
+	columns := map[string]string{}
+	for field, column := range csvMappings[format] {
+		columns[field] = column
+	}
+	for field, column := range mapping {
+		columns[field] = column
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the header: %w", err)
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	// positions maps the item fields to the column positions
+	positions := map[string]int{}
+	for field, column := range columns {
+		if i, ok := index[strings.ToLower(column)]; ok {
+			positions[field] = i
+		}
+	}
+	if _, ok := positions["password"]; !ok {
+		return nil, fmt.Errorf("password column %q not found", columns["password"])
+	}
+
+	res := &Result{}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(field string) string {
+			i, ok := positions[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		name := get("name")
+		source := path.Join(get("folder"), name)
+		if source == "" {
+			source = fmt.Sprintf("line %d", line)
+		}
+
+		if format == LastPass && get("url") == lastPassSecureNoteURL {
+			res.Entries = append(res.Entries, &Entry{Item: newNote(name, get("note")), Source: source})
+			continue
+		}
+
+		if get("username") == "" && get("password") == "" && get("url") == "" {
+			if get("note") == "" {
+				res.warnf("%s: empty row, skipped", source)
+				continue
+			}
+			res.Entries = append(res.Entries, &Entry{Item: newNote(name, get("note")), Source: source})
+			continue
+		}
+		login := newLogin(name, get("username"), get("password"), get("url"), get("note"))
+		if err := setTOTP(login, get("totp")); err != nil {
+			res.warnf("%s: %s", source, err)
+		}
+		res.Entries = append(res.Entries, &Entry{Item: login, Source: source})
+	}
+	return res, nil
+}
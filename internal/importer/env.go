@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseEnv parses a dotenv file. Each variable is imported as a password named
+// after the variable, the underscores are replaced by dashes.
+// Comments, the export keyword, and single or double quoted values are supported.
+func parseEnv(r io.Reader) (*Result, error) {
+	res := &Result{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		s = strings.TrimSpace(strings.TrimPrefix(s, "export "))
+		i := strings.IndexByte(s, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		key := strings.TrimSpace(s[:i])
+		value, err := envValue(strings.TrimSpace(s[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if value == "" {
+			res.warnf("%s: empty value, skipped", key)
+			continue
+		}
+		res.Entries = append(res.Entries, &Entry{Item: newPassword(key, value, ""), Source: key})
+	}
+	return res, scanner.Err()
+}
+
+// envValue returns the unquoted value. Double quoted values support the \n, \" and \\ escapes,
+// the comments after an unquoted value are removed.
+func envValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch q := s[0]; q {
+	case '\'', '"':
+		end := -1
+		for i := 1; i < len(s); i++ {
+			if q == '"' && s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == q {
+				end = i
+				break
+			}
+		}
+		if end == -1 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		v := s[1:end]
+		if q == '"' {
+			v = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(v)
+		}
+		return v, nil
+	}
+	if i := strings.Index(s, " #"); i != -1 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
@@ -0,0 +1,343 @@
+// Package importer imports the items exported by other password managers.
+//
+// Importing is a two steps process: the export is parsed into entries, then a plan
+// maps each entry to a vault secret name and detects the duplicates. The plan
+// can be reviewed, i.e. as dry-run, before being applied to the vault.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Format is the format of the export
+type Format string
+
+const (
+	// Bitwarden is the Bitwarden unencrypted JSON export
+	Bitwarden Format = "bitwarden"
+	// KeePass is the KeePass 2.x XML export
+	KeePass Format = "keepass"
+	// OnePassword is the 1Password 1PUX export
+	OnePassword Format = "1pux"
+	// LastPass is the LastPass CSV export
+	LastPass Format = "lastpass"
+	// Chrome is the Chrome passwords CSV export
+	Chrome Format = "chrome"
+	// Firefox is the Firefox logins CSV export
+	Firefox Format = "firefox"
+	// CSV is a generic CSV file, see Options.Mapping
+	CSV Format = "csv"
+	// Env is a dotenv file, each variable is imported as a password
+	Env Format = "env"
+)
+
+// Formats returns the supported formats
+func Formats() []Format {
+	return []Format{Bitwarden, KeePass, OnePassword, LastPass, Chrome, Firefox, CSV, Env}
+}
+
+// ParseFormat returns the format from its name
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+// maxNameLength is the max length of a Key Vault secret name
+const maxNameLength = 127
+
+// Options holds the import options
+type Options struct {
+	// Prefix is prepended to the secret names, i.e. the team name
+	Prefix string
+	// Mapping maps the item fields to the CSV columns, it overrides the format defaults.
+	// The supported fields are name, url, username, password, note, totp and folder
+	Mapping map[string]string
+}
+
+// Entry is an item parsed from the export
+type Entry struct {
+	Item paw.Item
+	// Source is the entry location into the export, i.e. folder/title
+	Source string
+}
+
+// Result holds the parsed entries and the warnings about the skipped ones
+type Result struct {
+	Entries  []*Entry
+	Warnings []string
+}
+
+func (r *Result) warnf(format string, a ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, a...))
+}
+
+// Parse parses the export. The item names are converted to valid secret names.
+func Parse(format Format, r io.Reader, opts Options) (*Result, error) {
+	var res *Result
+	var err error
+	switch format {
+	case Bitwarden:
+		res, err = parseBitwarden(r)
+	case KeePass:
+		res, err = parseKeePass(r)
+	case OnePassword:
+		res, err = parseOnePassword(r)
+	case LastPass, Chrome, Firefox, CSV:
+		res, err = parseCSV(format, r, opts.Mapping)
+	case Env:
+		res, err = parseEnv(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the %s export: %w", format, err)
+	}
+	for _, e := range res.Entries {
+		m := e.Item.GetMetadata()
+		m.Name = SecretName(opts.Prefix + m.Name)
+	}
+	return res, nil
+}
+
+// SecretName converts s to a valid Key Vault secret name: only alphanumeric chars
+// and dashes are allowed, the other ones are replaced by a dash.
+func SecretName(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range s {
+		if r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimRight(sb.String(), "-")
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-")
+	}
+	if name == "" {
+		return "imported"
+	}
+	return name
+}
+
+// hostname returns the host of the URL, used to name the entries without a title
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// newLogin returns a login, the password is a custom one
+func newLogin(name, username, password, rawURL, note string) *paw.Login {
+	login := paw.NewLogin()
+	if name == "" {
+		name = hostname(rawURL)
+	}
+	login.Name = name
+	login.Username = username
+	login.Password = paw.NewCustomPassword()
+	login.Password.Value = password
+	login.URL = rawURL
+	login.Note.Value = note
+	return login
+}
+
+// newNote returns a note
+func newNote(name, value string) *paw.Note {
+	note := paw.NewNote()
+	note.Name = name
+	note.Value = value
+	return note
+}
+
+// newPassword returns a custom password
+func newPassword(name, value, note string) *paw.Password {
+	password := paw.NewCustomPassword()
+	password.Name = name
+	password.Value = value
+	password.Note.Value = note
+	return password
+}
+
+// setTOTP attaches the TOTP, an otpauth URI or a base32 secret, to the login
+func setTOTP(login *paw.Login, uri string) error {
+	if strings.TrimSpace(uri) == "" {
+		return nil
+	}
+	totp := &paw.TOTP{}
+	if err := totp.SetURI(uri); err != nil {
+		return fmt.Errorf("invalid TOTP for %q: %w", login.Name, err)
+	}
+	login.TOTP = totp
+	return nil
+}
+
+// Action is the action planned for an entry
+type Action string
+
+const (
+	// Create creates a new secret
+	Create Action = "create"
+	// Overwrite overwrites the existing secret
+	Overwrite Action = "overwrite"
+	// Skip skips the entry
+	Skip Action = "skip"
+)
+
+// Duplicates is the policy applied to the entries whose name is already taken
+type Duplicates string
+
+const (
+	// SkipDuplicates skips the entries whose name is taken
+	SkipDuplicates Duplicates = "skip"
+	// RenameDuplicates appends a numeric suffix to the name
+	RenameDuplicates Duplicates = "rename"
+	// OverwriteDuplicates overwrites the existing secrets
+	OverwriteDuplicates Duplicates = "overwrite"
+)
+
+// Step is the planned action for an entry
+type Step struct {
+	*Entry
+	Action Action
+	// Reason explains why the entry is skipped or overwritten
+	Reason string
+}
+
+// Plan maps the entries to the vault secrets. The entries with the same content
+// of a secret with the same name, already in the vault or in the export, are always
+// skipped, so that an import can be run again. The other name conflicts are
+// resolved using the duplicates policy. The entries exceeding the Azure limits,
+// i.e. a too long note, are skipped.
+// The values of the conflicting vault secrets are loaded to compare them.
+func Plan(entries []*Entry, vault azure.Vault, duplicates Duplicates) ([]*Step, error) {
+	// taken maps the names to their items, nil for the reserved names
+	taken := map[string]paw.Item{}
+	vault.Range(func(name string, item paw.Item) bool {
+		taken[name] = item
+		return true
+	})
+
+	// planned holds the names taken by the export entries
+	planned := map[string]bool{}
+	steps := make([]*Step, 0, len(entries))
+	for _, e := range entries {
+		if err := azure.ValidateItem(e.Item); err != nil {
+			steps = append(steps, &Step{Entry: e, Action: Skip, Reason: err.Error()})
+			continue
+		}
+		m := e.Item.GetMetadata()
+		existing, ok := taken[m.Name]
+		if azure.IsReservedName(m.Name) {
+			// the reserved names are taken but not listed
+			existing, ok = nil, true
+		}
+		if !ok {
+			taken[m.Name], planned[m.Name] = e.Item, true
+			steps = append(steps, &Step{Entry: e, Action: Create})
+			continue
+		}
+
+		if existing != nil {
+			if !planned[m.Name] && !azure.HasValue(existing) {
+				loaded, err := vault.GetItem(existing)
+				if err != nil {
+					return nil, fmt.Errorf("could not load %q: %w", m.Name, err)
+				}
+				existing = loaded
+				taken[m.Name] = loaded
+			}
+			if sameContent(existing, e.Item) {
+				steps = append(steps, &Step{Entry: e, Action: Skip, Reason: "already imported"})
+				continue
+			}
+		}
+
+		switch duplicates {
+		case OverwriteDuplicates:
+			if existing == nil {
+				steps = append(steps, &Step{Entry: e, Action: Skip, Reason: "reserved name"})
+				continue
+			}
+			taken[m.Name], planned[m.Name] = e.Item, true
+			steps = append(steps, &Step{Entry: e, Action: Overwrite, Reason: "name already taken"})
+		case RenameDuplicates:
+			name := m.Name
+			for i := 2; ; i++ {
+				suffix := fmt.Sprintf("-%d", i)
+				m.Name = SecretName(name[:min(len(name), maxNameLength-len(suffix))] + suffix)
+				if _, ok := taken[m.Name]; !ok {
+					break
+				}
+			}
+			taken[m.Name], planned[m.Name] = e.Item, true
+			steps = append(steps, &Step{Entry: e, Action: Create, Reason: fmt.Sprintf("renamed, %q already taken", name)})
+		default:
+			steps = append(steps, &Step{Entry: e, Action: Skip, Reason: "name already taken"})
+		}
+	}
+	return steps, nil
+}
+
+// Apply writes the planned entries into the vault and returns the number of written secrets
+func Apply(vault azure.Vault, steps []*Step) (int, error) {
+	n := 0
+	for _, s := range steps {
+		if s.Action == Skip {
+			continue
+		}
+		if err := vault.AddItem(s.Item); err != nil {
+			return n, fmt.Errorf("could not import %q: %w", s.Item.GetMetadata().Name, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// sameContent reports whether the items have the same type and content
+func sameContent(a, b paw.Item) bool {
+	if a.GetMetadata().Type != b.GetMetadata().Type {
+		return false
+	}
+	switch x := a.(type) {
+	case *paw.Login:
+		y := b.(*paw.Login)
+		return x.Username == y.Username && x.URL == y.URL && passwordValue(x.Password) == passwordValue(y.Password)
+	case *paw.Password:
+		return x.Value == b.(*paw.Password).Value
+	case *paw.Note:
+		return x.Value == b.(*paw.Note).Value
+	}
+	return false
+}
+
+func passwordValue(p *paw.Password) string {
+	if p == nil {
+		return ""
+	}
+	return p.Value
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,380 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// memVault is an in memory azure.Vault used for testing
+type memVault struct {
+	secrets map[string]paw.Item
+	added   []string
+}
+
+func (v *memVault) AddItem(secret paw.Item) error {
+	v.secrets[secret.GetMetadata().Name] = secret
+	v.added = append(v.added, secret.GetMetadata().Name)
+	return nil
+}
+
+func (v *memVault) DeleteItem(secret paw.Item) error {
+	delete(v.secrets, secret.GetMetadata().Name)
+	return nil
+}
+
+func (v *memVault) GetItem(secret paw.Item) (paw.Item, error) {
+	s, ok := v.secrets[secret.GetMetadata().Name]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return s, nil
+}
+
+func (v *memVault) FilterItemMetadata(opt *paw.VaultFilterOptions) []*paw.Metadata {
+	return nil
+}
+
+func (v *memVault) Key() (*paw.Key, error) {
+	return nil, azure.ErrNoKey
+}
+
+func (v *memVault) CreateKey() error {
+	return azure.ErrNoKey
+}
+
+func (v *memVault) Secret(password *paw.Password, item paw.Item) (string, error) {
+	return azure.GenerateSecret(nil, password, item)
+}
+
+func (v *memVault) ListItems() []string {
+	var names []string
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v *memVault) Range(f func(name string, item paw.Item) bool) {
+	for _, name := range v.ListItems() {
+		if !f(name, v.secrets[name]) {
+			break
+		}
+	}
+}
+
+func (v *memVault) Size() int {
+	return len(v.secrets)
+}
+
+func (v *memVault) SizeByType(_ paw.ItemType) int {
+	return v.Size()
+}
+
+const totpSecret = "JBSWY3DPEHPK3PXP"
+
+func TestSecretName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "github.com", want: "github-com"},
+		{in: "Work / DB (prod)", want: "Work-DB-prod"},
+		{in: "DATABASE_URL", want: "DATABASE-URL"},
+		{in: "-é-", want: "imported"},
+		{in: strings.Repeat("a", 200), want: strings.Repeat("a", 127)},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SecretName(tt.in))
+	}
+}
+
+func TestParseBitwarden(t *testing.T) {
+	export := `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Work"}],
+  "items": [
+    {"type": 1, "name": "GitHub", "notes": "2FA enabled", "folderId": "f1",
+     "fields": [{"name": "recovery", "value": "abc", "type": 1}],
+     "login": {"username": "alice", "password": "s3cret", "totp": "` + totpSecret + `", "uris": [{"uri": "https://github.com"}]}},
+    {"type": 2, "name": "Wifi", "notes": "the wifi password"},
+    {"type": 3, "name": "Visa"}
+  ]
+}`
+	res, err := Parse(Bitwarden, strings.NewReader(export), Options{Prefix: "team-"})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 2)
+	assert.Len(t, res.Warnings, 1)
+
+	login := res.Entries[0].Item.(*paw.Login)
+	assert.Equal(t, "Work/GitHub", res.Entries[0].Source)
+	assert.Equal(t, "team-GitHub", login.Name)
+	assert.Equal(t, "alice", login.Username)
+	assert.Equal(t, "s3cret", login.Password.Value)
+	assert.Equal(t, "https://github.com", login.URL)
+	assert.Equal(t, "2FA enabled", login.Note.Value)
+	assert.Equal(t, totpSecret, login.TOTP.Secret)
+	assert.Equal(t, paw.Fields{{Label: "recovery", Value: "abc", Kind: paw.HiddenField}}, login.Fields)
+
+	note := res.Entries[1].Item.(*paw.Note)
+	assert.Equal(t, "team-Wifi", note.Name)
+	assert.Equal(t, "the wifi password", note.Value)
+
+	_, err = Parse(Bitwarden, strings.NewReader(`{"encrypted": true}`), Options{})
+	assert.Error(t, err)
+}
+
+func TestParseKeePass(t *testing.T) {
+	export := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+  <Meta><RecycleBinUUID>bin</RecycleBinUUID></Meta>
+  <Root>
+    <Group>
+      <UUID>root</UUID>
+      <Name>Database</Name>
+      <Group>
+        <UUID>g1</UUID>
+        <Name>Servers</Name>
+        <Entry>
+          <String><Key>Title</Key><Value>postgres</Value></String>
+          <String><Key>UserName</Key><Value>admin</Value></String>
+          <String><Key>Password</Key><Value ProtectInMemory="True">pg-pass</Value></String>
+          <String><Key>URL</Key><Value>postgres://db.local</Value></String>
+          <String><Key>port</Key><Value>5432</Value></String>
+          <String><Key>otp</Key><Value>otpauth://totp/db?secret=` + totpSecret + `</Value></String>
+          <History>
+            <Entry><String><Key>Title</Key><Value>old</Value></String></Entry>
+          </History>
+        </Entry>
+        <Entry>
+          <String><Key>Title</Key><Value>readme</Value></String>
+          <String><Key>Notes</Key><Value>just a note</Value></String>
+        </Entry>
+      </Group>
+      <Group>
+        <UUID>bin</UUID>
+        <Name>Recycle Bin</Name>
+        <Entry><String><Key>Title</Key><Value>deleted</Value></String><String><Key>Password</Key><Value>x</Value></String></Entry>
+      </Group>
+    </Group>
+  </Root>
+</KeePassFile>`
+	res, err := Parse(KeePass, strings.NewReader(export), Options{})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 2)
+
+	assert.Equal(t, "Servers/postgres", res.Entries[0].Source)
+	login := res.Entries[0].Item.(*paw.Login)
+	assert.Equal(t, "postgres", login.Name)
+	assert.Equal(t, "admin", login.Username)
+	assert.Equal(t, "pg-pass", login.Password.Value)
+	assert.Equal(t, totpSecret, login.TOTP.Secret)
+	assert.Equal(t, paw.Fields{{Label: "port", Value: "5432", Kind: paw.TextField}}, login.Fields)
+
+	note := res.Entries[1].Item.(*paw.Note)
+	assert.Equal(t, "just a note", note.Value)
+}
+
+func TestParseOnePassword(t *testing.T) {
+	data := `{"accounts": [{"vaults": [{"attrs": {"name": "Private"}, "items": [
+  {"state": "active", "categoryUuid": "001",
+   "overview": {"title": "Azure portal", "url": "https://portal.azure.com"},
+   "details": {"loginFields": [{"value": "bob", "designation": "username"}, {"value": "az-pass", "designation": "password"}],
+               "notesPlain": "",
+               "sections": [{"fields": [
+                 {"title": "tenant", "value": {"string": "contoso"}},
+                 {"title": "one-time password", "value": {"totp": "` + totpSecret + `"}},
+                 {"title": "api key", "value": {"concealed": "k3y"}},
+                 {"title": "expiry", "value": {"date": 1640995200}}
+               ]}]}},
+  {"state": "active", "categoryUuid": "005", "overview": {"title": "Router"}, "details": {"password": "r0uter"}},
+  {"state": "trashed", "categoryUuid": "003", "overview": {"title": "Old"}, "details": {"notesPlain": "old"}},
+  {"state": "active", "categoryUuid": "002", "overview": {"title": "Card"}, "details": {}}
+]}]}]}`
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("export.data")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	res, err := Parse(OnePassword, &buf, Options{})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 2)
+	assert.Len(t, res.Warnings, 1)
+
+	login := res.Entries[0].Item.(*paw.Login)
+	assert.Equal(t, "Azure-portal", login.Name)
+	assert.Equal(t, "Private/Azure portal", res.Entries[0].Source)
+	assert.Equal(t, "bob", login.Username)
+	assert.Equal(t, "az-pass", login.Password.Value)
+	assert.Equal(t, totpSecret, login.TOTP.Secret)
+	assert.Equal(t, paw.Fields{
+		{Label: "tenant", Value: "contoso", Kind: paw.TextField},
+		{Label: "api key", Value: "k3y", Kind: paw.HiddenField},
+		{Label: "expiry", Value: "2022-01-01", Kind: paw.DateField},
+	}, login.Fields)
+
+	password := res.Entries[1].Item.(*paw.Password)
+	assert.Equal(t, "r0uter", password.Value)
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		mapping string
+		data    string
+		want    []string // name|username|password|url
+	}{
+		{
+			name:   "lastpass",
+			format: LastPass,
+			data: "url,username,password,totp,extra,name,grouping,fav\n" +
+				"https://example.com,alice,pass1,,,Example,Work,0\n" +
+				"http://sn,,,,secret note,Note,,0\n",
+			want: []string{"Example|alice|pass1|https://example.com", "Note"},
+		},
+		{
+			name:   "chrome",
+			format: Chrome,
+			data:   "name,url,username,password\nexample.com,https://example.com/login,bob,pass2\n",
+			want:   []string{"example-com|bob|pass2|https://example.com/login"},
+		},
+		{
+			name:   "firefox",
+			format: Firefox,
+			data: `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"` + "\n" +
+				`"https://accounts.example.org","carol","pass3",,"https://accounts.example.org","{1}","1","1","1"` + "\n",
+			want: []string{"accounts-example-org|carol|pass3|https://accounts.example.org"},
+		},
+		{
+			name:    "generic with mapping",
+			format:  CSV,
+			mapping: "name=Title,username=Login,password=Secret",
+			data:    "Title,Login,Secret\nDB,dave,pass4\n",
+			want:    []string{"DB|dave|pass4|"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, err := ParseMapping(tt.mapping)
+			require.NoError(t, err)
+			res, err := Parse(tt.format, strings.NewReader(tt.data), Options{Mapping: mapping})
+			require.NoError(t, err)
+
+			var got []string
+			for _, e := range res.Entries {
+				switch i := e.Item.(type) {
+				case *paw.Login:
+					got = append(got, strings.Join([]string{i.Name, i.Username, i.Password.Value, i.URL}, "|"))
+				case *paw.Note:
+					got = append(got, i.Name)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ParseMapping("title=name")
+	assert.Error(t, err)
+	_, err = Parse(CSV, strings.NewReader("a,b\n1,2\n"), Options{})
+	assert.Error(t, err)
+}
+
+func TestParseEnv(t *testing.T) {
+	data := `# comment
+export DATABASE_URL="postgres://u:p@db/app"
+API_KEY=abc123 # inline comment
+MULTILINE="line1\nline2"
+SINGLE='it''s'
+EMPTY=
+`
+	res, err := Parse(Env, strings.NewReader(data), Options{})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 4)
+	assert.Len(t, res.Warnings, 1)
+
+	got := map[string]string{}
+	for _, e := range res.Entries {
+		p := e.Item.(*paw.Password)
+		got[p.Name] = p.Value
+	}
+	assert.Equal(t, map[string]string{
+		"DATABASE-URL": "postgres://u:p@db/app",
+		"API-KEY":      "abc123",
+		"MULTILINE":    "line1\nline2",
+		"SINGLE":       "it",
+	}, got)
+
+	_, err = Parse(Env, strings.NewReader("INVALID\n"), Options{})
+	assert.Error(t, err)
+}
+
+func TestPlanAndApply(t *testing.T) {
+	existing := newLogin("github", "alice", "s3cret", "https://github.com", "")
+	taken := newLogin("gitlab", "alice", "other", "https://gitlab.com", "")
+	newEntries := func() []*Entry {
+		return []*Entry{
+			{Item: newLogin("github", "alice", "s3cret", "https://github.com", "")},
+			{Item: newLogin("gitlab", "alice", "changed", "https://gitlab.com", "")},
+			{Item: newLogin("gitlab", "bob", "pass", "https://gitlab.com", "")},
+			{Item: newPassword("paw-key", "value", "")},
+			{Item: newNote("readme", "note")},
+			{Item: newNote("readme", "note")},
+			{Item: newLogin("long", "alice", "pass", "https://example.com", strings.Repeat("note ", 60))},
+		}
+	}
+
+	tests := []struct {
+		duplicates Duplicates
+		want       []string
+	}{
+		{
+			duplicates: SkipDuplicates,
+			want:       []string{"skip github", "skip gitlab", "skip gitlab", "skip paw-key", "create readme", "skip readme", "skip long"},
+		},
+		{
+			duplicates: RenameDuplicates,
+			want:       []string{"skip github", "create gitlab-2", "create gitlab-3", "create paw-key-2", "create readme", "skip readme", "skip long"},
+		},
+		{
+			duplicates: OverwriteDuplicates,
+			want:       []string{"skip github", "overwrite gitlab", "overwrite gitlab", "skip paw-key", "create readme", "skip readme", "skip long"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.duplicates), func(t *testing.T) {
+			vault := &memVault{secrets: map[string]paw.Item{"github": existing, "gitlab": taken}}
+			steps, err := Plan(newEntries(), vault, tt.duplicates)
+			require.NoError(t, err)
+
+			var got []string
+			want := 0
+			for _, s := range steps {
+				got = append(got, fmt.Sprintf("%s %s", s.Action, s.Item.GetMetadata().Name))
+				if s.Action != Skip {
+					want++
+				}
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, steps[len(steps)-1].Reason, "255 chars Max")
+
+			n, err := Apply(vault, steps)
+			require.NoError(t, err)
+			assert.Equal(t, want, n)
+			assert.Len(t, vault.added, want)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+type keePassFile struct {
+	Meta struct {
+		RecycleBinUUID string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+// keePassEntry is an entry, the History element is not decoded so that
+// the previous versions of the entry are not imported
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Value     string `xml:",chardata"`
+			Protected string `xml:"ProtectInMemory,attr"`
+		} `xml:"Value"`
+	} `xml:"String"`
+}
+
+// keePassStandardFields are the KeePass standard fields, the others are imported as custom fields
+var keePassStandardFields = map[string]bool{
+	"Title":    true,
+	"UserName": true,
+	"Password": true,
+	"URL":      true,
+	"Notes":    true,
+	"otp":      true,
+}
+
+// parseKeePass parses the KeePass 2.x XML export, i.e. from KeePass or KeePassXC.
+// The entries with a username, a password or an URL are imported as logins, the
+// other ones as notes. The recycle bin is skipped.
+func parseKeePass(r io.Reader) (*Result, error) {
+	var file keePassFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	var walk func(groups []keePassGroup, dir string, root bool)
+	walk = func(groups []keePassGroup, dir string, root bool) {
+		for _, g := range groups {
+			if g.UUID != "" && g.UUID == file.Meta.RecycleBinUUID {
+				continue
+			}
+			gdir := dir
+			// the root group is the database itself
+			if !root {
+				gdir = path.Join(dir, g.Name)
+			}
+			for _, e := range g.Entries {
+				parseKeePassEntry(res, e, gdir)
+			}
+			walk(g.Groups, gdir, false)
+		}
+	}
+	walk(file.Root.Groups, "", true)
+	return res, nil
+}
+
+func parseKeePassEntry(res *Result, e keePassEntry, dir string) {
+	values := map[string]string{}
+	var fields paw.Fields
+	for _, s := range e.Strings {
+		values[s.Key] = s.Value.Value
+		if keePassStandardFields[s.Key] || s.Value.Value == "" {
+			continue
+		}
+		kind := paw.TextField
+		if strings.EqualFold(s.Value.Protected, "true") {
+			kind = paw.HiddenField
+		}
+		fields = append(fields, &paw.Field{Label: s.Key, Value: s.Value.Value, Kind: kind})
+	}
+
+	title := values["Title"]
+	source := path.Join(dir, title)
+	if values["UserName"] == "" && values["Password"] == "" && values["URL"] == "" && len(fields) == 0 {
+		if values["Notes"] == "" {
+			res.warnf("%s: empty entry, skipped", source)
+			return
+		}
+		res.Entries = append(res.Entries, &Entry{Item: newNote(title, values["Notes"]), Source: source})
+		return
+	}
+
+	login := newLogin(title, values["UserName"], values["Password"], values["URL"], values["Notes"])
+	login.Fields = fields
+	if err := setTOTP(login, values["otp"]); err != nil {
+		res.warnf("%s: %s", source, err)
+	}
+	res.Entries = append(res.Entries, &Entry{Item: login, Source: source})
+}
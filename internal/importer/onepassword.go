@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+	"time"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// 1Password item categories
+const (
+	onePasswordLogin      = "001"
+	onePasswordSecureNote = "003"
+	onePasswordPassword   = "005"
+)
+
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// parseOnePassword parses the 1Password 1PUX export, a zip archive holding
+// the export.data JSON file. Logins, passwords and secure notes are imported,
+// the other categories and the trashed items are skipped.
+func parseOnePassword(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	f, err := zr.Open("export.data")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var export onePasswordExport
+	if err := json.NewDecoder(f).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				if item.State == "trashed" {
+					continue
+				}
+				parseOnePasswordItem(res, item, vault.Attrs.Name)
+			}
+		}
+	}
+	return res, nil
+}
+
+func parseOnePasswordItem(res *Result, item onePasswordItem, dir string) {
+	title := item.Overview.Title
+	source := path.Join(dir, title)
+	d := item.Details
+
+	switch item.CategoryUUID {
+	case onePasswordLogin:
+		login := newLogin(title, "", "", item.Overview.URL, d.NotesPlain)
+		for _, f := range d.LoginFields {
+			switch f.Designation {
+			case "username":
+				login.Username = f.Value
+			case "password":
+				login.Password.Value = f.Value
+			}
+		}
+		for _, s := range d.Sections {
+			for _, f := range s.Fields {
+				kind, value, ok := onePasswordFieldValue(f.Value)
+				if !ok || value == "" {
+					continue
+				}
+				if kind == "totp" {
+					if err := setTOTP(login, value); err != nil {
+						res.warnf("%s: %s", source, err)
+					}
+					continue
+				}
+				login.Fields = append(login.Fields, &paw.Field{Label: f.Title, Value: value, Kind: paw.FieldKind(kind)})
+			}
+		}
+		res.Entries = append(res.Entries, &Entry{Item: login, Source: source})
+	case onePasswordPassword:
+		res.Entries = append(res.Entries, &Entry{Item: newPassword(title, d.Password, d.NotesPlain), Source: source})
+	case onePasswordSecureNote:
+		res.Entries = append(res.Entries, &Entry{Item: newNote(title, d.NotesPlain), Source: source})
+	default:
+		res.warnf("%s: unsupported category %s, skipped", source, item.CategoryUUID)
+	}
+}
+
+// onePasswordFieldValue returns the kind and the value of a section field.
+// The value is an object with a single key holding the value type, i.e. {"concealed": "secret"}.
+// The returned kind is a paw.FieldKind or "totp". The structured values, like addresses, are skipped.
+func onePasswordFieldValue(v map[string]json.RawMessage) (string, string, bool) {
+	for typ, raw := range v {
+		switch typ {
+		case "email":
+			var e struct {
+				Address string `json:"email_address"`
+			}
+			if err := json.Unmarshal(raw, &e); err != nil {
+				return "", "", false
+			}
+			return string(paw.EmailField), e.Address, true
+		case "date":
+			var ts int64
+			if err := json.Unmarshal(raw, &ts); err != nil {
+				return "", "", false
+			}
+			return string(paw.DateField), time.Unix(ts, 0).UTC().Format(paw.FieldDateLayout), true
+		}
+
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", "", false
+		}
+		switch typ {
+		case "concealed":
+			return string(paw.HiddenField), s, true
+		case "url":
+			return string(paw.URLField), s, true
+		case "totp":
+			return "totp", s, true
+		}
+		return string(paw.TextField), s, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,199 @@
+// Package kit implements the emergency kit that allows to recover the key of a vault,
+// i.e. when the only device holding the key is lost.
+//
+// The kit holds the age identity, or its Shamir shares, as text and QR code so that
+// it can be printed and stored offline.
+package kit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"lucor.dev/paw/internal/age/bech32"
+	"lucor.dev/paw/internal/paw"
+	"lucor.dev/paw/internal/shamir"
+)
+
+// identityHRP is the human readable part of the age X25519 identities
+const identityHRP = "AGE-SECRET-KEY-"
+
+// Kit is the emergency kit of a vault
+type Kit struct {
+	// Vault is the vault name
+	Vault string
+	// Created is the kit creation date
+	Created time.Time
+	// KeyID is the ID of the key, see paw.KeyInfo
+	KeyID string
+	// Checksum allows to verify the recovered key
+	Checksum string
+	// Threshold is the number of shares required to recover the key, zero if the key is not split
+	Threshold int
+	// Secrets holds the bech32 encoded identity or, if split, the shares. Each secret is a page of the kit.
+	Secrets []string
+}
+
+// New returns the emergency kit for the vault key.
+// If n is greater than zero the key is split into n Shamir shares, threshold of them are
+// required to recover the key, and each share is printed on its own page.
+func New(vault string, key *paw.Key, n int, threshold int) (*Kit, error) {
+	if key == nil || key.IsOneTime() {
+		return nil, errors.New("kit: the vault key is not persistent")
+	}
+	secret, err := decodeIdentity(key.Identity())
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Kit{
+		Vault:    vault,
+		Created:  time.Now(),
+		KeyID:    key.Info().ID,
+		Checksum: checksum(secret),
+	}
+	if n == 0 {
+		k.Secrets = []string{key.Identity()}
+		return k, nil
+	}
+
+	shares, err := shamir.Split(secret, n, threshold)
+	if err != nil {
+		return nil, err
+	}
+	k.Threshold = threshold
+	for _, s := range shares {
+		v, err := s.Encode()
+		if err != nil {
+			return nil, err
+		}
+		k.Secrets = append(k.Secrets, v)
+	}
+	return k, nil
+}
+
+// Pages returns the number of pages of the kit
+func (k *Kit) Pages() int {
+	return len(k.Secrets)
+}
+
+// lines returns the text lines of the page i
+func (k *Kit) lines(i int) []string {
+	lines := []string{
+		"PAW EMERGENCY KIT",
+		"",
+		fmt.Sprintf("Vault:    %s", k.Vault),
+		fmt.Sprintf("Created:  %s", k.Created.Format(time.RFC1123)),
+		fmt.Sprintf("Key ID:   %s", k.KeyID),
+		fmt.Sprintf("Checksum: %s", k.Checksum),
+		"",
+	}
+	if k.Threshold > 0 {
+		lines = append(lines,
+			fmt.Sprintf("Share %d of %d, %d shares are required to recover the key.", i+1, len(k.Secrets), k.Threshold),
+			"Store each share in a different place or give it to a different person.",
+		)
+	} else {
+		lines = append(lines, "Anyone with this kit can read the vault. Store it in a safe place.")
+	}
+	lines = append(lines,
+		"",
+		k.Secrets[i],
+		"",
+		fmt.Sprintf("Recover the key using: paw recover -vault %s", k.Vault),
+	)
+	return lines
+}
+
+// WriteText writes the page i of the kit as text
+func (k *Kit) WriteText(w io.Writer, i int) error {
+	if i < 0 || i >= len(k.Secrets) {
+		return fmt.Errorf("kit: invalid page %d", i)
+	}
+	_, err := io.WriteString(w, strings.Join(k.lines(i), "\n")+"\n")
+	return err
+}
+
+// Recover recovers the key from the secrets of the kit: the bech32 encoded identity
+// or the threshold shares. The returned checksum must match the one printed on the kit.
+func Recover(secrets []string) (*paw.Key, string, error) {
+	if len(secrets) == 0 {
+		return nil, "", errors.New("kit: the identity or the shares are required")
+	}
+
+	var secret []byte
+	first := strings.ToUpper(strings.Join(strings.Fields(secrets[0]), ""))
+	if strings.HasPrefix(first, identityHRP) {
+		if len(secrets) > 1 {
+			return nil, "", errors.New("kit: only an identity is expected")
+		}
+		var err error
+		secret, err = decodeIdentity(first)
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		var shares []shamir.Share
+		for _, v := range secrets {
+			s, err := shamir.ParseShare(v)
+			if err != nil {
+				return nil, "", err
+			}
+			shares = append(shares, s)
+		}
+		var err error
+		secret, err = shamir.Combine(shares)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	identity, err := bech32.Encode(identityHRP, secret)
+	if err != nil {
+		return nil, "", fmt.Errorf("kit: could not encode the identity: %w", err)
+	}
+	key, err := paw.ParseKey(identity)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, checksum(secret), nil
+}
+
+// decodeIdentity returns the secret of the age X25519 identity
+func decodeIdentity(identity string) ([]byte, error) {
+	// the dashes of the human readable part are meaningful
+	identity = identityHRP + strings.ReplaceAll(strings.TrimPrefix(identity, identityHRP), "-", "")
+	hrp, secret, err := bech32.Decode(identity)
+	if err != nil {
+		return nil, fmt.Errorf("kit: invalid identity: %w", err)
+	}
+	if hrp != identityHRP {
+		return nil, fmt.Errorf("kit: invalid identity type: %s", hrp)
+	}
+	return secret, nil
+}
+
+// checksum returns a short checksum of the secret, to be compared by humans
+func checksum(secret []byte) string {
+	h := sha256.Sum256(secret)
+	return fmt.Sprintf("%X-%X", h[:2], h[2:4])
+}
+
+// ReadSecrets reads the identity or the shares from r, i.e. a text page of the kit
+// or the secrets typed one per line. The other lines are ignored.
+func ReadSecrets(r io.Reader) ([]string, error) {
+	var secrets []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		upper := strings.ToUpper(line)
+		if strings.HasPrefix(upper, identityHRP) || strings.HasPrefix(upper, shamir.ShareHRP) {
+			secrets = append(secrets, line)
+		}
+	}
+	return secrets, scanner.Err()
+}
@@ -0,0 +1,93 @@
+package kit
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+func TestKitRecover(t *testing.T) {
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+
+	t.Run("identity", func(t *testing.T) {
+		k, err := New("team", key, 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, k.Pages())
+		assert.Equal(t, key.Identity(), k.Secrets[0])
+		assert.Equal(t, key.Info().ID, k.KeyID)
+
+		// typed back in lower case
+		recovered, checksum, err := Recover([]string{strings.ToLower(k.Secrets[0])})
+		require.NoError(t, err)
+		assert.Equal(t, key.Identity(), recovered.Identity())
+		assert.Equal(t, k.Checksum, checksum)
+
+		// typed back with spaces and dashes
+		identity := k.Secrets[0]
+		split := identity[:20] + " " + identity[20:40] + "-" + identity[40:60] + "\n" + identity[60:]
+		recovered, checksum, err = Recover([]string{split})
+		require.NoError(t, err)
+		assert.Equal(t, key.Identity(), recovered.Identity())
+		assert.Equal(t, k.Checksum, checksum)
+	})
+
+	t.Run("shares", func(t *testing.T) {
+		k, err := New("team", key, 5, 3)
+		require.NoError(t, err)
+		require.Equal(t, 5, k.Pages())
+		for _, s := range k.Secrets {
+			assert.NotContains(t, s, key.Identity())
+		}
+
+		recovered, checksum, err := Recover([]string{k.Secrets[4], k.Secrets[0], k.Secrets[2]})
+		require.NoError(t, err)
+		assert.Equal(t, key.Identity(), recovered.Identity())
+		assert.Equal(t, k.Checksum, checksum)
+
+		_, _, err = Recover(k.Secrets[:2])
+		assert.Error(t, err, "not enough shares")
+	})
+
+	_, err = New("team", key, 2, 3)
+	assert.Error(t, err)
+	oneTime, err := paw.MakeOneTimeKey()
+	require.NoError(t, err)
+	_, err = New("team", oneTime, 0, 0)
+	assert.Error(t, err)
+	_, _, err = Recover(nil)
+	assert.Error(t, err)
+	_, _, err = Recover([]string{"AGE-SECRET-KEY-1INVALID"})
+	assert.Error(t, err)
+}
+
+func TestKitWrite(t *testing.T) {
+	key, err := paw.GenerateKey()
+	require.NoError(t, err)
+	k, err := New("team", key, 3, 2)
+	require.NoError(t, err)
+
+	for i := 0; i < k.Pages(); i++ {
+		buf := &bytes.Buffer{}
+		require.NoError(t, k.WriteText(buf, i))
+		assert.Contains(t, buf.String(), k.Secrets[i])
+		assert.Contains(t, buf.String(), k.Checksum)
+		assert.Contains(t, buf.String(), "2 shares are required")
+
+		buf.Reset()
+		require.NoError(t, k.WritePNG(buf, i))
+		img, err := png.Decode(buf)
+		require.NoError(t, err)
+		assert.Equal(t, pageWidth, img.Bounds().Dx())
+		assert.Equal(t, pageHeight, img.Bounds().Dy())
+	}
+
+	assert.Error(t, k.WriteText(&bytes.Buffer{}, 3))
+	assert.Error(t, k.WritePNG(&bytes.Buffer{}, -1))
+}
@@ -0,0 +1,80 @@
+package kit
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	qrcode "github.com/skip2/go-qrcode"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// The page is an A4 sheet at 150 DPI
+const (
+	pageWidth  = 1240
+	pageHeight = 1754
+	pageMargin = 60
+
+	// textScale scales the basic font, so that it is readable once printed
+	textScale = 2
+	qrSize    = 640
+)
+
+// WritePNG writes the page i of the kit as printable PNG image.
+// The page holds the kit text and a QR code encoding the secret.
+func (k *Kit) WritePNG(w io.Writer, i int) error {
+	if i < 0 || i >= len(k.Secrets) {
+		return fmt.Errorf("kit: invalid page %d", i)
+	}
+
+	page := image.NewRGBA(image.Rect(0, 0, pageWidth, pageHeight))
+	draw.Draw(page, page.Bounds(), image.White, image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	lineHeight := face.Metrics().Height.Ceil() * textScale * 3 / 2
+	y := pageMargin
+	for _, line := range k.lines(i) {
+		drawText(page, line, pageMargin, y, face)
+		y += lineHeight
+	}
+
+	qr, err := qrcode.New(k.Secrets[i], qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("kit: could not generate the QR code: %w", err)
+	}
+	img := qr.Image(qrSize)
+	x := (pageWidth - qrSize) / 2
+	y += lineHeight
+	draw.Draw(page, image.Rect(x, y, x+qrSize, y+qrSize), img, image.Point{}, draw.Src)
+
+	return png.Encode(w, page)
+}
+
+// drawText draws the text at x, y using the face scaled by textScale
+func drawText(dst draw.Image, text string, x int, y int, face font.Face) {
+	if text == "" {
+		return
+	}
+	metrics := face.Metrics()
+	width := font.MeasureString(face, text).Ceil()
+	height := metrics.Height.Ceil()
+
+	src := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	d := &font.Drawer{
+		Dst:  src,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+		Dot:  fixed.P(0, metrics.Ascent.Ceil()),
+	}
+	d.DrawString(text)
+
+	r := image.Rect(x, y, x+width*textScale, y+height*textScale)
+	xdraw.NearestNeighbor.Scale(dst, r, src, src.Bounds(), draw.Src, nil)
+}
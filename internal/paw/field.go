@@ -0,0 +1,89 @@
+package paw
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FieldKind represents the kind of a custom field value
+type FieldKind string
+
+const (
+	// TextField is a plain text field
+	TextField FieldKind = "text"
+	// HiddenField is a text field whose value is concealed, i.e. API keys
+	HiddenField FieldKind = "hidden"
+	// URLField is an URL field
+	URLField FieldKind = "url"
+	// EmailField is an email address field
+	EmailField FieldKind = "email"
+	// DateField is a date field in the YYYY-MM-DD format
+	DateField FieldKind = "date"
+)
+
+// FieldDateLayout is the layout used to format the date fields
+const FieldDateLayout = "2006-01-02"
+
+// FieldKinds returns the supported field kinds
+func FieldKinds() []FieldKind {
+	return []FieldKind{TextField, HiddenField, URLField, EmailField, DateField}
+}
+
+// Field is a custom field of an item
+type Field struct {
+	Label string    `json:"label"`
+	Value string    `json:"value,omitempty"`
+	Kind  FieldKind `json:"kind,omitempty"`
+}
+
+// Validate returns an error if the field value does not match its kind
+func (f *Field) Validate() error {
+	if f.Label == "" {
+		return fmt.Errorf("field label cannot be empty")
+	}
+	if f.Value == "" {
+		return nil
+	}
+	var err error
+	switch f.Kind {
+	case TextField, HiddenField, "":
+	case URLField:
+		_, err = url.ParseRequestURI(f.Value)
+	case EmailField:
+		_, err = mail.ParseAddress(f.Value)
+	case DateField:
+		_, err = time.Parse(FieldDateLayout, f.Value)
+	default:
+		return fmt.Errorf("invalid kind %q for field %q", f.Kind, f.Label)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s for field %q: %w", f.Kind, f.Label, err)
+	}
+	return nil
+}
+
+// Fields is an ordered list of custom fields
+type Fields []*Field
+
+// Get returns the first field matching the label. Labels are case insensitive.
+func (fields Fields) Get(label string) (*Field, bool) {
+	for _, f := range fields {
+		if strings.EqualFold(f.Label, label) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Validate returns an error if any of the fields is invalid
+func (fields Fields) Validate() error {
+	for _, f := range fields {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package paw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   *Field
+		wantErr bool
+	}{
+		{name: "text", field: &Field{Label: "host", Value: "db.example.com", Kind: TextField}},
+		{name: "hidden", field: &Field{Label: "api key", Value: "s3cr3t", Kind: HiddenField}},
+		{name: "empty value", field: &Field{Label: "port", Kind: DateField}},
+		{name: "url", field: &Field{Label: "portal", Value: "https://portal.example.com", Kind: URLField}},
+		{name: "email", field: &Field{Label: "owner", Value: "ops@example.com", Kind: EmailField}},
+		{name: "date", field: &Field{Label: "expires", Value: "2026-12-31", Kind: DateField}},
+		{name: "no label", field: &Field{Value: "value"}, wantErr: true},
+		{name: "invalid url", field: &Field{Label: "portal", Value: "portal", Kind: URLField}, wantErr: true},
+		{name: "invalid email", field: &Field{Label: "owner", Value: "ops", Kind: EmailField}, wantErr: true},
+		{name: "invalid date", field: &Field{Label: "expires", Value: "31/12/2026", Kind: DateField}, wantErr: true},
+		{name: "invalid kind", field: &Field{Label: "port", Value: "5432", Kind: "number"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFieldsGet(t *testing.T) {
+	fields := Fields{
+		{Label: "Host", Value: "db.example.com"},
+		{Label: "Port", Value: "5432"},
+		{Label: "port", Value: "ignored"},
+	}
+	f, ok := fields.Get("port")
+	require.True(t, ok)
+	assert.Equal(t, "5432", f.Value)
+
+	_, ok = fields.Get("tenant")
+	assert.False(t, ok)
+}
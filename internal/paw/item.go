@@ -1,6 +1,7 @@
 package paw
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -16,6 +17,10 @@ const (
 	PasswordItemType
 	// LoginItemType is the Website Item type
 	LoginItemType
+	// SSHKeyItemType is the SSH Key Item type
+	SSHKeyItemType
+	// TOTPItemType is the TOTP Item type
+	TOTPItemType
 )
 
 func (it ItemType) String() string {
@@ -28,6 +33,10 @@ func (it ItemType) String() string {
 		return "password"
 	case LoginItemType:
 		return "login"
+	case SSHKeyItemType:
+		return "sshkey"
+	case TOTPItemType:
+		return "totp"
 	}
 	return "invalid"
 }
@@ -42,6 +51,10 @@ func ItemTypeFromString(v string) (ItemType, error) {
 		itemType = NoteItemType
 	case PasswordItemType.String():
 		itemType = PasswordItemType
+	case SSHKeyItemType.String():
+		itemType = SSHKeyItemType
+	case TOTPItemType.String():
+		itemType = TOTPItemType
 	default:
 		err = fmt.Errorf("invalid item type %q", v)
 	}
@@ -67,9 +80,34 @@ func NewItem(name string, itemType ItemType) (Item, error) {
 		item = NewNote()
 	case PasswordItemType:
 		item = NewPassword()
+	case SSHKeyItemType:
+		item = NewSSHKey()
+	case TOTPItemType:
+		item = NewTOTP()
 	default:
 		return nil, fmt.Errorf("invalid item type %q", itemType)
 	}
 	item.GetMetadata().Name = name
 	return item, nil
 }
+
+// UnmarshalItem decodes the JSON representation of an item according to its metadata type
+func UnmarshalItem(data []byte) (Item, error) {
+	v := struct {
+		Metadata *Metadata `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if v.Metadata == nil {
+		return nil, fmt.Errorf("item metadata is missing")
+	}
+	item, err := NewItem(v.Metadata.Name, v.Metadata.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -12,6 +12,10 @@ type Login struct {
 
 	Username string `json:"username,omitempty"`
 	URL      string `json:"url,omitempty"`
+	// TOTP is the optional one-time password attached to the login
+	TOTP *TOTP `json:"totp,omitempty"`
+	// Fields holds the custom fields, i.e. host, port or tenant ID
+	Fields Fields `json:"fields,omitempty"`
 }
 
 func NewLogin() *Login {
@@ -17,17 +17,18 @@ type Metadata struct {
 	Modified time.Time `json:"modified,omitempty"`
 	// Created holds the creation date
 	Created time.Time `json:"created,omitempty"`
+	// Expires holds the expiration date, zero if the item does not expire
+	Expires time.Time `json:"expires,omitempty"`
 	// Icon
 	Favicon *Favicon `json:"favicon,omitempty"`
 }
 
+// ID returns the item identifier, the hash of the item type and name.
+// The name is hashed as data since the blake2b keys are limited to 64 bytes.
 func (m *Metadata) ID() string {
-	key := append([]byte(m.Type.String()), []byte(m.Name)...)
-	hash, err := blake2b.New256(key)
-	if err != nil {
-		panic(err)
-	}
-	return hex.EncodeToString(hash.Sum(nil))
+	data := append([]byte(m.Type.String()), []byte(m.Name)...)
+	hash := blake2b.Sum256(data)
+	return hex.EncodeToString(hash[:])
 }
 
 func (m *Metadata) GetMetadata() *Metadata {
@@ -2,9 +2,7 @@ package paw
 
 import (
 	"fmt"
-	"strings"
-
-	"lucor.dev/paw/internal/age"
+	"strconv"
 )
 
 // Declare conformity to Item interface
@@ -14,27 +12,34 @@ var _ Item = (*Password)(nil)
 var _ Seeder = (*Password)(nil)
 
 const (
-	RandomPasswordDefaultLength     = 16
-	RandomPasswordMinLength         = 8
-	RandomPasswordMaxLength         = 120
-	RandomPasswordDefaultFormat     = LowercaseFormat | DigitsFormat | SymbolsFormat | UppercaseFormat
-	PinPasswordDefaultLength        = 4
-	PinPasswordMinLength            = 3
-	PinPasswordMaxLength            = 10
-	PinPasswordDefaultFormat        = DigitsFormat
-	PassphrasePasswordDefaultLength = 4
-	PassphrasePasswordMinLength     = 3
-	PassphrasePasswordMaxLength     = 12
+	RandomPasswordDefaultLength        = 16
+	RandomPasswordMinLength            = 8
+	RandomPasswordMaxLength            = 120
+	RandomPasswordDefaultFormat        = LowercaseFormat | DigitsFormat | SymbolsFormat | UppercaseFormat
+	PinPasswordDefaultLength           = 4
+	PinPasswordMinLength               = 3
+	PinPasswordMaxLength               = 10
+	PinPasswordDefaultFormat           = DigitsFormat
+	PassphrasePasswordDefaultLength    = 4
+	PassphrasePasswordMinLength        = 3
+	PassphrasePasswordMaxLength        = 12
+	StatelessPasswordDefaultCounter    = 1
+	PronounceablePasswordDefaultLength = 12
+	PronounceablePasswordMinLength     = 6
+	PronounceablePasswordMaxLength     = 64
+	PronounceablePasswordDefaultFormat = LowercaseFormat | DigitsFormat
 )
 
 type PasswordMode uint32
 
 const (
-	CustomPassword     PasswordMode = 0
-	RandomPassword     PasswordMode = 1
-	PassphrasePassword PasswordMode = 2
-	PinPassword        PasswordMode = 3
-	StatelessPassword  PasswordMode = 4
+	CustomPassword        PasswordMode = 0
+	RandomPassword        PasswordMode = 1
+	PassphrasePassword    PasswordMode = 2
+	PinPassword           PasswordMode = 3
+	StatelessPassword     PasswordMode = 4
+	PatternPassword       PasswordMode = 5
+	PronounceablePassword PasswordMode = 6
 )
 
 func (pm PasswordMode) String() string {
@@ -49,6 +54,10 @@ func (pm PasswordMode) String() string {
 		return "Pin"
 	case PassphrasePassword:
 		return "Passphrase"
+	case PatternPassword:
+		return "Pattern"
+	case PronounceablePassword:
+		return "Pronounceable"
 	}
 	return fmt.Sprintf("Unknown password mode (%d)", pm)
 }
@@ -58,6 +67,31 @@ type Password struct {
 	Format Format       `json:"format,omitempty"`
 	Length int          `json:"length,omitempty"`
 	Mode   PasswordMode `json:"mode,omitempty"`
+	// Min holds the minimum number of chars required for each format class
+	Min map[Format]int `json:"min,omitempty"`
+	// MaxConsecutive is the max number of identical consecutive chars. Zero means no limit
+	MaxConsecutive int `json:"max_consecutive,omitempty"`
+	// Exclude holds the chars excluded from the password, i.e. the look-alikes
+	Exclude string `json:"exclude,omitempty"`
+	// Symbols holds the allowed symbols. Empty means all the symbols are allowed
+	Symbols string `json:"symbols,omitempty"`
+	// Counter is the version of a stateless password, bump it to rotate the password
+	Counter int `json:"counter,omitempty"`
+	// Rules holds the password policy in the passwordrules language, i.e. published by the site.
+	// When set it replaces the format, the min counts, the max consecutive chars and the allowed symbols
+	Rules string `json:"rules,omitempty"`
+	// Separator is the passphrase words separator. Empty means the default one
+	Separator string `json:"separator,omitempty"`
+	// Capitalization is the passphrase words capitalization style
+	Capitalization Capitalization `json:"capitalization,omitempty"`
+	// InjectDigit injects a digit into a passphrase word
+	InjectDigit bool `json:"inject_digit,omitempty"`
+	// InjectSymbol injects a symbol into a passphrase word
+	InjectSymbol bool `json:"inject_symbol,omitempty"`
+	// Wordlist is the name of the passphrase wordlist. Empty means the default one
+	Wordlist string `json:"wordlist,omitempty"`
+	// Pattern is the pattern of the password, i.e. Cvcc-9999-Cvcc. See ParsePattern
+	Pattern string `json:"pattern,omitempty"`
 
 	*Metadata `json:"metadata,omitempty"`
 	*Note     `json:"note,omitempty"`
@@ -95,6 +129,30 @@ func NewPassphrasePassword() *Password {
 	return password
 }
 
+func NewStatelessPassword() *Password {
+	password := NewPassword()
+	password.Mode = StatelessPassword
+	password.Format = RandomPasswordDefaultFormat
+	password.Length = RandomPasswordDefaultLength
+	password.Counter = StatelessPasswordDefaultCounter
+	return password
+}
+
+func NewPatternPassword() *Password {
+	password := NewPassword()
+	password.Mode = PatternPassword
+	password.Pattern = PatternPasswordDefaultPattern
+	return password
+}
+
+func NewPronounceablePassword() *Password {
+	password := NewPassword()
+	password.Mode = PronounceablePassword
+	password.Format = PronounceablePasswordDefaultFormat
+	password.Length = PronounceablePasswordDefaultLength
+	return password
+}
+
 func NewCustomPassword() *Password {
 	password := NewPassword()
 	password.Mode = CustomPassword
@@ -104,36 +162,149 @@ func NewCustomPassword() *Password {
 // Implemets Seeder interface
 
 func (p *Password) Salt() []byte {
-	if p.Mode == StatelessPassword {
+	if p.Mode == StatelessPassword && p.Metadata != nil && p.Name != "" {
 		return []byte(p.ID())
 	}
 	return nil
 }
 
+// Info returns the counter for stateless passwords so that
+// a new password is derived when the counter is bumped
 func (p *Password) Info() []byte {
+	if p.Mode == StatelessPassword {
+		return []byte(strconv.Itoa(p.Counter))
+	}
+	return nil
+}
+
+// Stateless reports whether the password is derived from the key and the item
+func (p *Password) Stateless() bool {
+	return p.Mode == StatelessPassword
+}
+
+// SeederFor returns the seeder used to generate the password of the item.
+// Stateless passwords are derived from the item ID, so that the password
+// attached to a login is bound to the login.
+func (p *Password) SeederFor(item Item) Seeder {
+	if item == nil || item == Item(p) {
+		return p
+	}
+	return &itemPassword{Password: p, id: item.ID()}
+}
+
+// itemPassword is a password bound to an item
+type itemPassword struct {
+	*Password
+	id string
+}
+
+func (p *itemPassword) Salt() []byte {
+	if p.Mode == StatelessPassword {
+		return []byte(p.id)
+	}
 	return nil
 }
 
 func (p *Password) Template() (string, error) {
-	ruler, err := NewRule(p.Length, p.Format)
+	ruler, err := p.Rule()
 	if err != nil {
 		return "", err
 	}
 	return ruler.Template()
 }
 
+// Rule returns the rule used to generate the password
+func (p *Password) Rule() (*Rule, error) {
+	if p.Rules != "" {
+		rule, err := ParsePasswordRules(p.Rules)
+		if err != nil {
+			return nil, err
+		}
+		if p.Length > 0 {
+			rule.Length = rule.ClampLength(p.Length)
+		}
+		rule.WithFilter([]byte(p.Exclude))
+		return rule, nil
+	}
+
+	rule, err := NewRule(p.Length, p.Format)
+	if err != nil {
+		return nil, err
+	}
+	for format, n := range p.Min {
+		// ignore the classes not enabled by the format
+		if p.Format&format != 0 {
+			rule.WithMin(format, n)
+		}
+	}
+	rule.WithMaxConsecutive(p.MaxConsecutive)
+	rule.WithFilter([]byte(p.Exclude))
+	if err := rule.WithSymbols([]byte(p.Symbols)); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
 func (p *Password) Len() int {
+	if pattern, err := p.PasswordPattern(); err == nil && pattern != nil {
+		return pattern.MaxLen()
+	}
+	if p.Rules != "" {
+		if rule, err := p.Rule(); err == nil {
+			return rule.Len()
+		}
+	}
 	return p.Length
 }
 
-func (p *Password) Pwgen(key *Key) (string, error) {
-	if p.Mode == PassphrasePassword {
-		var words []string
-		for i := 0; i < p.Length; i++ {
-			words = append(words, age.RandomWord())
-		}
-		return strings.Join(words, "-"), nil
+// Passphrase returns the options to generate the passphrase.
+// It returns nil if the password is not a passphrase.
+func (p *Password) Passphrase() (*Passphrase, error) {
+	if p.Mode != PassphrasePassword {
+		return nil, nil
+	}
+	words, err := Wordlist(p.Wordlist)
+	if err != nil {
+		return nil, err
+	}
+	separator := p.Separator
+	if separator == "" {
+		separator = PassphraseDefaultSeparator
+	}
+	return &Passphrase{
+		Words:          words,
+		Length:         p.Length,
+		Separator:      separator,
+		Capitalization: p.Capitalization,
+		Digit:          p.InjectDigit,
+		Symbol:         p.InjectSymbol,
+	}, nil
+}
+
+// PasswordPattern returns the parsed pattern.
+// It returns nil if the password is not generated from a pattern.
+func (p *Password) PasswordPattern() (*Pattern, error) {
+	if p.Mode != PatternPassword {
+		return nil, nil
 	}
+	return ParsePattern(p.Pattern)
+}
+
+// Pronounceable returns the options to generate the pronounceable password.
+// It returns nil if the password is not pronounceable.
+func (p *Password) Pronounceable() *Pronounceable {
+	if p.Mode != PronounceablePassword {
+		return nil
+	}
+	return &Pronounceable{
+		Length:    p.Length,
+		Uppercase: p.Format&UppercaseFormat != 0,
+		Digits:    p.Format&DigitsFormat != 0,
+		Symbol:    p.Format&SymbolsFormat != 0,
+	}
+}
+
+func (p *Password) Pwgen(key *Key) (string, error) {
 	secret, err := key.Secret(p)
 	if err != nil {
 		return "", fmt.Errorf("could not generate password: %w", err)
@@ -0,0 +1,83 @@
+package paw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordStateless(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	login := NewLogin()
+	login.Name = "example.com"
+	login.Password = NewStatelessPassword()
+
+	secret, err := key.Secret(login.Password.SeederFor(login))
+	require.NoError(t, err)
+	assert.Len(t, secret, login.Password.Length)
+
+	t.Run("same key and item", func(t *testing.T) {
+		parsed, err := ParseKey(key.Identity())
+		require.NoError(t, err)
+		again := NewLogin()
+		again.Name = "example.com"
+		again.Password = NewStatelessPassword()
+		got, err := parsed.Secret(again.Password.SeederFor(again))
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("counter bumped", func(t *testing.T) {
+		p := *login.Password
+		p.Counter++
+		got, err := key.Secret(p.SeederFor(login))
+		require.NoError(t, err)
+		assert.NotEqual(t, secret, got)
+	})
+
+	t.Run("other item", func(t *testing.T) {
+		other := NewLogin()
+		other.Name = "example.org"
+		got, err := key.Secret(login.Password.SeederFor(other))
+		require.NoError(t, err)
+		assert.NotEqual(t, secret, got)
+	})
+
+	t.Run("password item", func(t *testing.T) {
+		p := NewStatelessPassword()
+		p.Metadata = &Metadata{Name: "example.com", Type: PasswordItemType}
+		got, err := key.Secret(p.SeederFor(p))
+		require.NoError(t, err)
+		assert.NotEqual(t, secret, got)
+	})
+
+	t.Run("long name", func(t *testing.T) {
+		// Azure secret names can be up to 127 chars
+		long := NewLogin()
+		long.Name = strings.Repeat("a", 127)
+		got, err := key.Secret(login.Password.SeederFor(long))
+		require.NoError(t, err)
+		assert.Len(t, got, login.Password.Length)
+		long.Name = strings.Repeat("a", 126) + "b"
+		other, err := key.Secret(login.Password.SeederFor(long))
+		require.NoError(t, err)
+		assert.NotEqual(t, got, other)
+	})
+
+	t.Run("without item", func(t *testing.T) {
+		_, err := key.Secret(login.Password)
+		assert.Error(t, err)
+	})
+
+	t.Run("one time key", func(t *testing.T) {
+		oneTimeKey, err := MakeOneTimeKey()
+		require.NoError(t, err)
+		assert.True(t, oneTimeKey.IsOneTime())
+		_, err = oneTimeKey.Secret(login.Password.SeederFor(login))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package paw
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Declare conformity to Item interface
+var _ Item = (*SSHKey)(nil)
+
+// SSHKeyType represents the SSH key algorithm
+type SSHKeyType string
+
+const (
+	// ED25519SSHKey is the ed25519 SSH key type
+	ED25519SSHKey SSHKeyType = "ed25519"
+	// RSASSHKey is the RSA SSH key type
+	RSASSHKey SSHKeyType = "rsa"
+)
+
+const (
+	// RSASSHKeyDefaultBits is the default size for RSA keys
+	RSASSHKeyDefaultBits = 4096
+)
+
+// SSHKey represents an SSH key pair. The private key is PEM encoded,
+// the public key is in the OpenSSH authorized_keys format.
+type SSHKey struct {
+	PrivateKey  string `json:"private_key,omitempty"`
+	PublicKey   string `json:"public_key,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	*Metadata `json:"metadata,omitempty"`
+	*Note     `json:"note,omitempty"`
+}
+
+func NewSSHKey() *SSHKey {
+	return &SSHKey{
+		Metadata: &Metadata{
+			Type: SSHKeyItemType,
+		},
+		Note: &Note{},
+	}
+}
+
+// Generate generates a new key pair of the specified type replacing the current one.
+// Bits is used only for RSA keys, if zero RSASSHKeyDefaultBits is used.
+func (k *SSHKey) Generate(keyType SSHKeyType, bits int) error {
+	var privateKey interface{}
+	var err error
+	switch keyType {
+	case ED25519SSHKey:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case RSASSHKey:
+		if bits == 0 {
+			bits = RSASSHKeyDefaultBits
+		}
+		privateKey, err = rsa.GenerateKey(rand.Reader, bits)
+	default:
+		return fmt.Errorf("invalid SSH key type %q", keyType)
+	}
+	if err != nil {
+		return fmt.Errorf("could not generate the SSH key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("could not encode the SSH key: %w", err)
+	}
+	block := &pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}
+	return k.SetPrivateKey(string(pem.EncodeToMemory(block)))
+}
+
+// SetPrivateKey sets the PEM encoded private key deriving the public key and
+// its fingerprint. Passphrase protected keys are not supported.
+func (k *SSHKey) SetPrivateKey(privateKey string) error {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return fmt.Errorf("invalid SSH private key: %w", err)
+	}
+	k.PrivateKey = privateKey
+	k.PublicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	k.Fingerprint = ssh.FingerprintSHA256(signer.PublicKey())
+	return nil
+}
+
+// Signer returns a signer for the private key
+func (k *SSHKey) Signer() (ssh.Signer, error) {
+	if k.PrivateKey == "" {
+		return nil, fmt.Errorf("SSH private key not loaded")
+	}
+	return ssh.ParsePrivateKey([]byte(k.PrivateKey))
+}
+
+// AuthorizedKey returns the public key in the authorized_keys format using
+// the item name as comment
+func (k *SSHKey) AuthorizedKey() string {
+	if k.PublicKey == "" {
+		return ""
+	}
+	return k.PublicKey + " " + k.Name
+}
@@ -0,0 +1,71 @@
+package paw
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHKeyGenerate(t *testing.T) {
+	tests := []struct {
+		keyType    SSHKeyType
+		bits       int
+		wantPrefix string
+	}{
+		{keyType: ED25519SSHKey, wantPrefix: "ssh-ed25519 "},
+		{keyType: RSASSHKey, bits: 2048, wantPrefix: "ssh-rsa "},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.keyType), func(t *testing.T) {
+			key := NewSSHKey()
+			key.Name = "deploy"
+			err := key.Generate(tt.keyType, tt.bits)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(key.PublicKey, tt.wantPrefix))
+			assert.True(t, strings.HasPrefix(key.Fingerprint, "SHA256:"))
+			assert.True(t, strings.HasSuffix(key.AuthorizedKey(), " deploy"))
+
+			signer, err := key.Signer()
+			require.NoError(t, err)
+			sig, err := signer.Sign(nil, []byte("data"))
+			require.NoError(t, err)
+			assert.NoError(t, signer.PublicKey().Verify([]byte("data"), sig))
+
+			// the public key must be derived from the stored private key
+			loaded := NewSSHKey()
+			err = loaded.SetPrivateKey(key.PrivateKey)
+			require.NoError(t, err)
+			assert.Equal(t, key.PublicKey, loaded.PublicKey)
+			assert.Equal(t, key.Fingerprint, loaded.Fingerprint)
+		})
+	}
+
+	err := NewSSHKey().Generate("dsa", 0)
+	assert.Error(t, err)
+	err = NewSSHKey().SetPrivateKey("not a key")
+	assert.Error(t, err)
+}
+
+func TestUnmarshalItem(t *testing.T) {
+	key := NewSSHKey()
+	key.Name = "deploy"
+	require.NoError(t, key.Generate(ED25519SSHKey, 0))
+
+	data, err := json.Marshal(key)
+	require.NoError(t, err)
+	item, err := UnmarshalItem(data)
+	require.NoError(t, err)
+	assert.Equal(t, key, item)
+
+	login := NewLogin()
+	login.Name = "login"
+	login.Username = "user"
+	data, err = json.Marshal(login)
+	require.NoError(t, err)
+	item, err = UnmarshalItem(data)
+	require.NoError(t, err)
+	assert.Equal(t, login, item)
+}
@@ -0,0 +1,205 @@
+package paw
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Declare conformity to Item interface
+var _ Item = (*TOTP)(nil)
+
+const (
+	TOTPDefaultDigits    = 6
+	TOTPDefaultPeriod    = 30
+	TOTPDefaultAlgorithm = "SHA1"
+)
+
+// TOTP represents a time-based one-time password as defined in RFC 6238.
+// It can be used as standalone item or attached to a Login.
+type TOTP struct {
+	// Secret is the base32 encoded shared secret
+	Secret    string `json:"secret,omitempty"`
+	Issuer    string `json:"issuer,omitempty"`
+	Account   string `json:"account,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Digits    int    `json:"digits,omitempty"`
+	Period    int    `json:"period,omitempty"`
+
+	*Metadata `json:"metadata,omitempty"`
+	*Note     `json:"note,omitempty"`
+}
+
+func NewTOTP() *TOTP {
+	return &TOTP{
+		Algorithm: TOTPDefaultAlgorithm,
+		Digits:    TOTPDefaultDigits,
+		Period:    TOTPDefaultPeriod,
+		Metadata: &Metadata{
+			Type: TOTPItemType,
+		},
+		Note: &Note{},
+	}
+}
+
+// SetURI sets the TOTP parameters from an otpauth URI as defined in
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+// A plain base32 secret is accepted as well using the default parameters.
+func (t *TOTP) SetURI(uri string) error {
+	uri = strings.TrimSpace(uri)
+	if !strings.HasPrefix(uri, "otpauth://") {
+		return t.set(uri, "", "", TOTPDefaultAlgorithm, TOTPDefaultDigits, TOTPDefaultPeriod)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid otpauth URI: %w", err)
+	}
+	if u.Host != "totp" {
+		return fmt.Errorf("unsupported otpauth type %q", u.Host)
+	}
+
+	q := u.Query()
+	issuer := q.Get("issuer")
+	account := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(account, ":"); i >= 0 {
+		if issuer == "" {
+			issuer = account[:i]
+		}
+		account = strings.TrimSpace(account[i+1:])
+	}
+
+	algorithm := TOTPDefaultAlgorithm
+	if v := q.Get("algorithm"); v != "" {
+		algorithm = strings.ToUpper(v)
+	}
+	digits := TOTPDefaultDigits
+	if v := q.Get("digits"); v != "" {
+		digits, err = strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid otpauth digits %q", v)
+		}
+	}
+	period := TOTPDefaultPeriod
+	if v := q.Get("period"); v != "" {
+		period, err = strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid otpauth period %q", v)
+		}
+	}
+	return t.set(q.Get("secret"), issuer, account, algorithm, digits, period)
+}
+
+func (t *TOTP) set(secret, issuer, account, algorithm string, digits, period int) error {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	if _, err := decodeTOTPSecret(secret); err != nil {
+		return err
+	}
+	if _, err := totpHash(algorithm); err != nil {
+		return err
+	}
+	if digits < 6 || digits > 8 {
+		return fmt.Errorf("invalid TOTP digits %d: must be between 6 and 8", digits)
+	}
+	if period <= 0 {
+		return fmt.Errorf("invalid TOTP period %d", period)
+	}
+	t.Secret = secret
+	t.Issuer = issuer
+	t.Account = account
+	t.Algorithm = algorithm
+	t.Digits = digits
+	t.Period = period
+	return nil
+}
+
+// URI returns the otpauth URI
+func (t *TOTP) URI() string {
+	if t.Secret == "" {
+		return ""
+	}
+	label := t.Account
+	if t.Issuer != "" {
+		label = t.Issuer + ":" + t.Account
+	}
+	q := url.Values{}
+	q.Set("secret", t.Secret)
+	if t.Issuer != "" {
+		q.Set("issuer", t.Issuer)
+	}
+	q.Set("algorithm", t.Algorithm)
+	q.Set("digits", strconv.Itoa(t.Digits))
+	q.Set("period", strconv.Itoa(t.Period))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + label,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+// Code returns the one-time password valid at the time tm
+func (t *TOTP) Code(tm time.Time) (string, error) {
+	key, err := decodeTOTPSecret(t.Secret)
+	if err != nil {
+		return "", err
+	}
+	h, err := totpHash(t.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(tm.Unix())/uint64(t.Period))
+	mac := hmac.New(h, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, see RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < t.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.Digits, code%mod), nil
+}
+
+// Remaining returns the time left before the code valid at tm expires
+func (t *TOTP) Remaining(tm time.Time) time.Duration {
+	period := int64(t.Period) * int64(time.Second)
+	return time.Duration(period - tm.UnixNano()%period)
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("TOTP secret is empty")
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return key, nil
+}
+
+func totpHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported TOTP algorithm %q", algorithm)
+}
@@ -0,0 +1,98 @@
+package paw
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from RFC 6238 Appendix B
+func TestTOTPCode(t *testing.T) {
+	seed := func(n int) string {
+		s := strings.Repeat("1234567890", 7)[:n]
+		return base32.StdEncoding.EncodeToString([]byte(s))
+	}
+	tests := []struct {
+		unix      int64
+		algorithm string
+		secret    string
+		want      string
+	}{
+		{unix: 59, algorithm: "SHA1", secret: seed(20), want: "94287082"},
+		{unix: 59, algorithm: "SHA256", secret: seed(32), want: "46119246"},
+		{unix: 59, algorithm: "SHA512", secret: seed(64), want: "90693936"},
+		{unix: 1111111109, algorithm: "SHA1", secret: seed(20), want: "07081804"},
+		{unix: 1111111109, algorithm: "SHA256", secret: seed(32), want: "68084774"},
+		{unix: 1111111109, algorithm: "SHA512", secret: seed(64), want: "25091201"},
+		{unix: 20000000000, algorithm: "SHA1", secret: seed(20), want: "65353130"},
+		{unix: 20000000000, algorithm: "SHA256", secret: seed(32), want: "77737706"},
+		{unix: 20000000000, algorithm: "SHA512", secret: seed(64), want: "47863826"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm+"/"+tt.want, func(t *testing.T) {
+			totp := NewTOTP()
+			totp.Secret = tt.secret
+			totp.Algorithm = tt.algorithm
+			totp.Digits = 8
+			code, err := totp.Code(time.Unix(tt.unix, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestTOTPSetURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		want    *TOTP
+		wantErr bool
+	}{
+		{
+			name: "full",
+			uri:  "otpauth://totp/ACME%20Co:john@example.com?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&issuer=ACME%20Co&algorithm=SHA256&digits=8&period=60",
+			want: &TOTP{Secret: "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", Issuer: "ACME Co", Account: "john@example.com", Algorithm: "SHA256", Digits: 8, Period: 60},
+		},
+		{
+			name: "defaults",
+			uri:  "otpauth://totp/GitHub:admin?secret=hxdmvjecjjwsrb3hwizr4ifugftmxboz",
+			want: &TOTP{Secret: "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", Issuer: "GitHub", Account: "admin", Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			name: "plain secret",
+			uri:  "hxdm vjec jjws rb3h wizr 4ifu gftm xboz",
+			want: &TOTP{Secret: "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{name: "hotp", uri: "otpauth://hotp/admin?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", wantErr: true},
+		{name: "invalid secret", uri: "otpauth://totp/admin?secret=1", wantErr: true},
+		{name: "invalid algorithm", uri: "otpauth://totp/admin?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&algorithm=MD5", wantErr: true},
+		{name: "invalid digits", uri: "otpauth://totp/admin?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&digits=4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totp := &TOTP{}
+			err := totp.SetURI(tt.uri)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, totp)
+
+			// the URI must round trip
+			got := &TOTP{}
+			require.NoError(t, got.SetURI(totp.URI()))
+			assert.Equal(t, totp, got)
+		})
+	}
+}
+
+func TestTOTPRemaining(t *testing.T) {
+	totp := NewTOTP()
+	assert.Equal(t, 30*time.Second, totp.Remaining(time.Unix(60, 0)))
+	assert.Equal(t, 1*time.Second, totp.Remaining(time.Unix(89, 0)))
+}
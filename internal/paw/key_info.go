@@ -0,0 +1,177 @@
+package paw
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The scrypt work factors, as log2 of the scrypt N parameter, allowed to protect the key
+const (
+	// MinWorkFactor is the lowest work factor allowed, i.e. to be used in CI
+	MinWorkFactor = 10
+	// DefaultWorkFactor is the age default work factor, about one second on a modern machine
+	DefaultWorkFactor = 18
+	// MaxWorkFactor is the highest work factor accepted by age when decrypting
+	MaxWorkFactor = 22
+)
+
+// key file header fields
+const (
+	keyInfoID         = "id"
+	keyInfoPublicKey  = "public key"
+	keyInfoCreated    = "created"
+	keyInfoWorkFactor = "scrypt work factor"
+)
+
+// KeyInfo holds the key metadata. The metadata are stored in clear as header of the key file,
+// so that can be read without unlocking the key.
+type KeyInfo struct {
+	// ID identifies the key, it is derived from the public key
+	ID string
+	// PublicKey is the age recipient of the key
+	PublicKey string
+	// Created is the key creation date, zero if unknown
+	Created time.Time
+	// WorkFactor is the scrypt work factor used to protect the key using the password,
+	// zero if the key is not protected by a password
+	WorkFactor int
+}
+
+// Info returns the key metadata.
+// The creation date and the work factor are known only for the keys loaded from a key file.
+func (k *Key) Info() KeyInfo {
+	if k.info != nil {
+		return *k.info
+	}
+	publicKey := k.ageIdentity.Recipient().String()
+	return KeyInfo{ID: keyID(publicKey), PublicKey: publicKey}
+}
+
+// setCreated records the key creation date into the key metadata.
+// The date is truncated to seconds as it is stored into the header.
+func (k *Key) setCreated(t time.Time) {
+	info := k.Info()
+	info.Created = t.Truncate(time.Second)
+	k.info = &info
+}
+
+// setInfo sets the key metadata read from the key file header.
+// The header is not encrypted, so the ID and the public key are always derived from the key.
+func (k *Key) setInfo(info *KeyInfo) {
+	if info == nil {
+		return
+	}
+	i := *info
+	i.PublicKey = k.ageIdentity.Recipient().String()
+	i.ID = keyID(i.PublicKey)
+	k.info = &i
+}
+
+// keyID returns the key ID derived from the public key
+func keyID(publicKey string) string {
+	h := sha256.Sum256([]byte(publicKey))
+	return strings.ToUpper(hex.EncodeToString(h[:8]))
+}
+
+// validateWorkFactor returns the work factor to use, zero means the default one
+func validateWorkFactor(workFactor int) (int, error) {
+	if workFactor == 0 {
+		return DefaultWorkFactor, nil
+	}
+	if workFactor < MinWorkFactor || workFactor > MaxWorkFactor {
+		return 0, fmt.Errorf("invalid scrypt work factor %d, must be between %d and %d", workFactor, MinWorkFactor, MaxWorkFactor)
+	}
+	return workFactor, nil
+}
+
+// writeKeyHeader writes the key metadata as header of the key file
+func writeKeyHeader(w io.Writer, info KeyInfo) error {
+	header := &strings.Builder{}
+	fmt.Fprintf(header, "# %s: %s\n", keyInfoID, info.ID)
+	fmt.Fprintf(header, "# %s: %s\n", keyInfoPublicKey, info.PublicKey)
+	if !info.Created.IsZero() {
+		fmt.Fprintf(header, "# %s: %s\n", keyInfoCreated, info.Created.Format(time.RFC3339))
+	}
+	if info.WorkFactor > 0 {
+		fmt.Fprintf(header, "# %s: %d\n", keyInfoWorkFactor, info.WorkFactor)
+	}
+	_, err := io.WriteString(w, header.String())
+	return err
+}
+
+// ReadKeyInfo reads the key metadata from the key file header without unlocking the key
+func ReadKeyInfo(r io.Reader) (*KeyInfo, error) {
+	info, _, err := readKeyHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("paw: readkeyinfo error: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("paw: readkeyinfo error: the key file has no metadata header")
+	}
+	return info, nil
+}
+
+// readKeyHeader reads the metadata header, if any, from the key file.
+// It returns the reader to use to read the rest of the file, i.e. the armored key.
+// The key files created before the header was introduced return a nil info.
+func readKeyHeader(r io.Reader) (*KeyInfo, io.Reader, error) {
+	br := bufio.NewReader(r)
+	var info *KeyInfo
+	for {
+		b, err := br.Peek(1)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if b[0] != '#' {
+			break
+		}
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, nil, err
+		}
+		k, v, ok := parseKeyHeaderLine(line)
+		if !ok {
+			continue
+		}
+		if info == nil {
+			info = &KeyInfo{}
+		}
+		switch k {
+		case keyInfoID:
+			info.ID = v
+		case keyInfoPublicKey:
+			info.PublicKey = v
+		case keyInfoCreated:
+			info.Created, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid key creation date: %w", err)
+			}
+		case keyInfoWorkFactor:
+			info.WorkFactor, err = strconv.Atoi(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid key work factor: %w", err)
+			}
+		}
+	}
+	return info, br, nil
+}
+
+// parseKeyHeaderLine parses an header line in the "# key: value" format
+func parseKeyHeaderLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
@@ -0,0 +1,97 @@
+package paw
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeKeyWorkFactor(t *testing.T) {
+	tests := []struct {
+		name       string
+		workFactor int
+		want       int
+		wantErr    bool
+	}{
+		{name: "min", workFactor: MinWorkFactor, want: MinWorkFactor},
+		{name: "custom", workFactor: 12, want: 12},
+		{name: "too low", workFactor: MinWorkFactor - 1, wantErr: true},
+		{name: "too high", workFactor: MaxWorkFactor + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			key, err := MakeKey("secret", tt.workFactor, buf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			info, err := ReadKeyInfo(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info.WorkFactor)
+			assert.Equal(t, key.Recipients()[0], info.PublicKey)
+			assert.Len(t, info.ID, 16)
+			assert.WithinDuration(t, time.Now(), info.Created, time.Minute)
+
+			loaded, err := LoadKey("secret", bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, key.Identity(), loaded.Identity())
+			assert.Equal(t, info.ID, loaded.Info().ID)
+			assert.Equal(t, info.WorkFactor, loaded.Info().WorkFactor)
+			assert.True(t, info.Created.Equal(loaded.Info().Created))
+		})
+	}
+}
+
+func TestWrapKeyKeepsMetadata(t *testing.T) {
+	buf := &bytes.Buffer{}
+	key, err := MakeKey("secret", MinWorkFactor, buf)
+	require.NoError(t, err)
+	created := key.Info().Created
+
+	buf.Reset()
+	require.NoError(t, WrapKey(key, "new secret", MinWorkFactor+1, buf))
+	info, err := ReadKeyInfo(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, MinWorkFactor+1, info.WorkFactor)
+	assert.True(t, created.Equal(info.Created))
+	assert.Equal(t, key.Info().ID, info.ID)
+}
+
+func TestLoadKeyWithoutHeader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	key, err := MakeKey("secret", MinWorkFactor, buf)
+	require.NoError(t, err)
+
+	// key files created before the metadata header was introduced start with the armor
+	i := strings.Index(buf.String(), armor.Header)
+	require.Greater(t, i, 0)
+	legacy := buf.Bytes()[i:]
+
+	_, err = ReadKeyInfo(bytes.NewReader(legacy))
+	assert.Error(t, err)
+
+	loaded, err := LoadKey("secret", bytes.NewReader(legacy))
+	require.NoError(t, err)
+	assert.Equal(t, key.Identity(), loaded.Identity())
+	assert.Equal(t, key.Info().ID, loaded.Info().ID)
+	assert.Zero(t, loaded.Info().WorkFactor)
+}
+
+func TestLoadKeyTamperedHeader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	key, err := MakeKey("secret", MinWorkFactor, buf)
+	require.NoError(t, err)
+
+	tampered := strings.Replace(buf.String(), key.Info().ID, "0000000000000000", 1)
+	loaded, err := LoadKey("secret", strings.NewReader(tampered))
+	require.NoError(t, err)
+	assert.Equal(t, key.Info().ID, loaded.Info().ID)
+}
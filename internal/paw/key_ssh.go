@@ -0,0 +1,143 @@
+package paw
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/ssh"
+
+	"lucor.dev/paw/internal/age/bech32"
+)
+
+// sshAgentChallenge is signed by the ssh-agent to derive the key used to unlock the vault
+const sshAgentChallenge = ID + " ssh-agent unlock "
+
+// WrapKeySSH encrypts the key to w so that can be unlocked using the SSH private key
+// matching one of the public keys, as alternative to the password.
+// The public keys must be in the authorized_keys format, only ssh-ed25519 and ssh-rsa are supported.
+//
+// The private keys held by an ssh-agent cannot be used to decrypt, so the signers,
+// if any, are used to derive an additional recipient from the signature of a
+// challenge. This allows to unlock the key using the agent only, see SSHAgentIdentity.
+func WrapKeySSH(key *Key, w io.Writer, publicKeys []string, signers []ssh.Signer) (err error) {
+	wrapErr := func(err error) error {
+		return fmt.Errorf("paw: wrapkeyssh error: %w", err)
+	}
+
+	var recipients []age.Recipient
+	for _, pk := range publicKeys {
+		if !strings.HasPrefix(pk, "ssh-") {
+			return wrapErr(fmt.Errorf("not an SSH public key: %q", pk))
+		}
+		recipient, err := ParseRecipient(pk)
+		if err != nil {
+			return wrapErr(err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	for _, signer := range signers {
+		identity, err := SSHAgentIdentity(signer)
+		if err != nil {
+			return wrapErr(err)
+		}
+		recipients = append(recipients, identity.Recipient())
+	}
+	if len(recipients) == 0 {
+		return wrapErr(errors.New("at least an SSH public key is required"))
+	}
+
+	info := key.Info()
+	info.WorkFactor = 0
+	if err := writeKeyHeader(w, info); err != nil {
+		return wrapErr(err)
+	}
+
+	a := armor.NewWriter(w)
+	defer func() {
+		if ierr := a.Close(); ierr != nil && err == nil {
+			err = wrapErr(ierr)
+		}
+	}()
+	e, err := age.Encrypt(a, recipients...)
+	if err != nil {
+		return wrapErr(err)
+	}
+	if _, err := e.Write(keyFileContent(key.ageIdentity, info.Created)); err != nil {
+		return wrapErr(err)
+	}
+	if err := e.Close(); err != nil {
+		return wrapErr(err)
+	}
+	return nil
+}
+
+// LoadKeySSH decrypts the key wrapped by WrapKeySSH from the reader r using the SSH identities
+func LoadKeySSH(r io.Reader, identities ...age.Identity) (*Key, error) {
+	wrapErr := func(err error) error {
+		return fmt.Errorf("paw: loadkeyssh error: %w", err)
+	}
+
+	info, r, err := readKeyHeader(r)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	d, err := age.Decrypt(armor.NewReader(r), identities...)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	key, err := readKey(d)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	key.setInfo(info)
+	return key, nil
+}
+
+// ParseSSHIdentity parses an SSH private key in PEM or OpenSSH format.
+// The passphrase func is called only if the key is protected and it is required to decrypt.
+func ParseSSHIdentity(pemBytes []byte, passphrase func() ([]byte, error)) (age.Identity, error) {
+	identity, err := agessh.ParseIdentity(pemBytes)
+	if err == nil {
+		return identity, nil
+	}
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return nil, err
+	}
+	if missing.PublicKey == nil {
+		return nil, fmt.Errorf("the public key is required to use a passphrase protected SSH key")
+	}
+	return agessh.NewEncryptedSSHIdentity(missing.PublicKey, pemBytes, passphrase)
+}
+
+// SSHAgentIdentity returns the age identity derived from the signature of a challenge
+// using the signer, typically a key held by an ssh-agent.
+// Only the ssh-ed25519 and ssh-rsa keys are supported since produce deterministic signatures.
+func SSHAgentIdentity(signer ssh.Signer) (*age.X25519Identity, error) {
+	pk := signer.PublicKey()
+	if t := pk.Type(); t != ssh.KeyAlgoED25519 && t != ssh.KeyAlgoRSA {
+		return nil, fmt.Errorf("unsupported SSH key type %s", t)
+	}
+	signature, err := signer.Sign(nil, []byte(sshAgentChallenge+ssh.FingerprintSHA256(pk)))
+	if err != nil {
+		return nil, fmt.Errorf("could not sign the challenge: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	reader := hkdf.New(sha256.New, signature.Blob, nil, []byte(ID))
+	if _, err := io.ReadFull(reader, secret); err != nil {
+		return nil, err
+	}
+	s, err := bech32.Encode("AGE-SECRET-KEY-", secret)
+	if err != nil {
+		return nil, err
+	}
+	return age.ParseX25519Identity(strings.ToUpper(s))
+}
@@ -0,0 +1,89 @@
+package paw
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestWrapKeySSH(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	agentSigner, err := ssh.NewSignerFromKey(edKey)
+	require.NoError(t, err)
+
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	rsaSigner, err := ssh.NewSignerFromKey(rsaKey)
+	require.NoError(t, err)
+	publicKeys := []string{authorizedKey(agentSigner), authorizedKey(rsaSigner)}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WrapKeySSH(key, buf, publicKeys, []ssh.Signer{agentSigner}))
+	wrapped := buf.Bytes()
+
+	edIdentity, err := agessh.NewEd25519Identity(edKey)
+	require.NoError(t, err)
+	rsaIdentity, err := ParseSSHIdentity(rsaPEM, nil)
+	require.NoError(t, err)
+	agentIdentity, err := SSHAgentIdentity(agentSigner)
+	require.NoError(t, err)
+
+	for name, identity := range map[string]age.Identity{"ed25519": edIdentity, "rsa": rsaIdentity, "agent": agentIdentity} {
+		t.Run(name, func(t *testing.T) {
+			loaded, err := LoadKeySSH(bytes.NewReader(wrapped), identity)
+			require.NoError(t, err)
+			assert.Equal(t, key.Identity(), loaded.Identity())
+		})
+	}
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, err = LoadKeySSH(bytes.NewReader(wrapped), other)
+	assert.Error(t, err)
+
+	assert.Error(t, WrapKeySSH(key, &bytes.Buffer{}, nil, nil), "no recipients")
+	assert.Error(t, WrapKeySSH(key, &bytes.Buffer{}, []string{other.Recipient().String()}, nil), "not an SSH key")
+}
+
+func TestSSHAgentIdentity(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(edKey)
+	require.NoError(t, err)
+
+	// the identity must be derived deterministically
+	i1, err := SSHAgentIdentity(signer)
+	require.NoError(t, err)
+	i2, err := SSHAgentIdentity(signer)
+	require.NoError(t, err)
+	assert.Equal(t, i1.String(), i2.String())
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err = ssh.NewSignerFromKey(ecKey)
+	require.NoError(t, err)
+	_, err = SSHAgentIdentity(signer)
+	assert.Error(t, err, "ecdsa signatures are not deterministic")
+}
+
+func authorizedKey(signer ssh.Signer) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
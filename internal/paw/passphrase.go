@@ -0,0 +1,280 @@
+package paw
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	agepaw "lucor.dev/paw/internal/age"
+)
+
+// Capitalization represents how the passphrase words are capitalized
+type Capitalization string
+
+const (
+	// LowerCapitalization keeps the words as in the wordlist, usually lowercase
+	LowerCapitalization Capitalization = ""
+	// TitleCapitalization uppercases the first letter of each word
+	TitleCapitalization Capitalization = "title"
+	// UpperCapitalization uppercases all the words
+	UpperCapitalization Capitalization = "upper"
+	// RandomCapitalization uppercases the first letter of randomly selected words
+	RandomCapitalization Capitalization = "random"
+)
+
+// Capitalizations returns the supported capitalization styles
+func Capitalizations() []Capitalization {
+	return []Capitalization{LowerCapitalization, TitleCapitalization, UpperCapitalization, RandomCapitalization}
+}
+
+// String returns a human readable name of the capitalization
+func (c Capitalization) String() string {
+	if c == LowerCapitalization {
+		return "lower"
+	}
+	return string(c)
+}
+
+const (
+	// DefaultWordlist is the name of the built-in BIP39 english wordlist
+	DefaultWordlist = "bip39-english"
+	// EFFLargeWordlist is the name of the built-in EFF large wordlist
+	EFFLargeWordlist = "eff-large"
+	// EFFShortWordlist is the name of the built-in EFF short wordlist
+	EFFShortWordlist = "eff-short"
+	// PassphraseDefaultSeparator is the default separator between the passphrase words
+	PassphraseDefaultSeparator = "-"
+	// wordlistMinLength is the min number of words of a wordlist
+	wordlistMinLength = 16
+	// wordlistFileExt is the extension of the wordlist files loaded from a directory
+	wordlistFileExt = ".txt"
+)
+
+var (
+	wordlistsMu sync.RWMutex
+	wordlists   = map[string][]string{
+		DefaultWordlist:  agepaw.Wordlist(),
+		EFFLargeWordlist: effLargeWordlist,
+		EFFShortWordlist: effShortWordlist,
+	}
+)
+
+// RegisterWordlist registers the words with the specified name
+// replacing any wordlist with the same name, except the default one.
+func RegisterWordlist(name string, words []string) error {
+	if name == "" || name == DefaultWordlist {
+		return fmt.Errorf("invalid wordlist name %q", name)
+	}
+	if len(words) < wordlistMinLength {
+		return fmt.Errorf("wordlist %q must have at least %d words, got %d", name, wordlistMinLength, len(words))
+	}
+	wordlistsMu.Lock()
+	defer wordlistsMu.Unlock()
+	wordlists[name] = words
+	return nil
+}
+
+// Wordlists returns the sorted names of the registered wordlists
+func Wordlists() []string {
+	wordlistsMu.RLock()
+	defer wordlistsMu.RUnlock()
+	var names []string
+	for name := range wordlists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Wordlist returns the named wordlist. The empty name refers to the default wordlist.
+func Wordlist(name string) ([]string, error) {
+	if name == "" {
+		name = DefaultWordlist
+	}
+	wordlistsMu.RLock()
+	defer wordlistsMu.RUnlock()
+	words, ok := wordlists[name]
+	if !ok {
+		return nil, fmt.Errorf("wordlist %q not found", name)
+	}
+	return words, nil
+}
+
+// ParseWordlist parses a wordlist with a word per line.
+// Blank lines and lines starting with '#' are ignored. The diceware format used by the EFF
+// wordlists, where each word is preceded by the dice roll, i.e. "11111	abacus", is supported.
+func ParseWordlist(r io.Reader) ([]string, error) {
+	var words []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.Trim(fields[0], "123456") == "" {
+			fields = fields[1:]
+		}
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("invalid wordlist line %d: expected one word, got %q", n, line)
+		}
+		word := fields[0]
+		if !utf8.ValidString(word) {
+			return nil, fmt.Errorf("invalid wordlist line %d: invalid UTF-8", n)
+		}
+		if seen[word] {
+			return nil, fmt.Errorf("invalid wordlist line %d: duplicated word %q", n, word)
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// LoadWordlists registers the wordlists found into dir. Each file with the .txt
+// extension is registered using the file name without the extension,
+// i.e. eff_large_wordlist.txt is registered as eff_large_wordlist.
+// A missing dir is not an error.
+func LoadWordlists(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+wordlistFileExt))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := loadWordlistFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadWordlistFile registers the wordlist stored into the file
+func loadWordlistFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	words, err := ParseWordlist(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return RegisterWordlist(strings.TrimSuffix(filepath.Base(file), wordlistFileExt), words)
+}
+
+// Passphrase holds the options to generate a passphrase
+type Passphrase struct {
+	// Words is the wordlist
+	Words []string
+	// Length is the number of words
+	Length         int
+	Separator      string
+	Capitalization Capitalization
+	// Digit injects a digit into a random word
+	Digit bool
+	// Symbol injects a symbol into a random word
+	Symbol bool
+}
+
+// Generate generates a passphrase reading the randomness from rand
+func (p *Passphrase) Generate(rand io.Reader) (string, error) {
+	if p.Length <= 0 {
+		return "", fmt.Errorf("passphrase length must be greater than zero")
+	}
+	if len(p.Words) == 0 {
+		return "", fmt.Errorf("passphrase wordlist is empty")
+	}
+	words := make([]string, p.Length)
+	for i := range words {
+		n, err := uniform32(rand, len(p.Words))
+		if err != nil {
+			return "", err
+		}
+		word := p.Words[n]
+		switch p.Capitalization {
+		case TitleCapitalization:
+			word = title(word)
+		case UpperCapitalization:
+			word = strings.ToUpper(word)
+		case RandomCapitalization:
+			n, err := uniform(rand, 2)
+			if err != nil {
+				return "", err
+			}
+			if n == 1 {
+				word = title(word)
+			}
+		}
+		words[i] = word
+	}
+
+	inject := func(chars string) error {
+		i, err := uniform(rand, len(words))
+		if err != nil {
+			return err
+		}
+		c, err := pick(rand, []byte(chars))
+		if err != nil {
+			return err
+		}
+		words[i] += string(c)
+		return nil
+	}
+	if p.Digit {
+		if err := inject(digits); err != nil {
+			return "", err
+		}
+	}
+	if p.Symbol {
+		// the symbols that could be confused with the separator are skipped
+		chars := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(p.Separator, r) {
+				return -1
+			}
+			return r
+		}, ShellSafeSymbols)
+		if err := inject(chars); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(words, p.Separator), nil
+}
+
+// title uppercases the first letter of the word
+func title(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// uniform32 returns an uniform random int in [0,n) with n <= 1<<32 using rejection sampling
+func uniform32(rand io.Reader, n int) (int, error) {
+	if n <= 256 {
+		return uniform(rand, n)
+	}
+	if uint64(n) > 1<<32 {
+		return 0, fmt.Errorf("invalid range [0,%d)", n)
+	}
+	max := uint64(1<<32) - uint64(1<<32)%uint64(n)
+	buf := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return 0, err
+		}
+		v := uint64(binary.BigEndian.Uint32(buf))
+		if v < max {
+			return int(v % uint64(n)), nil
+		}
+	}
+}
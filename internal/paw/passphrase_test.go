@@ -0,0 +1,169 @@
+package paw
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWordlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "one per line", data: "alpha\nbeta\n\n# comment\ngamma\n", want: []string{"alpha", "beta", "gamma"}},
+		{name: "diceware", data: "11111\tabacus\n11112\tabdomen\n", want: []string{"abacus", "abdomen"}},
+		{name: "non english", data: "ábaco\nárbol\n", want: []string{"ábaco", "árbol"}},
+		{name: "duplicated", data: "alpha\nalpha\n", wantErr: true},
+		{name: "more words", data: "alpha beta\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWordlist(strings.NewReader(tt.data))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadWordlists(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < wordlistMinLength; i++ {
+		lines = append(lines, "parola"+string(rune('a'+i)))
+	}
+	err := os.WriteFile(filepath.Join(dir, "italian.txt"), []byte(strings.Join(lines, "\n")), 0600)
+	require.NoError(t, err)
+
+	require.NoError(t, LoadWordlists(dir))
+	assert.Contains(t, Wordlists(), "italian")
+	assert.Contains(t, Wordlists(), DefaultWordlist)
+	words, err := Wordlist("italian")
+	require.NoError(t, err)
+	assert.Equal(t, lines, words)
+
+	assert.Error(t, RegisterWordlist(DefaultWordlist, lines))
+	assert.Error(t, RegisterWordlist("short", lines[:2]))
+}
+
+func TestBuiltinWordlists(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: DefaultWordlist, size: 2048},
+		{name: EFFLargeWordlist, size: 7776},
+		{name: EFFShortWordlist, size: 1296},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := Wordlist(tt.name)
+			require.NoError(t, err)
+			assert.Len(t, words, tt.size)
+			seen := map[string]bool{}
+			for _, w := range words {
+				assert.NotEmpty(t, w)
+				assert.False(t, seen[w], "duplicated word %q", w)
+				seen[w] = true
+			}
+		})
+	}
+}
+
+func TestPasswordPassphrase(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		setup func(p *Password)
+		check func(t *testing.T, words []string)
+	}{
+		{
+			name:  "default",
+			setup: func(p *Password) {},
+			check: func(t *testing.T, words []string) {
+				for _, w := range words {
+					assert.Equal(t, strings.ToLower(w), w)
+				}
+			},
+		},
+		{
+			name:  "title",
+			setup: func(p *Password) { p.Capitalization = TitleCapitalization },
+			check: func(t *testing.T, words []string) {
+				for _, w := range words {
+					assert.True(t, unicode.IsUpper(rune(w[0])), w)
+				}
+			},
+		},
+		{
+			name:  "upper",
+			setup: func(p *Password) { p.Capitalization = UpperCapitalization },
+			check: func(t *testing.T, words []string) {
+				for _, w := range words {
+					assert.Equal(t, strings.ToUpper(w), w)
+				}
+			},
+		},
+		{
+			name: "digit and symbol",
+			setup: func(p *Password) {
+				p.InjectDigit = true
+				p.InjectSymbol = true
+			},
+			check: func(t *testing.T, words []string) {
+				s := strings.Join(words, "")
+				assert.True(t, strings.ContainsAny(s, digits), s)
+				assert.True(t, strings.ContainsAny(s, ShellSafeSymbols), s)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPassphrasePassword()
+			tt.setup(p)
+			p.Separator = " "
+			secret, err := key.Secret(p)
+			require.NoError(t, err)
+			words := strings.Split(secret, " ")
+			require.Len(t, words, p.Length)
+			tt.check(t, words)
+		})
+	}
+
+	t.Run("default separator", func(t *testing.T) {
+		p := NewPassphrasePassword()
+		secret, err := key.Secret(p)
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(secret, PassphraseDefaultSeparator), p.Length)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		pp := &Passphrase{Words: []string{"a", "b", "c"}, Length: 4, Separator: ".", Digit: true}
+		seed := strings.Repeat("\x01\x02\x03", 64)
+		s1, err := pp.Generate(strings.NewReader(seed))
+		require.NoError(t, err)
+		s2, err := pp.Generate(strings.NewReader(seed))
+		require.NoError(t, err)
+		assert.Equal(t, s1, s2)
+	})
+
+	t.Run("unknown wordlist", func(t *testing.T) {
+		p := NewPassphrasePassword()
+		p.Wordlist = "unknown"
+		_, err := key.Secret(p)
+		assert.Error(t, err)
+	})
+}
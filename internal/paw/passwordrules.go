@@ -0,0 +1,340 @@
+package paw
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// appleSpecial are the chars of the special class as defined by the passwordrules language
+const appleSpecial = "-~!@#$%^&*_+=`|(){}[:;\"'<>,.?]"
+
+// passwordRulesClasses are the named classes of the passwordrules language
+// in the order used by the serializer
+var passwordRulesClasses = []struct {
+	name   string
+	format Format
+	chars  string
+}{
+	{name: "upper", format: UppercaseFormat, chars: uppercase},
+	{name: "lower", format: LowercaseFormat, chars: lowercase},
+	{name: "digit", format: DigitsFormat, chars: digits},
+	{name: "special", chars: appleSpecial},
+}
+
+// passwordRulesClass is a char class parsed from a required or allowed property
+type passwordRulesClass struct {
+	// name is the class name, empty for custom classes
+	name  string
+	chars []byte
+}
+
+// ParsePasswordRules parses a rule written in the passwordrules language,
+// see https://developer.apple.com/password-rules/.
+// Unknown properties and classes are ignored as required by the specification.
+// Only ASCII printable chars, except the space, are supported.
+func ParsePasswordRules(s string) (*Rule, error) {
+	rule := &Rule{}
+	allowed := map[byte]bool{}
+	var required [][]passwordRulesClass
+
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ';' || isSpace(s[i])) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		j := strings.IndexByte(s[i:], ':')
+		if j == -1 {
+			return nil, fmt.Errorf("invalid password rules: missing ':' after %q", s[i:])
+		}
+		name := strings.ToLower(strings.TrimSpace(s[i : i+j]))
+		i += j + 1
+
+		switch name {
+		case "required", "allowed":
+			var classes []passwordRulesClass
+			var err error
+			classes, i, err = parsePasswordRulesClasses(s, i)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range classes {
+				for _, b := range c.chars {
+					allowed[b] = true
+				}
+			}
+			if name == "required" && len(classes) > 0 {
+				required = append(required, classes)
+			}
+		default:
+			end := strings.IndexByte(s[i:], ';')
+			if end == -1 {
+				end = len(s) - i
+			}
+			value := strings.TrimSpace(s[i : i+end])
+			i += end
+
+			var n *int
+			switch name {
+			case "minlength":
+				n = &rule.MinLength
+			case "maxlength":
+				n = &rule.MaxLength
+			case "max-consecutive":
+				n = &rule.MaxConsecutive
+			default:
+				continue
+			}
+			v, err := strconv.Atoi(value)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("invalid password rules: invalid %s value %q", name, value)
+			}
+			*n = v
+		}
+	}
+
+	if rule.MaxLength > 0 && rule.MinLength > rule.MaxLength {
+		return nil, fmt.Errorf("invalid password rules: minlength %d is greater than maxlength %d", rule.MinLength, rule.MaxLength)
+	}
+	rule.Length = rule.ClampLength(RandomPasswordDefaultLength)
+
+	// the ascii printable chars are allowed when no class is specified
+	all := lowercase + uppercase + digits + symbols
+	for _, b := range []byte(all) {
+		if len(allowed) == 0 || allowed[b] {
+			rule.Tpl = append(rule.Tpl, b)
+		}
+	}
+
+	for _, classes := range required {
+		if len(classes) == 1 {
+			if format := namedClassFormat(classes[0].name); format != 0 {
+				rule.WithMin(format, rule.Min[format]+1)
+				continue
+			}
+		}
+		set := map[byte]bool{}
+		for _, c := range classes {
+			for _, b := range c.chars {
+				set[b] = true
+			}
+		}
+		// keep the template order so that the rule does not depend on the classes order
+		var chars []byte
+		for _, b := range []byte(all) {
+			if set[b] {
+				chars = append(chars, b)
+			}
+		}
+		rule.Required = append(rule.Required, string(chars))
+	}
+	return rule, nil
+}
+
+// parsePasswordRulesClasses parses the comma separated classes starting at i
+// until the end of the property. It returns the position after the classes.
+func parsePasswordRulesClasses(s string, i int) ([]passwordRulesClass, int, error) {
+	var classes []passwordRulesClass
+	for i < len(s) {
+		switch {
+		case s[i] == ';':
+			return classes, i, nil
+		case s[i] == ',' || isSpace(s[i]):
+			i++
+		case s[i] == '[':
+			// the closing bracket is the one followed by a delimiter,
+			// this allows to specify ']' as last char of the class
+			k := i + 1
+			for {
+				e := strings.IndexByte(s[k:], ']')
+				if e == -1 {
+					return nil, i, fmt.Errorf("invalid password rules: unterminated custom class %q", s[i:])
+				}
+				e += k
+				n := e + 1
+				for n < len(s) && isSpace(s[n]) {
+					n++
+				}
+				if n >= len(s) || s[n] == ',' || s[n] == ';' {
+					var chars []byte
+					for _, b := range []byte(s[i+1 : e]) {
+						if isPasswordRulesChar(b) {
+							chars = appendUnique(chars, b)
+						}
+					}
+					classes = append(classes, passwordRulesClass{chars: chars})
+					i = e + 1
+					break
+				}
+				k = e + 1
+			}
+		default:
+			end := i
+			for end < len(s) && s[end] != ',' && s[end] != ';' && !isSpace(s[end]) {
+				end++
+			}
+			name := strings.ToLower(s[i:end])
+			i = end
+			switch name {
+			case "ascii-printable", "unicode":
+				// only the ASCII printable chars are supported
+				for _, c := range passwordRulesClasses[:3] {
+					classes = append(classes, passwordRulesClass{name: c.name, chars: []byte(c.chars)})
+				}
+				classes = append(classes, passwordRulesClass{chars: []byte(symbols)})
+				continue
+			}
+			for _, c := range passwordRulesClasses {
+				if c.name == name {
+					classes = append(classes, passwordRulesClass{name: c.name, chars: []byte(c.chars)})
+				}
+			}
+		}
+	}
+	return classes, i, nil
+}
+
+// PasswordRules returns the rule serialized in the passwordrules language
+func (r *Rule) PasswordRules() (string, error) {
+	tpl, err := r.Template()
+	if err != nil {
+		return "", err
+	}
+
+	var props []string
+	if r.MinLength > 0 {
+		props = append(props, fmt.Sprintf("minlength: %d", r.MinLength))
+	}
+	if r.MaxLength > 0 {
+		props = append(props, fmt.Sprintf("maxlength: %d", r.MaxLength))
+	}
+
+	covered := map[byte]bool{}
+	addRequired := func(chars []byte) {
+		if len(chars) == 0 {
+			return
+		}
+		for _, b := range chars {
+			covered[b] = true
+		}
+		props = append(props, "required: "+passwordRulesClassesString(chars))
+	}
+	for _, c := range passwordRulesClasses {
+		if c.format == 0 {
+			continue
+		}
+		for i := 0; i < r.Min[c.format]; i++ {
+			addRequired(r.classChars(c.format))
+		}
+	}
+	if n := r.Min[SymbolsFormat]; n > 0 {
+		for i := 0; i < n; i++ {
+			addRequired(r.classChars(SymbolsFormat))
+		}
+	}
+	for _, req := range r.Required {
+		addRequired(r.templateChars([]byte(req)))
+	}
+
+	var allowed []byte
+	for _, b := range []byte(tpl) {
+		if !covered[b] {
+			allowed = append(allowed, b)
+		}
+	}
+	if len(allowed) > 0 {
+		props = append(props, "allowed: "+passwordRulesClassesString(allowed))
+	}
+
+	if r.MaxConsecutive > 0 {
+		props = append(props, fmt.Sprintf("max-consecutive: %d", r.MaxConsecutive))
+	}
+	return strings.Join(props, "; "), nil
+}
+
+// ClampLength returns the length clamped between the rule min and max lengths, if defined
+func (r *Rule) ClampLength(length int) int {
+	if r.MinLength > 0 && length < r.MinLength {
+		length = r.MinLength
+	}
+	if r.MaxLength > 0 && length > r.MaxLength {
+		length = r.MaxLength
+	}
+	return length
+}
+
+// passwordRulesClassesString returns the chars as a list of named and custom classes
+func passwordRulesClassesString(chars []byte) string {
+	set := map[byte]bool{}
+	for _, b := range chars {
+		set[b] = true
+	}
+
+	var names []string
+	for _, c := range passwordRulesClasses {
+		found := true
+		for _, b := range []byte(c.chars) {
+			if !set[b] {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		names = append(names, c.name)
+		for _, b := range []byte(c.chars) {
+			delete(set, b)
+		}
+	}
+
+	// '-' must be the first char of a custom class, ']' the last one
+	var custom []byte
+	if set['-'] {
+		custom = append(custom, '-')
+	}
+	for _, b := range chars {
+		if set[b] && b != '-' && b != ']' {
+			custom = append(custom, b)
+		}
+	}
+	if set[']'] {
+		custom = append(custom, ']')
+	}
+	if len(custom) > 0 {
+		names = append(names, "["+string(custom)+"]")
+	}
+	return strings.Join(names, ", ")
+}
+
+// namedClassFormat returns the format of the named class, zero if the class has no format
+func namedClassFormat(name string) Format {
+	for _, c := range passwordRulesClasses {
+		if c.name == name {
+			return c.format
+		}
+	}
+	return 0
+}
+
+// isPasswordRulesChar reports whether the char can be used into a generated password
+func isPasswordRulesChar(b byte) bool {
+	return b > ' ' && b <= '~'
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func appendUnique(dst []byte, chars ...byte) []byte {
+	for _, b := range chars {
+		if bytes.IndexByte(dst, b) == -1 {
+			dst = append(dst, b)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,127 @@
+package paw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePasswordRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		want    *Rule
+		wantErr bool
+	}{
+		{
+			name:  "required classes",
+			rules: "required: upper; required: digit; allowed: [-_]; minlength: 12; max-consecutive: 2",
+			want: &Rule{
+				Length:         16,
+				Tpl:            []byte(uppercase + digits + "-_"),
+				Min:            map[Format]int{UppercaseFormat: 1, DigitsFormat: 1},
+				MaxConsecutive: 2,
+				MinLength:      12,
+			},
+		},
+		{
+			name:  "union and custom required",
+			rules: "minlength: 8; maxlength: 10; required: lower, upper; required: [-]]; allowed: digit",
+			want: &Rule{
+				Length:    10,
+				Tpl:       []byte(lowercase + uppercase + digits + "-]"),
+				Required:  []string{lowercase + uppercase, "-]"},
+				MinLength: 8,
+				MaxLength: 10,
+			},
+		},
+		{
+			name:  "special",
+			rules: "required: special",
+			want: &Rule{
+				Length:   16,
+				Tpl:      []byte("!\"#$%&'()*+,-.:;<=>?@[]^_`{|}~"),
+				Required: []string{"!\"#$%&'()*+,-.:;<=>?@[]^_`{|}~"},
+			},
+		},
+		{
+			name:  "no classes",
+			rules: "minlength: 20; unknown: value",
+			want: &Rule{
+				Length:    20,
+				Tpl:       []byte(lowercase + uppercase + digits + symbols),
+				MinLength: 20,
+			},
+		},
+		{
+			name:  "custom class with separators",
+			rules: "allowed: lower, [;,]",
+			want: &Rule{
+				Length: 16,
+				Tpl:    []byte(lowercase + ",;"),
+			},
+		},
+		{name: "missing colon", rules: "required upper", wantErr: true},
+		{name: "invalid length", rules: "minlength: twelve", wantErr: true},
+		{name: "min greater than max", rules: "minlength: 12; maxlength: 8", wantErr: true},
+		{name: "unterminated class", rules: "allowed: [abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePasswordRules(tt.rules)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// the serialized rule must parse to the same rule
+			s, err := got.PasswordRules()
+			require.NoError(t, err)
+			again, err := ParsePasswordRules(s)
+			require.NoError(t, err)
+			assert.Equal(t, got, again, s)
+		})
+	}
+}
+
+func TestRulePasswordRules(t *testing.T) {
+	rule, err := NewRule(16, LowercaseFormat|DigitsFormat|SymbolsFormat)
+	require.NoError(t, err)
+	rule.WithMin(DigitsFormat, 2)
+	rule.WithMaxConsecutive(3)
+	require.NoError(t, rule.WithSymbols([]byte("-_/")))
+
+	s, err := rule.PasswordRules()
+	require.NoError(t, err)
+	assert.Equal(t, "required: digit; required: digit; allowed: lower, [-/_]; max-consecutive: 3", s)
+}
+
+func TestPasswordWithRules(t *testing.T) {
+	key, err := MakeOneTimeKey()
+	require.NoError(t, err)
+
+	password := NewRandomPassword()
+	password.Length = 40
+	password.Rules = "required: upper; required: digit; required: [-_]; allowed: lower; maxlength: 24; max-consecutive: 1"
+	assert.Equal(t, 24, password.Len())
+
+	for i := 0; i < 20; i++ {
+		secret, err := key.Secret(password)
+		require.NoError(t, err)
+		assert.Len(t, secret, 24)
+		assert.True(t, strings.ContainsAny(secret, uppercase), secret)
+		assert.True(t, strings.ContainsAny(secret, digits), secret)
+		assert.True(t, strings.ContainsAny(secret, "-_"), secret)
+		for j := 1; j < len(secret); j++ {
+			assert.NotEqual(t, secret[j-1], secret[j], secret)
+		}
+	}
+
+	password.Rules = "minlength: 12; maxlength: 8"
+	_, err = key.Secret(password)
+	assert.Error(t, err)
+}
@@ -0,0 +1,236 @@
+package paw
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PatternMaxLength is the max length of the passwords generated from a pattern
+	PatternMaxLength = 256
+	// PatternPasswordDefaultPattern is the default pattern
+	PatternPasswordDefaultPattern = "Cvcc-9999-Cvcc"
+)
+
+const (
+	lowerConsonants = "bcdfghjklmnpqrstvwxz"
+	upperConsonants = "BCDFGHJKLMNPQRSTVWXZ"
+	lowerVowels     = "aeiouy"
+	upperVowels     = "AEIOUY"
+	lowerHex        = "0123456789abcdef"
+	upperHex        = "0123456789ABCDEF"
+)
+
+// patternPlaceholders maps the pattern placeholders to their char class
+var patternPlaceholders = map[byte]string{
+	'a': lowercase,
+	'A': uppercase,
+	'c': lowerConsonants,
+	'C': upperConsonants,
+	'v': lowerVowels,
+	'V': upperVowels,
+	'9': digits,
+	'h': lowerHex,
+	'H': upperHex,
+	'x': lowercase + uppercase + digits,
+	's': symbols,
+	'*': lowercase + uppercase + digits + symbols,
+}
+
+// PatternPlaceholders returns the description of the pattern placeholders
+func PatternPlaceholders() [][2]string {
+	return [][2]string{
+		{"a / A", "lowercase / uppercase letter"},
+		{"c / C", "lowercase / uppercase consonant"},
+		{"v / V", "lowercase / uppercase vowel"},
+		{"9", "digit"},
+		{"h / H", "lowercase / uppercase hex digit"},
+		{"x", "letter or digit"},
+		{"s", "symbol"},
+		{"*", "any printable char"},
+		{"[A-Z0-9]", "custom class"},
+		{"{n} / {n,m}", "repeat the previous element"},
+		{`\`, "escape the next char"},
+	}
+}
+
+// patternElem is an element of a pattern: a literal or a char class repeated
+type patternElem struct {
+	// chars holds the char class, a single char for literals
+	chars []byte
+	min   int
+	max   int
+}
+
+// Pattern is a parsed password pattern, i.e. Cvcc-9999-Cvcc or [A-Z]{4}-[0-9]{4}.
+// Placeholders are replaced by a char of their class, the other chars are literals.
+type Pattern struct {
+	elems []patternElem
+}
+
+// ParsePattern parses the password pattern.
+// Only the ASCII printable chars are supported.
+func ParsePattern(s string) (*Pattern, error) {
+	if s == "" {
+		return nil, fmt.Errorf("invalid pattern: empty pattern")
+	}
+	p := &Pattern{}
+	for i := 0; i < len(s); {
+		b := s[i]
+		if !isPasswordRulesChar(b) && b != ' ' {
+			return nil, fmt.Errorf("invalid pattern: unsupported char %q at %d", b, i)
+		}
+		switch {
+		case b == '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("invalid pattern: trailing escape")
+			}
+			p.elems = append(p.elems, patternElem{chars: []byte{s[i+1]}, min: 1, max: 1})
+			i += 2
+		case b == '[':
+			chars, n, err := parsePatternClass(s, i)
+			if err != nil {
+				return nil, err
+			}
+			p.elems = append(p.elems, patternElem{chars: chars, min: 1, max: 1})
+			i = n
+		case b == '{':
+			if len(p.elems) == 0 {
+				return nil, fmt.Errorf("invalid pattern: repetition without element at %d", i)
+			}
+			end := strings.IndexByte(s[i:], '}')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid pattern: unterminated repetition at %d", i)
+			}
+			min, max, err := parsePatternRepetition(s[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			last := &p.elems[len(p.elems)-1]
+			if last.min != 1 || last.max != 1 {
+				return nil, fmt.Errorf("invalid pattern: repetition already specified at %d", i)
+			}
+			last.min, last.max = min, max
+			i += end + 1
+		default:
+			chars, ok := patternPlaceholders[b]
+			if !ok {
+				chars = string(b)
+			}
+			p.elems = append(p.elems, patternElem{chars: []byte(chars), min: 1, max: 1})
+			i++
+		}
+	}
+	if p.MaxLen() > PatternMaxLength {
+		return nil, fmt.Errorf("invalid pattern: the generated passwords can have %d chars max", PatternMaxLength)
+	}
+	return p, nil
+}
+
+// parsePatternClass parses the custom class starting at i, ranges like A-Z are supported.
+// It returns the class chars and the position after the class.
+func parsePatternClass(s string, i int) ([]byte, int, error) {
+	var chars []byte
+	j := i + 1
+	for ; j < len(s) && s[j] != ']'; j++ {
+		b := s[j]
+		if b == '\\' {
+			if j+1 >= len(s) {
+				break
+			}
+			j++
+			chars = appendUnique(chars, s[j])
+			continue
+		}
+		if j+2 < len(s) && s[j+1] == '-' && s[j+2] != ']' {
+			from, to := b, s[j+2]
+			if from > to {
+				return nil, i, fmt.Errorf("invalid pattern: invalid range %c-%c", from, to)
+			}
+			for c := from; c <= to; c++ {
+				if isPasswordRulesChar(c) {
+					chars = appendUnique(chars, c)
+				}
+			}
+			j += 2
+			continue
+		}
+		chars = appendUnique(chars, b)
+	}
+	if j >= len(s) {
+		return nil, i, fmt.Errorf("invalid pattern: unterminated class at %d", i)
+	}
+	if len(chars) == 0 {
+		return nil, i, fmt.Errorf("invalid pattern: empty class at %d", i)
+	}
+	return chars, j + 1, nil
+}
+
+// parsePatternRepetition parses the n or n,m repetition
+func parsePatternRepetition(s string) (int, int, error) {
+	minStr, maxStr, found := s, "", false
+	if i := strings.IndexByte(s, ','); i != -1 {
+		minStr, maxStr, found = s[:i], s[i+1:], true
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(minStr))
+	if err != nil || min < 0 {
+		return 0, 0, fmt.Errorf("invalid pattern: invalid repetition {%s}", s)
+	}
+	if !found {
+		return min, min, nil
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(maxStr))
+	if err != nil || max < min {
+		return 0, 0, fmt.Errorf("invalid pattern: invalid repetition {%s}", s)
+	}
+	return min, max, nil
+}
+
+// MaxLen returns the max length of the generated passwords
+func (p *Pattern) MaxLen() int {
+	n := 0
+	for _, e := range p.elems {
+		n += e.max
+	}
+	return n
+}
+
+// Entropy returns the entropy in bits of the generated passwords.
+// Variable repetitions are counted with their min value.
+func (p *Pattern) Entropy() float64 {
+	bits := 0.0
+	for _, e := range p.elems {
+		bits += float64(e.min) * math.Log2(float64(len(e.chars)))
+	}
+	return bits
+}
+
+// Generate generates a password reading the randomness from rand
+func (p *Pattern) Generate(rand io.Reader) (string, error) {
+	var sb strings.Builder
+	for _, e := range p.elems {
+		n := e.min
+		if e.max > e.min {
+			extra, err := uniform32(rand, e.max-e.min+1)
+			if err != nil {
+				return "", err
+			}
+			n += extra
+		}
+		for i := 0; i < n; i++ {
+			if len(e.chars) == 1 {
+				sb.WriteByte(e.chars[0])
+				continue
+			}
+			b, err := pick(rand, e.chars)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteByte(b)
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,63 @@
+package paw
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		// match is the regexp the generated passwords must match
+		match   string
+		maxLen  int
+		entropy float64
+		wantErr bool
+	}{
+		{pattern: "Cvcc-9999-Cvcc", match: `^[B-DF-HJ-NP-TV-XZ][aeiouy][b-df-hj-np-tv-xz]{2}-[0-9]{4}-[B-DF-HJ-NP-TV-XZ][aeiouy][b-df-hj-np-tv-xz]{2}$`, maxLen: 14},
+		{pattern: "[A-Z]{4}-[0-9]{4}", match: `^[A-Z]{4}-[0-9]{4}$`, maxLen: 9},
+		{pattern: "HH:HH:HH", match: `^[0-9A-F]{2}:[0-9A-F]{2}:[0-9A-F]{2}$`, maxLen: 8, entropy: 24},
+		{pattern: "key-9{2,4}", match: `^key-[0-9]{2,4}$`, maxLen: 8},
+		{pattern: `\9[\]\-]`, match: `^9[\]-]$`, maxLen: 2, entropy: 1},
+		{pattern: "[-_]x{0}", match: `^[-_]$`, maxLen: 1, entropy: 1},
+		{pattern: "", wantErr: true},
+		{pattern: "{4}", wantErr: true},
+		{pattern: "a{4", wantErr: true},
+		{pattern: "a{4}{2}", wantErr: true},
+		{pattern: "a{4,2}", wantErr: true},
+		{pattern: "[a-z", wantErr: true},
+		{pattern: "[z-a]", wantErr: true},
+		{pattern: "[]", wantErr: true},
+		{pattern: `a\`, wantErr: true},
+		{pattern: "a{300}", wantErr: true},
+		{pattern: "è", wantErr: true},
+	}
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p, err := ParsePattern(tt.pattern)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.maxLen, p.MaxLen())
+			if tt.entropy > 0 {
+				assert.InDelta(t, tt.entropy, p.Entropy(), 0.001)
+			}
+
+			password := NewPatternPassword()
+			password.Pattern = tt.pattern
+			re := regexp.MustCompile(tt.match)
+			for i := 0; i < 20; i++ {
+				secret, err := key.Secret(password)
+				require.NoError(t, err)
+				assert.Regexp(t, re, secret)
+			}
+		})
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"filippo.io/age/armor"
 	"golang.org/x/crypto/hkdf"
 
-	agepaw "lucor.dev/paw/internal/age"
 	"lucor.dev/paw/internal/age/bech32"
 )
 
@@ -35,12 +34,80 @@ type Seeder interface {
 	Info() []byte
 }
 
+// RuleMaker is implemented by the seeders that define a rule with constraints,
+// like the min count of chars for each class
+type RuleMaker interface {
+	Rule() (*Rule, error)
+}
+
+// PatternMaker is implemented by the seeders that can define a pattern.
+// PasswordPattern returns nil if the seeder is not generated from a pattern
+type PatternMaker interface {
+	PasswordPattern() (*Pattern, error)
+}
+
+// PronounceableMaker is implemented by the seeders that can define a pronounceable password.
+// Pronounceable returns nil if the seeder is not pronounceable
+type PronounceableMaker interface {
+	Pronounceable() *Pronounceable
+}
+
+// PassphraseMaker is implemented by the seeders that can define a passphrase.
+// Passphrase returns nil if the seeder is not a passphrase
+type PassphraseMaker interface {
+	Passphrase() (*Passphrase, error)
+}
+
 type SecretMaker interface {
 	Secret(seeder Seeder) (string, error)
 }
 
+// StatelessSeeder is implemented by the seeders that can derive stateless passwords.
+// Stateless passwords are not stored and must be derived again from the same key and salt
+type StatelessSeeder interface {
+	Seeder
+	Stateless() bool
+}
+
 type Key struct {
 	ageIdentity *age.X25519Identity
+	// members are the additional recipients the messages are encrypted to
+	members []string
+	// info holds the metadata of the keys loaded from or written to a key file
+	info *KeyInfo
+	// oneTime reports whether the key is not persisted
+	oneTime bool
+}
+
+// GenerateKey generates an age secret key that is meant to be persisted, i.e. into the vault.
+// Use MakeKey to store the key protected by a password.
+func GenerateKey() (*Key, error) {
+	ageIdentity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, fmt.Errorf("paw: generatekey error: %w", err)
+	}
+	return &Key{ageIdentity: ageIdentity}, nil
+}
+
+// ParseKey parses the age secret key identity
+func ParseKey(identity string) (*Key, error) {
+	ageIdentity, err := age.ParseX25519Identity(strings.TrimSpace(identity))
+	if err != nil {
+		return nil, fmt.Errorf("paw: parsekey error: %w", err)
+	}
+	return &Key{ageIdentity: ageIdentity}, nil
+}
+
+// Identity returns the age secret key identity.
+// It must be handled with care since allows to derive all the passwords
+func (k *Key) Identity() string {
+	return k.ageIdentity.String()
+}
+
+// IsOneTime reports whether the key has been generated by MakeOneTimeKey.
+// One time keys cannot be used to derive stateless passwords.
+func (k *Key) IsOneTime() bool {
+	return k.oneTime
 }
 
 // MakeOneTimeKey generates a one time age secret key.
@@ -58,64 +125,71 @@ func MakeOneTimeKey() (key *Key, err error) {
 	}
 	key = &Key{
 		ageIdentity: ageIdentity,
+		oneTime:     true,
 	}
 	return
 }
 
-// MakeKey generates an age secret key. The key is encrypted to w and protect using the provided password
-func MakeKey(password string, w io.Writer) (key *Key, err error) {
+// MakeKey generates an age secret key. The key is encrypted to w and protect using the provided password.
+// The workFactor is the scrypt work factor, zero means DefaultWorkFactor.
+func MakeKey(password string, workFactor int, w io.Writer) (*Key, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("paw: makekey error: %w", err)
+	}
+	key.setCreated(time.Now())
+	if err := WrapKey(key, password, workFactor, w); err != nil {
+		return nil, fmt.Errorf("paw: makekey error: %w", err)
+	}
+	return key, nil
+}
 
+// WrapKey encrypts the key to w protecting it using the provided password.
+// It is used to change the password or the work factor that protect an existing key.
+// The workFactor is the scrypt work factor, zero means DefaultWorkFactor.
+// The key metadata are written in clear as header, see ReadKeyInfo.
+func WrapKey(key *Key, password string, workFactor int, w io.Writer) (err error) {
 	wrapErr := func(err error) error {
-		return fmt.Errorf("paw: makekey error: %w", err)
+		return fmt.Errorf("paw: wrapkey error: %w", err)
 	}
 
-	// Generate the age X25519 Identity
-	ageIdentity, ierr := age.GenerateX25519Identity()
-	if ierr != nil {
-		err = wrapErr(ierr)
-		return
+	workFactor, err = validateWorkFactor(workFactor)
+	if err != nil {
+		return wrapErr(err)
+	}
+	ageScryptRecipient, err := age.NewScryptRecipient(password)
+	if err != nil {
+		return wrapErr(err)
 	}
+	ageScryptRecipient.SetWorkFactor(workFactor)
 
-	ageScryptRecipient, ierr := age.NewScryptRecipient(password)
-	if ierr != nil {
-		err = wrapErr(ierr)
-		return
+	info := key.Info()
+	info.WorkFactor = workFactor
+	if err := writeKeyHeader(w, info); err != nil {
+		return wrapErr(err)
 	}
 
 	a := armor.NewWriter(w)
 	defer func() {
 		// make sure to handle the error, if any
-		if ierr := a.Close(); ierr != nil {
+		if ierr := a.Close(); ierr != nil && err == nil {
 			err = wrapErr(ierr)
-			return
+		}
+		if err == nil {
+			key.info = &info
 		}
 	}()
 	e, err := age.Encrypt(a, ageScryptRecipient)
 	if err != nil {
-		err = wrapErr(ierr)
-		return
+		return wrapErr(err)
 	}
-
-	data := &bytes.Buffer{}
-	fmt.Fprintf(data, "# created: %s\n", time.Now().Format(time.RFC3339))
-	fmt.Fprintf(data, "# public key: %s\n", ageIdentity.Recipient())
-	fmt.Fprintf(data, "%s\n", ageIdentity)
-
-	_, err = e.Write(data.Bytes())
-	if err != nil {
-		err = wrapErr(ierr)
-		return
+	if _, err := e.Write(keyFileContent(key.ageIdentity, info.Created)); err != nil {
+		return wrapErr(err)
 	}
-	err = e.Close()
-	if err != nil {
-		err = wrapErr(ierr)
-		return
+	if err := e.Close(); err != nil {
+		return wrapErr(err)
 	}
-
-	key = &Key{
-		ageIdentity: ageIdentity,
-	}
-	return
+	return nil
 }
 
 // LoadKey decrypts an age secret key from the reader r using the provided password
@@ -131,6 +205,12 @@ func LoadKey(password string, r io.Reader) (key *Key, err error) {
 		return
 	}
 
+	info, r, ierr := readKeyHeader(r)
+	if ierr != nil {
+		err = wrapErr(ierr)
+		return
+	}
+
 	a := armor.NewReader(r)
 	d, ierr := age.Decrypt(a, ageScryptIdentity)
 	if ierr != nil {
@@ -138,36 +218,50 @@ func LoadKey(password string, r io.Reader) (key *Key, err error) {
 		return
 	}
 
-	// Generate the age X25519 Identity
-	ageIdentities, ierr := age.ParseIdentities(d)
+	key, ierr = readKey(d)
 	if ierr != nil {
 		err = wrapErr(ierr)
 		return
 	}
+	key.setInfo(info)
+	return
+}
+
+// keyFileContent returns the content of the key file for the age X25519 identity
+func keyFileContent(ageIdentity *age.X25519Identity, created time.Time) []byte {
+	if created.IsZero() {
+		created = time.Now()
+	}
+	data := &bytes.Buffer{}
+	fmt.Fprintf(data, "# created: %s\n", created.Format(time.RFC3339))
+	fmt.Fprintf(data, "# public key: %s\n", ageIdentity.Recipient())
+	fmt.Fprintf(data, "%s\n", ageIdentity)
+	return data.Bytes()
+}
+
+// readKey reads the age X25519 identity from the decrypted key file
+func readKey(r io.Reader) (*Key, error) {
+	ageIdentities, err := age.ParseIdentities(r)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(ageIdentities) > 1 {
-		err = wrapErr(fmt.Errorf("only one identity per file is supported, found %d", len(ageIdentities)))
-		return
+		return nil, fmt.Errorf("only one identity per file is supported, found %d", len(ageIdentities))
 	}
 
 	ageIdentity, ok := ageIdentities[0].(*age.X25519Identity)
 	if !ok {
-		err = wrapErr(fmt.Errorf("only *age.X25519Identity are supported, got %T", ageIdentities[0]))
-		return
+		return nil, fmt.Errorf("only *age.X25519Identity are supported, got %T", ageIdentities[0])
 	}
 
-	key = &Key{
-		ageIdentity: ageIdentity,
-	}
-	return
+	return &Key{ageIdentity: ageIdentity}, nil
 }
 
+// Passphrase derives a passphrase of numWords words using the default options
 func (k *Key) Passphrase(numWords int) (string, error) {
-	var words []string
-	for i := 0; i < numWords; i++ {
-		words = append(words, agepaw.RandomWord())
-	}
-	return strings.Join(words, "-"), nil
+	p := &Password{Mode: PassphrasePassword, Length: numWords}
+	return k.Secret(p)
 }
 
 // Secret derives a secret from the seeder
@@ -176,6 +270,14 @@ func (k *Key) Secret(seeder Seeder) (string, error) {
 	// Underlying hash function for HMAC.
 	hash := sha256.New
 	salt := seeder.Salt()
+	if s, ok := seeder.(StatelessSeeder); ok && s.Stateless() {
+		if k.oneTime {
+			return "", fmt.Errorf("stateless passwords require a persistent key")
+		}
+		if salt == nil {
+			return "", fmt.Errorf("stateless passwords require the item name")
+		}
+	}
 	if salt == nil {
 		salt = make([]byte, hash().Size())
 		if _, err := rand.Read(salt); err != nil {
@@ -191,12 +293,54 @@ func (k *Key) Secret(seeder Seeder) (string, error) {
 
 	// reader to derive a key
 	reader := hkdf.New(sha256.New, data, salt, seeder.Info())
+
+	if pm, ok := seeder.(PatternMaker); ok {
+		pattern, err := pm.PasswordPattern()
+		if err != nil {
+			return "", err
+		}
+		if pattern != nil {
+			return pattern.Generate(reader)
+		}
+	}
+
+	if pm, ok := seeder.(PronounceableMaker); ok {
+		if pronounceable := pm.Pronounceable(); pronounceable != nil {
+			return pronounceable.Generate(reader)
+		}
+	}
+
+	if pm, ok := seeder.(PassphraseMaker); ok {
+		passphrase, err := pm.Passphrase()
+		if err != nil {
+			return "", err
+		}
+		if passphrase != nil {
+			return passphrase.Generate(reader)
+		}
+	}
+
+	// the rules with constraints use a dedicated algorithm, the others keep the
+	// original one so that the stateless passwords already generated do not change
+	if rm, ok := seeder.(RuleMaker); ok {
+		rule, err := rm.Rule()
+		if err != nil {
+			return "", err
+		}
+		if rule.HasConstraints() {
+			return rule.Generate(reader)
+		}
+	}
+
 	template, err := seeder.Template()
 	if err != nil {
 		return "", err
 	}
 
 	chars := []byte(template)
+	if len(chars) == 0 {
+		return "", fmt.Errorf("the password template is empty: no chars are allowed")
+	}
 
 	var secret bytes.Buffer
 	for {
@@ -224,7 +368,11 @@ func (k *Key) Decrypt(src io.Reader) (io.Reader, error) {
 	return age.Decrypt(src, k.ageIdentity)
 }
 
-// Encrypt a message
+// Encrypt a message to the key own recipient and to the additional recipients, if any
 func (k *Key) Encrypt(dst io.Writer) (io.WriteCloser, error) {
-	return age.Encrypt(dst, k.ageIdentity.Recipient())
+	recipients, err := k.ageRecipients()
+	if err != nil {
+		return nil, err
+	}
+	return age.Encrypt(dst, recipients...)
 }
@@ -0,0 +1,111 @@
+package paw
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	// pronounceableDigits is the number of digits appended when the digits are enabled
+	pronounceableDigits = 2
+)
+
+const (
+	// pronounceableConsonants are the consonants easy to spell, c, q, w, x and y are skipped
+	pronounceableConsonants = "bdfghjklmnprstvz"
+	pronounceableVowels     = "aeiou"
+	// pronounceableSymbols are the symbols easy to name
+	pronounceableSymbols = "!#%+-=?@"
+)
+
+// Pronounceable holds the options to generate a pronounceable password.
+// The password is made of alternating consonants and vowels followed by
+// the optional digits and symbol.
+type Pronounceable struct {
+	// Length is the password length including the digits and the symbol
+	Length int
+	// Uppercase uppercases the first letter of random syllables
+	Uppercase bool
+	Digits    bool
+	Symbol    bool
+}
+
+// letters returns the number of letters of the password
+func (p *Pronounceable) letters() int {
+	n := p.Length
+	if p.Digits {
+		n -= pronounceableDigits
+	}
+	if p.Symbol {
+		n--
+	}
+	return n
+}
+
+// Entropy returns the entropy in bits of the generated passwords
+func (p *Pronounceable) Entropy() float64 {
+	n := p.letters()
+	consonants := (n + 1) / 2
+	vowels := n / 2
+	bits := float64(consonants)*math.Log2(float64(len(pronounceableConsonants))) +
+		float64(vowels)*math.Log2(float64(len(pronounceableVowels)))
+	if p.Uppercase {
+		// one bit for each syllable
+		bits += float64(consonants)
+	}
+	if p.Digits {
+		bits += pronounceableDigits * math.Log2(float64(len(digits)))
+	}
+	if p.Symbol {
+		bits += math.Log2(float64(len(pronounceableSymbols)))
+	}
+	return bits
+}
+
+// Generate generates a password reading the randomness from rand
+func (p *Pronounceable) Generate(rand io.Reader) (string, error) {
+	n := p.letters()
+	if n < 2 {
+		return "", fmt.Errorf("pronounceable password length must be at least %d", p.Length-n+2)
+	}
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		chars := pronounceableVowels
+		if i%2 == 0 {
+			chars = pronounceableConsonants
+		}
+		b, err := pick(rand, []byte(chars))
+		if err != nil {
+			return "", err
+		}
+		if p.Uppercase && i%2 == 0 {
+			upper, err := uniform(rand, 2)
+			if err != nil {
+				return "", err
+			}
+			if upper == 1 {
+				b -= 'a' - 'A'
+			}
+		}
+		sb.WriteByte(b)
+	}
+	if p.Digits {
+		for i := 0; i < pronounceableDigits; i++ {
+			b, err := pick(rand, []byte(digits))
+			if err != nil {
+				return "", err
+			}
+			sb.WriteByte(b)
+		}
+	}
+	if p.Symbol {
+		b, err := pick(rand, []byte(pronounceableSymbols))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,50 @@
+package paw
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPronounceable(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		format  Format
+		length  int
+		match   string
+		entropy float64
+		wantErr bool
+	}{
+		{name: "lowercase", format: LowercaseFormat, length: 6, match: `^([bdfghjklmnprstvz][aeiou]){3}$`, entropy: 3*4 + 3*2.3219},
+		{name: "odd length", format: LowercaseFormat, length: 7, match: `^([bdfghjklmnprstvz][aeiou]){3}[bdfghjklmnprstvz]$`},
+		{name: "default", format: PronounceablePasswordDefaultFormat, length: 12, match: `^([bdfghjklmnprstvz][aeiou]){5}[0-9]{2}$`},
+		{name: "uppercase", format: LowercaseFormat | UppercaseFormat, length: 8, match: `^([bdfghjklmnprstvzBDFGHJKLMNPRSTVZ][aeiou]){4}$`, entropy: 4*4 + 4*2.3219 + 4},
+		{name: "symbol", format: LowercaseFormat | DigitsFormat | SymbolsFormat, length: 9, match: `^([bdfghjklmnprstvz][aeiou]){3}[0-9]{2}[!#%+\-=?@]$`},
+		{name: "too short", format: LowercaseFormat | DigitsFormat | SymbolsFormat, length: 4, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password := NewPronounceablePassword()
+			password.Format = tt.format
+			password.Length = tt.length
+			if tt.entropy > 0 {
+				assert.InDelta(t, tt.entropy, password.Pronounceable().Entropy(), 0.001)
+			}
+			re := regexp.MustCompile(tt.match)
+			for i := 0; i < 20; i++ {
+				secret, err := key.Secret(password)
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Regexp(t, re, secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package paw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+)
+
+// ParseRecipient parses an age X25519 recipient (age1...) or an SSH public key
+// in the authorized_keys format (ssh-ed25519 and ssh-rsa)
+func ParseRecipient(s string) (age.Recipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		return age.ParseX25519Recipient(s)
+	case strings.HasPrefix(s, "ssh-"):
+		return agessh.ParseRecipient(s)
+	}
+	return nil, fmt.Errorf("unknown recipient type: %q", s)
+}
+
+// ReadRecipients reads the recipients from r, one per line.
+// Empty lines and lines starting with # are ignored.
+func ReadRecipients(r io.Reader) ([]string, error) {
+	var recipients []string
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := ParseRecipient(line); err != nil {
+			return nil, fmt.Errorf("invalid recipient at line %d: %w", n, err)
+		}
+		recipients = append(recipients, line)
+	}
+	return recipients, scanner.Err()
+}
+
+// WriteRecipients writes the recipients to w, one per line
+func WriteRecipients(w io.Writer, recipients []string) error {
+	for _, r := range recipients {
+		if _, err := fmt.Fprintln(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recipients returns the recipients the messages are encrypted to.
+// The first one is always the key own recipient.
+func (k *Key) Recipients() []string {
+	return append([]string{k.ageIdentity.Recipient().String()}, k.members...)
+}
+
+// SetRecipients sets the additional recipients the messages are encrypted to,
+// i.e. the members of a shared vault. The key own recipient is always included.
+func (k *Key) SetRecipients(recipients []string) error {
+	own := k.ageIdentity.Recipient().String()
+	members := []string{}
+	for _, r := range recipients {
+		r = strings.TrimSpace(r)
+		if _, err := ParseRecipient(r); err != nil {
+			return fmt.Errorf("paw: invalid recipient: %w", err)
+		}
+		if r == own || contains(members, r) {
+			continue
+		}
+		members = append(members, r)
+	}
+	k.members = members
+	return nil
+}
+
+// AddRecipient adds a recipient the messages are encrypted to.
+// Messages already encrypted must be re-encrypted using Reencrypt.
+func (k *Key) AddRecipient(recipient string) error {
+	recipient = strings.TrimSpace(recipient)
+	if _, err := ParseRecipient(recipient); err != nil {
+		return fmt.Errorf("paw: invalid recipient: %w", err)
+	}
+	if k.hasRecipient(recipient) {
+		return fmt.Errorf("paw: recipient already exists: %s", recipient)
+	}
+	k.members = append(k.members, recipient)
+	return nil
+}
+
+// RemoveRecipient removes a recipient the messages are encrypted to.
+// Messages already encrypted must be re-encrypted using Reencrypt.
+func (k *Key) RemoveRecipient(recipient string) error {
+	recipient = strings.TrimSpace(recipient)
+	if recipient == k.ageIdentity.Recipient().String() {
+		return fmt.Errorf("paw: the key own recipient cannot be removed")
+	}
+	for i, r := range k.members {
+		if r == recipient {
+			k.members = append(k.members[:i], k.members[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("paw: recipient not found: %s", recipient)
+}
+
+// Reencrypt decrypts the message from src and encrypts it again to dst
+// using the current recipients. It is used to grant or revoke the access
+// to the messages after the recipients are changed.
+func (k *Key) Reencrypt(dst io.Writer, src io.Reader) error {
+	return reencrypt(dst, src, k, k)
+}
+
+// reencrypt decrypts the message from src using the key from and encrypts it to dst using the key to
+func reencrypt(dst io.Writer, src io.Reader, from *Key, to *Key) error {
+	r, err := from.Decrypt(src)
+	if err != nil {
+		return fmt.Errorf("paw: reencrypt error: %w", err)
+	}
+	w, err := to.Encrypt(dst)
+	if err != nil {
+		return fmt.Errorf("paw: reencrypt error: %w", err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("paw: reencrypt error: %w", err)
+	}
+	return w.Close()
+}
+
+func (k *Key) hasRecipient(recipient string) bool {
+	return recipient == k.ageIdentity.Recipient().String() || contains(k.members, recipient)
+}
+
+// ageRecipients returns the parsed recipients
+func (k *Key) ageRecipients() ([]age.Recipient, error) {
+	recipients := []age.Recipient{k.ageIdentity.Recipient()}
+	for _, r := range k.members {
+		recipient, err := ParseRecipient(r)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
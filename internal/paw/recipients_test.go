@@ -0,0 +1,163 @@
+package paw
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseRecipient(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	sshRecipient, _ := generateSSHRecipient(t)
+
+	tests := []struct {
+		name      string
+		recipient string
+		wantErr   bool
+	}{
+		{name: "x25519", recipient: identity.Recipient().String()},
+		{name: "ssh-ed25519", recipient: sshRecipient},
+		{name: "x25519 identity", recipient: identity.String(), wantErr: true},
+		{name: "invalid", recipient: "age1invalid", wantErr: true},
+		{name: "unknown", recipient: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRecipient(tt.recipient)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReadWriteRecipients(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	sshRecipient, _ := generateSSHRecipient(t)
+	recipients := []string{identity.Recipient().String(), sshRecipient}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("# team members\n\n")
+	require.NoError(t, WriteRecipients(buf, recipients))
+
+	got, err := ReadRecipients(buf)
+	require.NoError(t, err)
+	assert.Equal(t, recipients, got)
+
+	_, err = ReadRecipients(strings.NewReader("age1invalid\n"))
+	assert.Error(t, err)
+}
+
+func TestKeyRecipients(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	member, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	sshRecipient, sshIdentity := generateSSHRecipient(t)
+
+	require.NoError(t, key.AddRecipient(member.Recipient().String()))
+	require.NoError(t, key.AddRecipient(sshRecipient))
+	assert.Error(t, key.AddRecipient(sshRecipient), "duplicated recipient")
+	assert.Error(t, key.AddRecipient("invalid"))
+	assert.Len(t, key.Recipients(), 3)
+
+	ciphertext := encryptMessage(t, key, "a secret note")
+	for _, identity := range []age.Identity{key.ageIdentity, member, sshIdentity} {
+		assert.Equal(t, "a secret note", decryptMessage(t, ciphertext, identity))
+	}
+
+	// revoke the member access
+	require.NoError(t, key.RemoveRecipient(member.Recipient().String()))
+	assert.Error(t, key.RemoveRecipient(member.Recipient().String()))
+	assert.Error(t, key.RemoveRecipient(key.Recipients()[0]), "own recipient cannot be removed")
+
+	reencrypted := &bytes.Buffer{}
+	require.NoError(t, key.Reencrypt(reencrypted, bytes.NewReader(ciphertext)))
+	_, err = age.Decrypt(bytes.NewReader(reencrypted.Bytes()), member)
+	assert.Error(t, err)
+	assert.Equal(t, "a secret note", decryptMessage(t, reencrypted.Bytes(), sshIdentity))
+
+	// the own recipient and duplicates are ignored
+	require.NoError(t, key.SetRecipients([]string{key.Recipients()[0], sshRecipient, sshRecipient}))
+	assert.Equal(t, []string{key.Recipients()[0], sshRecipient}, key.Recipients())
+}
+
+func TestOSStorageReencryptVault(t *testing.T) {
+	name := "shared"
+	password := "secret"
+
+	storage, err := NewOSStorageRooted(t.TempDir())
+	require.NoError(t, err)
+	s := storage.(*OSStorage)
+	require.NoError(t, s.mkdirIfNotExists(vaultRootPath(s, name)))
+
+	f, err := s.createFile(keyPath(s, name))
+	require.NoError(t, err)
+	key, err := MakeKey(password, MinWorkFactor, f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	itemPath := filepath.Join(vaultRootPath(s, name), "item.age")
+	require.NoError(t, os.WriteFile(itemPath, encryptMessage(t, key, "a secret note"), 0600))
+
+	member, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, key.AddRecipient(member.Recipient().String()))
+	require.NoError(t, s.StoreVaultRecipients(name, key))
+
+	n, err := s.ReencryptVault(name, key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	ciphertext, err := os.ReadFile(itemPath)
+	require.NoError(t, err)
+	assert.Equal(t, "a secret note", decryptMessage(t, ciphertext, member))
+	assert.NoFileExists(t, itemPath+".tmp")
+
+	loaded, err := s.LoadVaultKey(name, password)
+	require.NoError(t, err)
+	assert.Equal(t, key.Recipients(), loaded.Recipients())
+}
+
+func generateSSHRecipient(t *testing.T) (string, age.Identity) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	identity, err := agessh.NewEd25519Identity(priv)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))), identity
+}
+
+func encryptMessage(t *testing.T, key *Key, message string) []byte {
+	buf := &bytes.Buffer{}
+	w, err := key.Encrypt(buf)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, message)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decryptMessage(t *testing.T, ciphertext []byte, identity age.Identity) string {
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
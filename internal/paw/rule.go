@@ -3,6 +3,7 @@ package paw
 import (
 	"bytes"
 	"fmt"
+	"io"
 )
 
 const (
@@ -12,9 +13,48 @@ const (
 	symbols   = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
 )
 
+const (
+	// LookAlikeChars are the chars easily confused when read or typed
+	LookAlikeChars = "0O1lI"
+	// QuoteChars are the quotes and the backslash, often rejected by legacy systems
+	QuoteChars = "\"'`\\"
+	// ShellSafeSymbols are the symbols that do not need to be quoted in a POSIX shell
+	ShellSafeSymbols = "%+,-./:=@_"
+)
+
+// CharsetPreset is a predefined set of excluded chars and allowed symbols
+type CharsetPreset struct {
+	Name    string
+	Exclude string
+	Symbols string
+}
+
+// CharsetPresets returns the predefined charset presets
+func CharsetPresets() []CharsetPreset {
+	return []CharsetPreset{
+		{Name: "No look-alikes (0O1lI)", Exclude: LookAlikeChars},
+		{Name: "No quotes and backslashes", Exclude: QuoteChars},
+		{Name: "Shell-safe symbols", Symbols: ShellSafeSymbols},
+	}
+}
+
 // Format represents the format for a rule
 type Format int
 
+func (f Format) String() string {
+	switch f {
+	case LowercaseFormat:
+		return "lowercase"
+	case UppercaseFormat:
+		return "uppercase"
+	case DigitsFormat:
+		return "digits"
+	case SymbolsFormat:
+		return "symbols"
+	}
+	return fmt.Sprintf("format(%d)", int(f))
+}
+
 const (
 	// LowercaseFormat specify a format with all lowercase chars
 	LowercaseFormat Format = 1 << iota
@@ -26,11 +66,31 @@ const (
 	SymbolsFormat
 )
 
+// formatClasses lists the chars of each format class in the template order
+var formatClasses = []struct {
+	format Format
+	chars  string
+}{
+	{format: LowercaseFormat, chars: lowercase},
+	{format: UppercaseFormat, chars: uppercase},
+	{format: DigitsFormat, chars: digits},
+	{format: SymbolsFormat, chars: symbols},
+}
+
 // Rule defines the policy for password generation
 type Rule struct {
 	Length int
 	Tpl    []byte
 	Filter []byte
+	// Min holds the minimum number of chars required for each format class
+	Min map[Format]int `json:",omitempty"`
+	// MaxConsecutive is the max number of identical consecutive chars. Zero means no limit
+	MaxConsecutive int `json:",omitempty"`
+	// Required holds the sets of chars that must appear at least once into the password
+	Required []string `json:",omitempty"`
+	// MinLength and MaxLength are the length limits defined by the rule, zero means no limit
+	MinLength int `json:",omitempty"`
+	MaxLength int `json:",omitempty"`
 }
 
 // NewRule defines a policy for password generation specifying the
@@ -64,6 +124,233 @@ func (r *Rule) WithFilter(filter []byte) {
 	copy(r.Filter, filter)
 }
 
+// WithSymbols restricts the symbols allowed by the rule. Symbols must be a subset
+// of the default ones and are used only if the rule includes the SymbolsFormat.
+func (r *Rule) WithSymbols(allowed []byte) error {
+	for _, b := range allowed {
+		if bytes.IndexByte([]byte(symbols), b) == -1 {
+			return fmt.Errorf("invalid symbol %q", b)
+		}
+	}
+	if len(allowed) == 0 || !bytes.ContainsAny(r.Tpl, symbols) {
+		return nil
+	}
+	var tpl []byte
+	for _, b := range r.Tpl {
+		if bytes.IndexByte([]byte(symbols), b) == -1 {
+			tpl = append(tpl, b)
+		}
+	}
+	// keep the default symbols order
+	for _, b := range []byte(symbols) {
+		if bytes.IndexByte(allowed, b) != -1 {
+			tpl = append(tpl, b)
+		}
+	}
+	r.Tpl = tpl
+	return nil
+}
+
+// WithMin requires at least n chars of the format class
+func (r *Rule) WithMin(format Format, n int) {
+	if n <= 0 {
+		delete(r.Min, format)
+		return
+	}
+	if r.Min == nil {
+		r.Min = map[Format]int{}
+	}
+	r.Min[format] = n
+}
+
+// WithMaxConsecutive limits the number of identical consecutive chars
+func (r *Rule) WithMaxConsecutive(n int) {
+	r.MaxConsecutive = n
+}
+
+// HasConstraints reports whether the rule requires min counts or limits the consecutive chars
+func (r *Rule) HasConstraints() bool {
+	return len(r.Min) > 0 || r.MaxConsecutive > 0 || len(r.Required) > 0
+}
+
+// Validate returns an error if the password cannot satisfy the rule
+func (r *Rule) Validate() error {
+	if r.MaxConsecutive < 0 {
+		return fmt.Errorf("max consecutive chars cannot be negative")
+	}
+	total := 0
+	for _, c := range formatClasses {
+		n := r.Min[c.format]
+		if n == 0 {
+			continue
+		}
+		if n < 0 {
+			return fmt.Errorf("min count for %s cannot be negative", c.format)
+		}
+		if len(r.classChars(c.format)) == 0 {
+			return fmt.Errorf("min count for %s requires the class to be enabled and not filtered", c.format)
+		}
+		total += n
+	}
+	for _, req := range r.Required {
+		if len(r.templateChars([]byte(req))) == 0 {
+			return fmt.Errorf("required chars %q are not allowed by the rule", req)
+		}
+		total++
+	}
+	if total > r.Length {
+		return fmt.Errorf("required chars (%d) exceed the password length (%d)", total, r.Length)
+	}
+	return nil
+}
+
+// classChars returns the template chars of the format class with the filter applied
+func (r *Rule) classChars(format Format) []byte {
+	for _, c := range formatClasses {
+		if c.format == format {
+			return r.templateChars([]byte(c.chars))
+		}
+	}
+	return nil
+}
+
+// templateChars returns the chars included into the template with the filter applied
+func (r *Rule) templateChars(chars []byte) []byte {
+	var tc []byte
+	for _, b := range chars {
+		if bytes.IndexByte(r.Tpl, b) == -1 || bytes.IndexByte(r.Filter, b) != -1 {
+			continue
+		}
+		tc = append(tc, b)
+	}
+	return tc
+}
+
+// Generate generates a password satisfying the rule reading the randomness from rand.
+// The output is deterministic for the same rand stream:
+// the required chars for each class and set are drawn first, the remaining ones from the whole template,
+// then the chars are shuffled and the runs longer than MaxConsecutive are replaced.
+func (r *Rule) Generate(rand io.Reader) (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+	tpl, err := r.Template()
+	if err != nil {
+		return "", err
+	}
+	if len(tpl) == 0 {
+		return "", fmt.Errorf("the password template is empty")
+	}
+
+	secret := make([]byte, 0, r.Length)
+	// pools holds the chars allowed for each position to keep the class counts when replacing
+	pools := make([][]byte, 0, r.Length)
+	add := func(pool []byte) error {
+		b, err := pick(rand, pool)
+		if err != nil {
+			return err
+		}
+		secret = append(secret, b)
+		pools = append(pools, pool)
+		return nil
+	}
+
+	for _, c := range formatClasses {
+		pool := r.classChars(c.format)
+		for i := 0; i < r.Min[c.format]; i++ {
+			if err := add(pool); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, req := range r.Required {
+		if err := add(r.templateChars([]byte(req))); err != nil {
+			return "", err
+		}
+	}
+	for len(secret) < r.Length {
+		if err := add([]byte(tpl)); err != nil {
+			return "", err
+		}
+	}
+
+	// Fisher-Yates shuffle
+	for i := len(secret) - 1; i > 0; i-- {
+		j, err := uniform(rand, i+1)
+		if err != nil {
+			return "", err
+		}
+		secret[i], secret[j] = secret[j], secret[i]
+		pools[i], pools[j] = pools[j], pools[i]
+	}
+
+	if r.MaxConsecutive == 0 {
+		return string(secret), nil
+	}
+
+	run := 1
+	for i := 1; i < len(secret); i++ {
+		if secret[i] != secret[i-1] {
+			run = 1
+			continue
+		}
+		run++
+		if run <= r.MaxConsecutive {
+			continue
+		}
+		// replace the char with one different from both the neighbours so that
+		// no new run is created. When not possible the next run is handled
+		// by the following iterations
+		var pool, fallback []byte
+		for _, b := range pools[i] {
+			if b == secret[i-1] {
+				continue
+			}
+			fallback = append(fallback, b)
+			if i+1 < len(secret) && b == secret[i+1] {
+				continue
+			}
+			pool = append(pool, b)
+		}
+		if len(pool) == 0 {
+			pool = fallback
+		}
+		b, err := pick(rand, pool)
+		if err != nil {
+			return "", fmt.Errorf("cannot satisfy max %d consecutive chars: %w", r.MaxConsecutive, err)
+		}
+		secret[i] = b
+		run = 1
+	}
+	return string(secret), nil
+}
+
+// pick returns a char from chars chosen uniformly
+func pick(rand io.Reader, chars []byte) (byte, error) {
+	i, err := uniform(rand, len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// uniform returns an uniform random int in [0,n) with n <= 256 using rejection sampling
+func uniform(rand io.Reader, n int) (int, error) {
+	if n <= 0 || n > 256 {
+		return 0, fmt.Errorf("invalid range [0,%d)", n)
+	}
+	max := 256 - 256%n
+	buf := make([]byte, 1)
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return 0, err
+		}
+		if int(buf[0]) < max {
+			return int(buf[0]) % n, nil
+		}
+	}
+}
+
 // Len returns the desired password length
 func (r *Rule) Len() int {
 	return r.Length
@@ -78,7 +365,6 @@ func (r *Rule) Template() (string, error) {
 	var filtered bytes.Buffer
 	for _, b := range r.Tpl {
 		if pos := bytes.IndexByte(r.Filter, b); pos != -1 {
-			fmt.Printf("found %s\n", string(b))
 			continue
 		}
 		err := filtered.WriteByte(b)
@@ -2,10 +2,15 @@ package paw
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/hkdf"
 )
 
 func TestRule(t *testing.T) {
@@ -127,3 +132,207 @@ func TestRuleEncodeRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleGenerate(t *testing.T) {
+	tests := []struct {
+		name           string
+		length         int
+		format         Format
+		filter         []byte
+		min            map[Format]int
+		maxConsecutive int
+	}{
+		{
+			name:   "min counts",
+			length: 8,
+			format: LowercaseFormat | UppercaseFormat | DigitsFormat | SymbolsFormat,
+			min:    map[Format]int{UppercaseFormat: 1, DigitsFormat: 2, SymbolsFormat: 1},
+		},
+		{
+			name:   "min counts fill the length",
+			length: 6,
+			format: LowercaseFormat | DigitsFormat,
+			min:    map[Format]int{LowercaseFormat: 3, DigitsFormat: 3},
+		},
+		{
+			name:   "min counts with filter",
+			length: 16,
+			format: DigitsFormat | SymbolsFormat,
+			filter: []byte("0123456"),
+			min:    map[Format]int{DigitsFormat: 4},
+		},
+		{
+			name:           "max consecutive",
+			length:         64,
+			format:         DigitsFormat,
+			filter:         []byte("01234567"),
+			maxConsecutive: 1,
+		},
+		{
+			name:           "min counts and max consecutive",
+			length:         32,
+			format:         LowercaseFormat | DigitsFormat,
+			min:            map[Format]int{DigitsFormat: 10},
+			maxConsecutive: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRule(tt.length, tt.format)
+			require.NoError(t, err)
+			rule.WithFilter(tt.filter)
+			for format, n := range tt.min {
+				rule.WithMin(format, n)
+			}
+			rule.WithMaxConsecutive(tt.maxConsecutive)
+			require.True(t, rule.HasConstraints())
+
+			for i := 0; i < 100; i++ {
+				salt := []byte(fmt.Sprintf("salt-%d", i))
+				secret, err := rule.Generate(hkdf.New(sha256.New, []byte("secret"), salt, nil))
+				require.NoError(t, err)
+				assert.Len(t, secret, tt.length)
+
+				// the generation must be deterministic
+				again, err := rule.Generate(hkdf.New(sha256.New, []byte("secret"), salt, nil))
+				require.NoError(t, err)
+				assert.Equal(t, secret, again)
+
+				tpl, _ := rule.Template()
+				for _, c := range secret {
+					assert.Contains(t, tpl, string(c))
+				}
+				for format, n := range tt.min {
+					count := 0
+					for _, c := range secret {
+						if bytes.IndexByte(rule.classChars(format), byte(c)) != -1 {
+							count++
+						}
+					}
+					assert.GreaterOrEqual(t, count, n, "%s in %q", format, secret)
+				}
+				if tt.maxConsecutive > 0 {
+					for _, c := range tpl {
+						run := strings.Repeat(string(c), tt.maxConsecutive+1)
+						assert.NotContains(t, secret, run)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	rule, err := NewRule(4, LowercaseFormat|DigitsFormat)
+	require.NoError(t, err)
+	rule.WithMin(LowercaseFormat, 3)
+	rule.WithMin(DigitsFormat, 2)
+	assert.Error(t, rule.Validate(), "min counts exceed the length")
+
+	rule.WithMin(DigitsFormat, 0)
+	assert.NoError(t, rule.Validate())
+
+	rule.WithMin(SymbolsFormat, 1)
+	assert.Error(t, rule.Validate(), "symbols are not enabled")
+	rule.WithMin(SymbolsFormat, 0)
+
+	rule.WithFilter([]byte(lowercase))
+	assert.Error(t, rule.Validate(), "lowercase are all filtered")
+
+	rule, err = NewRule(8, DigitsFormat)
+	require.NoError(t, err)
+	rule.WithFilter([]byte("123456789"))
+	rule.WithMaxConsecutive(1)
+	_, err = rule.Generate(hkdf.New(sha256.New, []byte("secret"), nil, nil))
+	assert.Error(t, err, "max consecutive cannot be satisfied with one char")
+}
+
+func TestPasswordSecretWithoutConstraints(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	// passwords without constraints must keep the original algorithm
+	// to not change the already generated stateless passwords
+	password := NewRandomPassword()
+	password.Mode = StatelessPassword
+	password.Name = "example.com"
+	rule, err := password.Rule()
+	require.NoError(t, err)
+	assert.False(t, rule.HasConstraints())
+
+	secret, err := key.Secret(password)
+	require.NoError(t, err)
+	again, err := key.Secret(password)
+	require.NoError(t, err)
+	assert.Equal(t, secret, again)
+
+	password.Min = map[Format]int{DigitsFormat: 3, SymbolsFormat: 3}
+	password.MaxConsecutive = 1
+	constrained, err := key.Secret(password)
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, constrained)
+	again, err = key.Secret(password)
+	require.NoError(t, err)
+	assert.Equal(t, constrained, again)
+}
+
+func TestRuleWithSymbols(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       Format
+		symbols      string
+		filter       string
+		wantTemplate string
+		wantErr      bool
+	}{
+		{name: "all symbols", format: DigitsFormat | SymbolsFormat, wantTemplate: digits + symbols},
+		{name: "shell safe", format: DigitsFormat | SymbolsFormat, symbols: ShellSafeSymbols, wantTemplate: digits + ShellSafeSymbols},
+		{name: "unordered", format: SymbolsFormat, symbols: "_-", wantTemplate: "-_"},
+		{name: "symbols not enabled", format: DigitsFormat, symbols: "_-", wantTemplate: digits},
+		{name: "look-alikes excluded", format: LowercaseFormat | UppercaseFormat | DigitsFormat, filter: LookAlikeChars, wantTemplate: "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"},
+		{name: "quotes excluded", format: SymbolsFormat, filter: QuoteChars, wantTemplate: "!#$%&()*+,-./:;<=>?@[]^_{|}~"},
+		{name: "invalid symbol", format: SymbolsFormat, symbols: "a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRule(8, tt.format)
+			require.NoError(t, err)
+			rule.WithFilter([]byte(tt.filter))
+			err = rule.WithSymbols([]byte(tt.symbols))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			template, err := rule.Template()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTemplate, template)
+		})
+	}
+}
+
+func TestPasswordRuleExclusions(t *testing.T) {
+	key, err := MakeOneTimeKey()
+	require.NoError(t, err)
+
+	password := NewRandomPassword()
+	password.Length = 64
+	password.Exclude = LookAlikeChars + QuoteChars
+	password.Symbols = ShellSafeSymbols
+	for i := 0; i < 10; i++ {
+		secret, err := key.Secret(password)
+		require.NoError(t, err)
+		assert.Len(t, secret, 64)
+		assert.False(t, strings.ContainsAny(secret, LookAlikeChars+QuoteChars), secret)
+		for _, c := range secret {
+			if strings.ContainsRune(symbols, c) {
+				assert.Contains(t, ShellSafeSymbols, string(c))
+			}
+		}
+	}
+
+	password.Format = DigitsFormat
+	password.Exclude = digits
+	_, err = key.Secret(password)
+	assert.Error(t, err, "all the chars are excluded")
+}
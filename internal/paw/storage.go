@@ -1,13 +1,20 @@
 package paw
 
 import (
+	"net/url"
 	"path/filepath"
 )
 
 const (
-	storageRootName = "storage"
-	keyFileName     = "key.age"
-	vaultFileName   = "vault.age"
+	storageRootName        = "storage"
+	keyFileName            = "key.age"
+	sshKeyFileName         = "key.ssh.age"
+	vaultFileName          = "vault.age"
+	recipientsFileName     = "recipients.txt"
+	rotationDir            = ".rotation"
+	rotationCommitFileName = "commit"
+	wordlistsDir           = "wordlists"
+	itemsDir               = "items"
 )
 
 type Storage interface {
@@ -21,3 +28,30 @@ func storageRootPath(s Storage) string {
 func vaultRootPath(s Storage, name string) string {
 	return filepath.Join(storageRootPath(s), name)
 }
+
+// WordlistsPath returns the path of the directory holding the user provided wordlists
+func WordlistsPath(s Storage) string {
+	return filepath.Join(s.Root(), wordlistsDir)
+}
+
+func recipientsPath(s Storage, name string) string {
+	return filepath.Join(vaultRootPath(s, name), recipientsFileName)
+}
+
+func keyPath(s Storage, name string) string {
+	return filepath.Join(vaultRootPath(s, name), keyFileName)
+}
+
+func sshKeyPath(s Storage, name string) string {
+	return filepath.Join(vaultRootPath(s, name), sshKeyFileName)
+}
+
+// itemPath returns the path of the item file, the items are grouped by type and
+// the names are escaped so that they can be used as file names
+func itemPath(s Storage, name string, itemType ItemType, itemName string) string {
+	return filepath.Join(vaultRootPath(s, name), itemsDir, itemType.String(), url.PathEscape(itemName)+".age")
+}
+
+func rotationPath(s Storage, name string) string {
+	return filepath.Join(vaultRootPath(s, name), rotationDir)
+}
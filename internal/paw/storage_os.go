@@ -1,9 +1,16 @@
 package paw
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/ssh"
 )
 
 // Declare conformity to Item interface
@@ -58,3 +65,190 @@ func (s *OSStorage) mkdirIfNotExists(path string) error {
 func (s *OSStorage) createFile(name string) (*os.File, error) {
 	return os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 }
+
+// VaultRecipients returns the recipients of a shared vault.
+// The recipients are stored in plain text so that can be reviewed, i.e. into a Git repo.
+func (s *OSStorage) VaultRecipients(name string) ([]string, error) {
+	f, err := os.Open(recipientsPath(s, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRecipients(f)
+}
+
+// LoadVaultRecipients loads the recipients of a shared vault into the key
+func (s *OSStorage) LoadVaultRecipients(name string, key *Key) error {
+	recipients, err := s.VaultRecipients(name)
+	if err != nil {
+		return err
+	}
+	return key.SetRecipients(recipients)
+}
+
+// StoreVaultRecipients stores the key recipients for a shared vault.
+// The key own recipient is stored as well, so that the file can be shared among the members.
+func (s *OSStorage) StoreVaultRecipients(name string, key *Key) error {
+	err := s.mkdirIfNotExists(vaultRootPath(s, name))
+	if err != nil {
+		return err
+	}
+	f, err := s.createFile(recipientsPath(s, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writeVaultRecipients(f, name, key); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeVaultRecipients writes the recipients file content for the key of the named vault
+func writeVaultRecipients(w io.Writer, name string, key *Key) error {
+	fmt.Fprintf(w, "# %s vault recipients\n", name)
+	return WriteRecipients(w, key.Recipients())
+}
+
+// LoadVaultKey loads the vault key protected by password along with the vault recipients
+func (s *OSStorage) LoadVaultKey(name string, password string) (*Key, error) {
+	if err := s.recoverVaultRotation(name); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(keyPath(s, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	key, err := LoadKey(password, f)
+	if err != nil {
+		return nil, err
+	}
+	return key, s.LoadVaultRecipients(name, key)
+}
+
+// LoadVaultSSHKey loads the vault key wrapped to the SSH keys along with the vault recipients.
+// It returns an error wrapping os.ErrNotExist if the key has not been wrapped by StoreVaultSSHKey.
+func (s *OSStorage) LoadVaultSSHKey(name string, identities ...age.Identity) (*Key, error) {
+	if err := s.recoverVaultRotation(name); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(sshKeyPath(s, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	key, err := LoadKeySSH(f, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return key, s.LoadVaultRecipients(name, key)
+}
+
+// StoreVaultSSHKey stores the vault key wrapped to the SSH keys, so that can be unlocked
+// as alternative to the password. See WrapKeySSH for details.
+func (s *OSStorage) StoreVaultSSHKey(name string, key *Key, publicKeys []string, signers []ssh.Signer) error {
+	f, err := s.createFile(sshKeyPath(s, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := WrapKeySSH(key, f, publicKeys, signers); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ReencryptVault encrypts again all the items of the vault to the current key recipients
+// and stores the recipients. The items and the recipients are written into a staging directory
+// first and then moved into the vault, so that a failure does not leave the items encrypted
+// to different recipients. An interrupted move is completed the next time the vault is loaded.
+func (s *OSStorage) ReencryptVault(name string, key *Key) (int, error) {
+	if err := s.recoverVaultRotation(name); err != nil {
+		return 0, err
+	}
+	n, err := s.stageVaultItems(name, key, key, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.writeCommitMarker(name, key); err != nil {
+		return 0, err
+	}
+	return n, s.commitVaultRotation(name)
+}
+
+// StoreVaultKey stores the vault key protected by password replacing the existing one, if any.
+// It is used to change the vault password or the scrypt work factor, zero means DefaultWorkFactor.
+func (s *OSStorage) StoreVaultKey(name string, key *Key, password string, workFactor int) error {
+	if err := s.mkdirIfNotExists(vaultRootPath(s, name)); err != nil {
+		return err
+	}
+	return s.writeFileAtomic(keyPath(s, name), func(w io.Writer) error {
+		return WrapKey(key, password, workFactor, w)
+	})
+}
+
+// VaultKeyInfo returns the metadata of the vault key without unlocking it
+func (s *OSStorage) VaultKeyInfo(name string) (*KeyInfo, error) {
+	f, err := os.Open(keyPath(s, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadKeyInfo(f)
+}
+
+// vaultItemFiles returns the path of the age files of the vault, but the keys
+func (s *OSStorage) vaultItemFiles(name string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(vaultRootPath(s, name), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path == rotationPath(s, name) {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".age") || path == keyPath(s, name) || path == sshKeyPath(s, name) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+// reencryptFile re-encrypts the file at path from a key to another one writing to w
+func (s *OSStorage) reencryptFile(w io.Writer, path string, from *Key, to *Key) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return reencrypt(w, src, from, to)
+}
+
+// writeFileAtomic writes the file at path using a temporary file that is synced
+// and then renamed, so that a failure does not leave the file partially written
+func (s *OSStorage) writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := s.createFile(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,117 @@
+package paw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CreateVault creates a local vault protected by password, using the scrypt work factor.
+// It fails if the vault already exists.
+func (s *OSStorage) CreateVault(name string, password string, workFactor int) (*Key, error) {
+	if s.isExist(keyPath(s, name)) {
+		return nil, fmt.Errorf("the vault %q already exists", name)
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	key.setCreated(time.Now())
+	if err := s.StoreVaultKey(name, key, password, workFactor); err != nil {
+		return nil, err
+	}
+	if err := s.StoreVaultRecipients(name, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// StoreVaultItem stores the item into the local vault encrypted to all the key recipients,
+// so that each member can decrypt it using its own identity, i.e. with the age CLI
+func (s *OSStorage) StoreVaultItem(name string, key *Key, item Item) error {
+	m := item.GetMetadata()
+	if m.Name == "" {
+		return errors.New("the item name is required")
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	path := itemPath(s, name, m.Type, m.Name)
+	if err := s.mkdirIfNotExists(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return s.writeFileAtomic(path, func(w io.Writer) error {
+		ew, err := key.Encrypt(w)
+		if err != nil {
+			return err
+		}
+		if _, err := ew.Write(data); err != nil {
+			return err
+		}
+		return ew.Close()
+	})
+}
+
+// LoadVaultItem loads the item from the local vault
+func (s *OSStorage) LoadVaultItem(name string, key *Key, itemType ItemType, itemName string) (Item, error) {
+	return s.loadItemFile(itemPath(s, name, itemType, itemName), key)
+}
+
+// VaultItems loads all the items of the local vault sorted by name
+func (s *OSStorage) VaultItems(name string, key *Key) ([]Item, error) {
+	var items []Item
+	err := filepath.WalkDir(filepath.Join(vaultRootPath(s, name), itemsDir), func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".age") {
+			return nil
+		}
+		item, err := s.loadItemFile(path, key)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].GetMetadata().Name < items[j].GetMetadata().Name
+	})
+	return items, nil
+}
+
+// loadItemFile decrypts and decodes the item file at path
+func (s *OSStorage) loadItemFile(path string, key *Key) (Item, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := key.Decrypt(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt %s: %w", path, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt %s: %w", path, err)
+	}
+	item, err := UnmarshalItem(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item %s: %w", path, err)
+	}
+	return item, nil
+}
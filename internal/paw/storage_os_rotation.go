@@ -0,0 +1,172 @@
+package paw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RotateVaultKey generates a new key for the vault protected by password, using the scrypt
+// work factor, and re-encrypts all the items to it. The new key keeps the recipients of the old one.
+//
+// The rotation is staged: the new key and the re-encrypted items are written into a staging
+// directory that is committed, by moving the files into the vault, only once completed.
+// An interrupted rotation is rolled back or completed the next time the vault key is loaded,
+// so that the vault is never left half rotated.
+//
+// The key wrapped to the SSH keys, if any, is removed since still wraps the old key.
+// Note that the stateless passwords are derived from the key, so they change as well.
+func (s *OSStorage) RotateVaultKey(name string, oldKey *Key, password string, workFactor int) (*Key, error) {
+	if err := s.recoverVaultRotation(name); err != nil {
+		return nil, err
+	}
+	newKey, err := s.stageVaultRotation(name, oldKey, password, workFactor)
+	if err != nil {
+		return nil, err
+	}
+	return newKey, s.commitVaultRotation(name)
+}
+
+// stageVaultRotation writes the new key, the re-encrypted items and the recipients into the staging directory.
+// The rotation is committed once the commit marker is written, from now on an interrupted
+// rotation is completed on recovery.
+func (s *OSStorage) stageVaultRotation(name string, oldKey *Key, password string, workFactor int) (*Key, error) {
+	newKey, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := newKey.SetRecipients(oldKey.members); err != nil {
+		return nil, err
+	}
+	newKey.setCreated(time.Now())
+
+	// the recipients file lists the key own recipient, so it must be rotated along with the key
+	if _, err := s.stageVaultItems(name, oldKey, newKey, s.isExist(recipientsPath(s, name))); err != nil {
+		return nil, err
+	}
+	err = s.writeFileAtomic(filepath.Join(rotationPath(s, name), keyFileName), func(w io.Writer) error {
+		return WrapKey(newKey, password, workFactor, w)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newKey, s.writeCommitMarker(name, newKey)
+}
+
+// stageVaultItems writes into the staging directory the items re-encrypted from a key to
+// another one and, if recipients is true, the recipients file of the new key.
+// The staging directory is removed on failure, so that nothing is committed.
+func (s *OSStorage) stageVaultItems(name string, from *Key, to *Key, recipients bool) (int, error) {
+	staging := rotationPath(s, name)
+	if err := os.RemoveAll(staging); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(staging, 0700); err != nil {
+		return 0, err
+	}
+	n, err := s.stageFiles(name, from, to, recipients)
+	if err != nil {
+		os.RemoveAll(staging)
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *OSStorage) stageFiles(name string, from *Key, to *Key, recipients bool) (int, error) {
+	staging := rotationPath(s, name)
+	files, err := s.vaultItemFiles(name)
+	if err != nil {
+		return 0, err
+	}
+	root := vaultRootPath(s, name)
+	for _, path := range files {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return 0, err
+		}
+		staged := filepath.Join(staging, rel)
+		if err := os.MkdirAll(filepath.Dir(staged), 0700); err != nil {
+			return 0, err
+		}
+		err = s.writeFileAtomic(staged, func(w io.Writer) error {
+			return s.reencryptFile(w, path, from, to)
+		})
+		if err != nil {
+			return 0, fmt.Errorf("could not reencrypt %s: %w", path, err)
+		}
+	}
+	if recipients {
+		err = s.writeFileAtomic(filepath.Join(staging, recipientsFileName), func(w io.Writer) error {
+			return writeVaultRecipients(w, name, to)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(files), nil
+}
+
+// writeCommitMarker writes the commit marker of the staged files
+func (s *OSStorage) writeCommitMarker(name string, key *Key) error {
+	return s.writeFileAtomic(filepath.Join(rotationPath(s, name), rotationCommitFileName), func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%s\n", key.Recipients()[0])
+		return err
+	})
+}
+
+// recoverVaultRotation rolls back an interrupted rotation not yet committed
+// or completes a committed one
+func (s *OSStorage) recoverVaultRotation(name string) error {
+	staging := rotationPath(s, name)
+	if !s.isExist(staging) {
+		return nil
+	}
+	if !s.isExist(filepath.Join(staging, rotationCommitFileName)) {
+		return os.RemoveAll(staging)
+	}
+	return s.commitVaultRotation(name)
+}
+
+// commitVaultRotation moves the staged files into the vault, see RotateVaultKey and ReencryptVault. The recipients and the key are moved as last,
+// so that an interruption can be completed safely moving the remaining files.
+func (s *OSStorage) commitVaultRotation(name string) error {
+	staging := rotationPath(s, name)
+	root := vaultRootPath(s, name)
+	stagedKey := filepath.Join(staging, keyFileName)
+	err := filepath.WalkDir(staging, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".age") || path == stagedKey {
+			return nil
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+		return os.Rename(path, filepath.Join(root, rel))
+	})
+	if err != nil {
+		return fmt.Errorf("could not commit the staged files: %w", err)
+	}
+	stagedRecipients := filepath.Join(staging, recipientsFileName)
+	if s.isExist(stagedRecipients) {
+		if err := os.Rename(stagedRecipients, recipientsPath(s, name)); err != nil {
+			return fmt.Errorf("could not commit the staged files: %w", err)
+		}
+	}
+	if s.isExist(stagedKey) {
+		if err := os.Remove(sshKeyPath(s, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.Rename(stagedKey, keyPath(s, name)); err != nil {
+			return fmt.Errorf("could not commit the staged files: %w", err)
+		}
+	}
+	return os.RemoveAll(staging)
+}
@@ -0,0 +1,166 @@
+package paw
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestVault creates a local vault protected by password with the items
+func newTestVault(t *testing.T, name string, password string, items map[string]string) (*OSStorage, *Key) {
+	storage, err := NewOSStorageRooted(t.TempDir())
+	require.NoError(t, err)
+	s := storage.(*OSStorage)
+	require.NoError(t, s.mkdirIfNotExists(filepath.Join(vaultRootPath(s, name), "items")))
+
+	f, err := s.createFile(keyPath(s, name))
+	require.NoError(t, err)
+	key, err := MakeKey(password, MinWorkFactor, f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	for item, value := range items {
+		path := filepath.Join(vaultRootPath(s, name), "items", item+".age")
+		require.NoError(t, os.WriteFile(path, encryptMessage(t, key, value), 0600))
+	}
+	return s, key
+}
+
+func readItem(t *testing.T, s *OSStorage, name string, item string, key *Key) string {
+	ciphertext, err := os.ReadFile(filepath.Join(vaultRootPath(s, name), "items", item+".age"))
+	require.NoError(t, err)
+	return decryptMessage(t, ciphertext, key.ageIdentity)
+}
+
+func canDecryptItem(t *testing.T, s *OSStorage, name string, item string, key *Key) bool {
+	ciphertext, err := os.ReadFile(filepath.Join(vaultRootPath(s, name), "items", item+".age"))
+	require.NoError(t, err)
+	_, err = key.Decrypt(bytes.NewReader(ciphertext))
+	return err == nil
+}
+
+func TestOSStorageStoreVaultKey(t *testing.T) {
+	s, key := newTestVault(t, "test", "old", nil)
+
+	require.NoError(t, s.StoreVaultKey("test", key, "new", MinWorkFactor))
+
+	_, err := s.LoadVaultKey("test", "old")
+	assert.Error(t, err)
+	loaded, err := s.LoadVaultKey("test", "new")
+	require.NoError(t, err)
+	assert.Equal(t, key.Identity(), loaded.Identity())
+	assert.NoFileExists(t, keyPath(s, "test")+".tmp")
+}
+
+func TestOSStorageRotateVaultKey(t *testing.T) {
+	items := map[string]string{"github": "s3cret", "gitlab": "an0ther"}
+	s, oldKey := newTestVault(t, "test", "password", items)
+
+	member, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, oldKey.AddRecipient(member.Recipient().String()))
+	require.NoError(t, s.StoreVaultRecipients("test", oldKey))
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(edKey)
+	require.NoError(t, err)
+	require.NoError(t, s.StoreVaultSSHKey("test", oldKey, nil, []ssh.Signer{signer}))
+
+	newKey, err := s.RotateVaultKey("test", oldKey, "new password", MinWorkFactor)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldKey.Identity(), newKey.Identity())
+	assert.Equal(t, oldKey.Recipients()[1:], newKey.Recipients()[1:], "recipients are kept")
+
+	for item, value := range items {
+		assert.False(t, canDecryptItem(t, s, "test", item, oldKey))
+		assert.Equal(t, value, readItem(t, s, "test", item, newKey))
+	}
+
+	loaded, err := s.LoadVaultKey("test", "new password")
+	require.NoError(t, err)
+	assert.Equal(t, newKey.Identity(), loaded.Identity())
+	assert.Equal(t, newKey.Recipients(), loaded.Recipients())
+	assert.NotContains(t, loaded.Recipients(), oldKey.Recipients()[0], "the old key must not be a member")
+	recipients, err := s.VaultRecipients("test")
+	require.NoError(t, err)
+	assert.NotContains(t, recipients, oldKey.Recipients()[0])
+	assert.NoDirExists(t, rotationPath(s, "test"))
+	assert.NoFileExists(t, sshKeyPath(s, "test"), "the SSH wrapped key must be removed")
+}
+
+func TestOSStorageRecoverVaultRotation(t *testing.T) {
+	items := map[string]string{"github": "s3cret", "gitlab": "an0ther"}
+
+	t.Run("not committed", func(t *testing.T) {
+		s, oldKey := newTestVault(t, "test", "password", items)
+		_, err := s.stageVaultRotation("test", oldKey, "new password", MinWorkFactor)
+		require.NoError(t, err)
+		// simulate an interruption before the commit marker is written
+		require.NoError(t, os.Remove(filepath.Join(rotationPath(s, "test"), rotationCommitFileName)))
+
+		loaded, err := s.LoadVaultKey("test", "password")
+		require.NoError(t, err)
+		assert.Equal(t, oldKey.Identity(), loaded.Identity())
+		assert.NoDirExists(t, rotationPath(s, "test"))
+		for item := range items {
+			assert.True(t, canDecryptItem(t, s, "test", item, oldKey))
+		}
+	})
+
+	t.Run("committed", func(t *testing.T) {
+		s, oldKey := newTestVault(t, "test", "password", items)
+		newKey, err := s.stageVaultRotation("test", oldKey, "new password", MinWorkFactor)
+		require.NoError(t, err)
+		// simulate an interruption after only an item has been moved into the vault
+		staged := filepath.Join(rotationPath(s, "test"), "items", "github.age")
+		require.NoError(t, os.Rename(staged, filepath.Join(vaultRootPath(s, "test"), "items", "github.age")))
+
+		_, err = s.LoadVaultKey("test", "password")
+		assert.Error(t, err, "the rotation must be completed")
+		loaded, err := s.LoadVaultKey("test", "new password")
+		require.NoError(t, err)
+		assert.Equal(t, newKey.Identity(), loaded.Identity())
+		assert.NoDirExists(t, rotationPath(s, "test"))
+		for item := range items {
+			assert.True(t, canDecryptItem(t, s, "test", item, newKey))
+		}
+	})
+}
+
+func TestOSStorageReencryptVaultFailure(t *testing.T) {
+	items := map[string]string{"github": "s3cret", "gitlab": "an0ther"}
+	s, key := newTestVault(t, "test", "password", items)
+	require.NoError(t, s.StoreVaultRecipients("test", key))
+	before, err := os.ReadFile(recipientsPath(s, "test"))
+	require.NoError(t, err)
+
+	// the last item cannot be decrypted, so the re-encryption fails after the first one
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	path := filepath.Join(vaultRootPath(s, "test"), "items", "gitlab.age")
+	require.NoError(t, os.WriteFile(path, encryptMessage(t, other, "an0ther"), 0600))
+
+	member, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, key.AddRecipient(member.Recipient().String()))
+	_, err = s.ReencryptVault("test", key)
+	require.Error(t, err)
+
+	ciphertext, err := os.ReadFile(filepath.Join(vaultRootPath(s, "test"), "items", "github.age"))
+	require.NoError(t, err)
+	_, err = age.Decrypt(bytes.NewReader(ciphertext), member)
+	assert.Error(t, err, "the items must not be re-encrypted partially")
+	assert.Equal(t, "s3cret", readItem(t, s, "test", "github", key))
+	after, err := os.ReadFile(recipientsPath(s, "test"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	assert.NoDirExists(t, rotationPath(s, "test"))
+}
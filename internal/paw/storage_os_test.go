@@ -1,10 +1,10 @@
 package paw
 
 import (
-	"log"
 	"os"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,80 +13,70 @@ func TestStorageOSRoundTrip(t *testing.T) {
 	name := "test"
 	password := "secret"
 
-	root, err := os.MkdirTemp(os.TempDir(), "paw")
+	storage, err := NewOSStorageRooted(t.TempDir())
 	require.NoError(t, err)
-	defer os.RemoveAll(root)
+	s := storage.(*OSStorage)
 
-	storage, err := NewOSStorageRooted(root)
+	// test vault creation
+	key, err := s.CreateVault(name, password, MinWorkFactor)
 	require.NoError(t, err)
+	require.FileExists(t, keyPath(s, name))
+	require.FileExists(t, recipientsPath(s, name))
+	_, err = s.CreateVault(name, password, MinWorkFactor)
+	assert.Error(t, err, "the vault must not be overwritten")
 
-	vaultURI := vaultPath(storage, name)
-	keyURI := keyPath(storage, name)
-
-	log.Println(vaultURI)
-	log.Println(keyURI)
-
-	// test key creation
-	key, err := storage.CreateVaultKey(name, password)
+	// test key info read without unlocking
+	info, err := s.VaultKeyInfo(name)
 	require.NoError(t, err)
+	assert.Equal(t, key.Info().PublicKey, info.PublicKey)
+	assert.Equal(t, MinWorkFactor, info.WorkFactor)
 
-	// test vault creation
-	vault, err := storage.CreateVault(name, key)
+	// test item store and load
+	items, err := s.VaultItems(name, key)
 	require.NoError(t, err)
-	require.Equal(t, name, vault.Name)
+	assert.Empty(t, items)
 
-	require.FileExists(t, vaultURI)
-	require.FileExists(t, keyURI)
-
-	// test item creation for the vault
+	login := NewLogin()
+	login.Name = "example.com/admin"
+	login.Username = "admin"
+	login.Password.Value = "s3cret"
+	require.NoError(t, s.StoreVaultItem(name, key, login))
 	note := NewNote()
-	note.Name = "test note"
+	note.Name = "a note"
 	note.Value = "a secret note"
+	require.NoError(t, s.StoreVaultItem(name, key, note))
 
-	vault.AddItem(note)
-	// add note vault to item
-	meta, ok := vault.ItemMetadata[note.Type][note.ID()]
-	require.True(t, ok)
-	assert.Equal(t, note.Name, meta.Name)
-
-	// store note item
-	err = storage.StoreItem(vault, note)
+	item, err := s.LoadVaultItem(name, key, LoginItemType, "example.com/admin")
 	require.NoError(t, err)
+	assert.Equal(t, "s3cret", item.(*Login).Password.Value)
+	_, err = s.LoadVaultItem(name, key, NoteItemType, "example.com/admin")
+	assert.Error(t, err)
 
-	itemURI := itemPath(storage, name, note.ID())
-	require.FileExists(t, itemURI)
-
-	// test item load for the vault
-	item, err := storage.LoadItem(vault, meta)
+	items, err = s.VaultItems(name, key)
 	require.NoError(t, err)
-	require.NotNil(t, item)
-	assert.Equal(t, note.Name, item.GetMetadata().Name)
+	require.Len(t, items, 2)
+	assert.Equal(t, "a note", items[0].GetMetadata().Name)
+	assert.Equal(t, "example.com/admin", items[1].GetMetadata().Name)
 
-	// test item creation for the vault
-	login := NewLogin()
-	login.Name = "test login"
-	login.Password.Value = "a secret password"
-
-	// add login item to vault
-	vault.AddItem(login)
-	require.Len(t, vault.ItemMetadata, 2) // login and note type
-
-	err = storage.StoreItem(vault, login)
+	// test key load along with the recipients
+	member, err := age.GenerateX25519Identity()
 	require.NoError(t, err)
-
-	loadedVault, err := storage.LoadVault(name, password)
+	_, err = s.LoadVaultKey(name, "wrong")
+	assert.Error(t, err)
+	loaded, err := s.LoadVaultKey(name, password)
 	require.NoError(t, err)
-	require.Equal(t, name, loadedVault.Name)
-	require.Len(t, loadedVault.ItemMetadata, 2) // login and note type
+	assert.Equal(t, key.Identity(), loaded.Identity())
+	require.NoError(t, loaded.AddRecipient(member.Recipient().String()))
 
-	meta, ok = loadedVault.ItemMetadata[login.Type][login.ID()]
-	require.True(t, ok)
-	assert.Equal(t, login.Name, meta.Name)
-
-	itemWebsite, err := storage.LoadItem(vault, meta)
+	n, err := s.ReencryptVault(name, loaded)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	recipients, err := s.VaultRecipients(name)
 	require.NoError(t, err)
-	require.NotNil(t, itemWebsite)
-	assert.Equal(t, login.Name, itemWebsite.GetMetadata().Name)
-	assert.Equal(t, login.Password.Value, itemWebsite.(*Login).Password.Value)
+	assert.Contains(t, recipients, member.Recipient().String())
 
+	// a member reads the items using its own identity, the vault key is not needed
+	ciphertext, err := os.ReadFile(itemPath(s, name, NoteItemType, "a note"))
+	require.NoError(t, err)
+	assert.Contains(t, decryptMessage(t, ciphertext, member), "a secret note")
 }
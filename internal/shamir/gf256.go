@@ -0,0 +1,48 @@
+package shamir
+
+// The arithmetic over GF(256) uses the AES polynomial x^8 + x^4 + x^3 + x + 1 (0x11b).
+// Multiplication and division are implemented using the log and exp tables of the generator 3.
+
+var (
+	logTable [256]byte
+	expTable [510]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		expTable[i+255] = x
+		logTable[x] = byte(i)
+		// multiply by the generator 3, i.e. x*2 + x
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+// add adds a and b, it is also the subtraction
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+// mul multiplies a and b
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// div divides a by b, b must not be zero
+func div(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
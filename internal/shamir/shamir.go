@@ -0,0 +1,272 @@
+// Package shamir implements the Shamir's secret sharing over GF(256).
+// A secret is split into n shares, any threshold of them allows to combine the secret back,
+// while fewer shares reveal nothing about it.
+package shamir
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lucor.dev/paw/internal/age/bech32"
+)
+
+const (
+	// MaxShares is the max number of shares, the x coordinate is a non zero byte
+	MaxShares = 255
+	// minThreshold is the min number of shares required to combine the secret,
+	// a single share would hold the secret itself
+	minThreshold = 2
+
+	// ShareHRP is the human readable part of the bech32 encoded shares
+	ShareHRP = "PAW-SHARE-"
+	// shareVersion is the version of the share encoding
+	shareVersion = 1
+	// shareHeaderLen is the length of the share header: version, ID, threshold and index
+	shareHeaderLen = 5
+	// sharePartsVersion is the version of the share encoding split into parts,
+	// used when the share does not fit into a single bech32 string
+	sharePartsVersion = 2
+	// sharePartHeaderLen is the length of the share part header: the share header, part and parts
+	sharePartHeaderLen = shareHeaderLen + 2
+	// maxDataLen is the max number of bytes of a bech32 string, that is limited to 90 chars
+	maxDataLen = (90 - len(ShareHRP) - 7) * 5 / 8
+	// maxPartValueLen is the max length of the value held by a share part
+	maxPartValueLen = maxDataLen - sharePartHeaderLen
+)
+
+// Share is a share of a secret
+type Share struct {
+	// ID identifies the shares produced by the same split,
+	// so that shares of different secrets are not combined by mistake
+	ID uint16
+	// Threshold is the number of shares required to combine the secret
+	Threshold int
+	// Index is the x coordinate of the share, from 1 to 255
+	Index int
+	// Value is the share value, it has the same length of the secret
+	Value []byte
+}
+
+// Split splits the secret into n shares, threshold of them are required to combine it
+func Split(secret []byte, n int, threshold int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("shamir: the secret cannot be empty")
+	}
+	if threshold < minThreshold {
+		return nil, fmt.Errorf("shamir: the threshold must be at least %d", minThreshold)
+	}
+	if n < threshold {
+		return nil, errors.New("shamir: the number of shares cannot be less than the threshold")
+	}
+	if n > MaxShares {
+		return nil, fmt.Errorf("shamir: the number of shares cannot be greater than %d", MaxShares)
+	}
+
+	b := make([]byte, 2)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	id := binary.BigEndian.Uint16(b)
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{ID: id, Threshold: threshold, Index: i + 1, Value: make([]byte, len(secret))}
+	}
+
+	// a random polynomial of degree threshold-1 is generated for each byte of the secret,
+	// the secret byte is the constant term and each share is a point of the polynomial
+	coefficients := make([]byte, threshold)
+	for j, s := range secret {
+		coefficients[0] = s
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].Value[j] = evaluate(coefficients, byte(shares[i].Index))
+		}
+	}
+	return shares, nil
+}
+
+// Combine combines the shares into the secret.
+// At least threshold shares produced by the same split are required.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir: no shares")
+	}
+	first := shares[0]
+	if first.Threshold < minThreshold {
+		return nil, fmt.Errorf("shamir: invalid threshold %d", first.Threshold)
+	}
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("shamir: %d shares are required, got %d", first.Threshold, len(shares))
+	}
+
+	seen := map[int]bool{}
+	for _, s := range shares {
+		if s.ID != first.ID || s.Threshold != first.Threshold {
+			return nil, errors.New("shamir: the shares belong to different secrets")
+		}
+		if len(s.Value) != len(first.Value) {
+			return nil, errors.New("shamir: the shares have different lengths")
+		}
+		if s.Index < 1 || s.Index > MaxShares {
+			return nil, fmt.Errorf("shamir: invalid share index %d", s.Index)
+		}
+		if seen[s.Index] {
+			return nil, fmt.Errorf("shamir: duplicated share %d", s.Index)
+		}
+		seen[s.Index] = true
+	}
+
+	// only threshold shares are needed to interpolate the polynomial
+	shares = shares[:first.Threshold]
+	secret := make([]byte, len(first.Value))
+	x := make([]byte, len(shares))
+	y := make([]byte, len(shares))
+	for i, s := range shares {
+		x[i] = byte(s.Index)
+	}
+	for j := range secret {
+		for i, s := range shares {
+			y[i] = s.Value[j]
+		}
+		secret[j] = interpolate(x, y)
+	}
+	return secret, nil
+}
+
+// Encode encodes the share as bech32 string, the checksum allows to detect typos
+// when the share is typed back.
+// A share longer than a bech32 string is split into parts separated by a space,
+// each part has its own checksum.
+func (s Share) Encode() (string, error) {
+	if shareHeaderLen+len(s.Value) <= maxDataLen {
+		return encode(s.header(shareVersion), s.Value)
+	}
+
+	parts := (len(s.Value) + maxPartValueLen - 1) / maxPartValueLen
+	if parts > 255 {
+		return "", errors.New("shamir: the share is too long")
+	}
+	values := make([]string, 0, parts)
+	for i := 0; i < parts; i++ {
+		end := (i + 1) * maxPartValueLen
+		if end > len(s.Value) {
+			end = len(s.Value)
+		}
+		header := append(s.header(sharePartsVersion), byte(i+1), byte(parts))
+		v, err := encode(header, s.Value[i*maxPartValueLen:end])
+		if err != nil {
+			return "", err
+		}
+		values = append(values, v)
+	}
+	return strings.Join(values, " "), nil
+}
+
+// header returns the share header for the encoding version
+func (s Share) header(version byte) []byte {
+	header := make([]byte, shareHeaderLen, sharePartHeaderLen)
+	header[0] = version
+	binary.BigEndian.PutUint16(header[1:3], s.ID)
+	header[3] = byte(s.Threshold)
+	header[4] = byte(s.Index)
+	return header
+}
+
+// encode encodes the header and the value as bech32 string
+func encode(header []byte, value []byte) (string, error) {
+	data := make([]byte, 0, len(header)+len(value))
+	data = append(data, header...)
+	data = append(data, value...)
+	v, err := bech32.Encode(ShareHRP, data)
+	if err != nil {
+		return "", fmt.Errorf("shamir: could not encode the share: %w", err)
+	}
+	return v, nil
+}
+
+// ParseShare parses a share encoded by Encode. Spaces and dashes added to make
+// the share readable are ignored.
+func ParseShare(v string) (Share, error) {
+	v = strings.ToUpper(strings.Join(strings.Fields(v), ""))
+	if !strings.HasPrefix(v, ShareHRP) {
+		return Share{}, errors.New("shamir: not a share")
+	}
+
+	var share Share
+	parts := strings.Split(v, ShareHRP)[1:]
+	for i, part := range parts {
+		// the dashes of the human readable part are meaningful
+		hrp, data, err := bech32.Decode(ShareHRP + strings.ReplaceAll(part, "-", ""))
+		if err != nil {
+			return Share{}, fmt.Errorf("shamir: invalid share: %w", err)
+		}
+		if hrp != ShareHRP || len(data) <= shareHeaderLen {
+			return Share{}, errors.New("shamir: invalid share")
+		}
+		s := Share{
+			ID:        binary.BigEndian.Uint16(data[1:3]),
+			Threshold: int(data[3]),
+			Index:     int(data[4]),
+		}
+		if s.Threshold < minThreshold {
+			return Share{}, fmt.Errorf("shamir: invalid share threshold %d", s.Threshold)
+		}
+
+		switch data[0] {
+		case shareVersion:
+			if len(parts) != 1 {
+				return Share{}, errors.New("shamir: invalid share, unexpected parts")
+			}
+			s.Value = data[shareHeaderLen:]
+			return s, nil
+		case sharePartsVersion:
+			if len(data) <= sharePartHeaderLen {
+				return Share{}, errors.New("shamir: invalid share part")
+			}
+			if int(data[5]) != i+1 || int(data[6]) != len(parts) {
+				return Share{}, fmt.Errorf("shamir: share part %d of %d is missing or out of order", i+1, data[6])
+			}
+			if i > 0 && (s.ID != share.ID || s.Threshold != share.Threshold || s.Index != share.Index) {
+				return Share{}, errors.New("shamir: the share parts belong to different shares")
+			}
+			s.Value = append(share.Value, data[sharePartHeaderLen:]...)
+			share = s
+		default:
+			return Share{}, fmt.Errorf("shamir: unsupported share version %d", data[0])
+		}
+	}
+	return share, nil
+}
+
+// evaluate evaluates the polynomial at x using the Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = add(mul(y, x), coefficients[i])
+	}
+	return y
+}
+
+// interpolate returns the value at 0 of the polynomial passing by the points (x, y)
+// using the Lagrange interpolation
+func interpolate(x []byte, y []byte) byte {
+	var secret byte
+	for i := range x {
+		basis := byte(1)
+		for j := range x {
+			if i == j {
+				continue
+			}
+			// x[j] / (x[j] - x[i]), subtraction is the same of addition in GF(256)
+			basis = mul(basis, div(x[j], add(x[j], x[i])))
+		}
+		secret = add(secret, mul(y[i], basis))
+	}
+	return secret
+}
@@ -0,0 +1,168 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			p := mul(byte(a), byte(b))
+			assert.Equal(t, byte(a), div(p, byte(b)), "%d * %d / %d", a, b, b)
+		}
+	}
+	// known AES values
+	assert.Equal(t, byte(0xc1), mul(0x57, 0x83))
+	assert.Equal(t, byte(0xfe), mul(0x57, 0x13))
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		n         int
+		threshold int
+	}{
+		{n: 2, threshold: 2},
+		{n: 3, threshold: 2},
+		{n: 5, threshold: 3},
+		{n: 255, threshold: 10},
+	}
+	for _, tt := range tests {
+		shares, err := Split(secret, tt.n, tt.threshold)
+		require.NoError(t, err)
+		require.Len(t, shares, tt.n)
+
+		// any threshold shares combine the secret
+		got, err := Combine(shares[tt.n-tt.threshold:])
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+
+		got, err = Combine(shares)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+
+		// fewer shares are rejected
+		_, err = Combine(shares[:tt.threshold-1])
+		assert.Error(t, err)
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	_, err := Split(nil, 3, 2)
+	assert.Error(t, err)
+	_, err = Split([]byte("secret"), 3, 1)
+	assert.Error(t, err)
+	_, err = Split([]byte("secret"), 2, 3)
+	assert.Error(t, err)
+	_, err = Split([]byte("secret"), 256, 3)
+	assert.Error(t, err)
+}
+
+func TestCombineInvalid(t *testing.T) {
+	s1, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+	s2, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+	s2[1].ID = s1[0].ID + 1
+
+	_, err = Combine([]Share{s1[0], s2[1]})
+	assert.Error(t, err, "different secrets")
+	_, err = Combine([]Share{s1[0], s1[0]})
+	assert.Error(t, err, "duplicated shares")
+	_, err = Combine(nil)
+	assert.Error(t, err)
+
+	for _, threshold := range []int{0, 1} {
+		s := s1[0]
+		s.Threshold = threshold
+		_, err = Combine([]Share{s})
+		assert.Error(t, err, "threshold %d", threshold)
+	}
+}
+
+func TestShareEncoding(t *testing.T) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	shares, err := Split(secret, 3, 2)
+	require.NoError(t, err)
+
+	var parsed []Share
+	for _, s := range shares {
+		v, err := s.Encode()
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(v, "PAW-SHARE-1"), v)
+
+		// typed back in lower case and with spaces
+		p, err := ParseShare(" " + strings.ToLower(v[:20]) + " " + strings.ToLower(v[20:]) + "\n")
+		require.NoError(t, err)
+		assert.Equal(t, s, p)
+		parsed = append(parsed, p)
+	}
+	got, err := Combine(parsed[1:])
+	require.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	v, err := shares[0].Encode()
+	require.NoError(t, err)
+	typo := v[:len(v)-1] + "Q"
+	if typo == v {
+		typo = v[:len(v)-1] + "P"
+	}
+	_, err = ParseShare(typo)
+	assert.Error(t, err, "the checksum detects typos")
+	_, err = ParseShare("AGE-SECRET-KEY-1")
+	assert.Error(t, err)
+
+	for _, threshold := range []int{0, 1} {
+		s := shares[0]
+		s.Threshold = threshold
+		v, err := s.Encode()
+		require.NoError(t, err)
+		_, err = ParseShare(v)
+		assert.Error(t, err, "threshold %d", threshold)
+	}
+}
+
+func TestShareEncodingParts(t *testing.T) {
+	secret := []byte(strings.Repeat("correct horse battery staple ", 4))
+	shares, err := Split(secret, 3, 2)
+	require.NoError(t, err)
+
+	v, err := shares[0].Encode()
+	require.NoError(t, err)
+	parts := strings.Fields(v)
+	require.Len(t, parts, 4)
+	for _, part := range parts {
+		assert.True(t, strings.HasPrefix(part, "PAW-SHARE-1"), part)
+		assert.LessOrEqual(t, len(part), 90)
+	}
+
+	p, err := ParseShare(strings.Join(parts, "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, shares[0], p)
+
+	_, err = ParseShare(strings.Join(parts[1:], " "))
+	assert.Error(t, err, "missing part")
+	_, err = ParseShare(parts[1] + " " + parts[0] + " " + strings.Join(parts[2:], " "))
+	assert.Error(t, err, "parts out of order")
+
+	w, err := shares[1].Encode()
+	require.NoError(t, err)
+	_, err = ParseShare(parts[0] + " " + strings.Fields(w)[1] + " " + strings.Join(parts[2:], " "))
+	assert.Error(t, err, "parts of different shares")
+
+	s2, err := ParseShare(w)
+	require.NoError(t, err)
+	got, err := Combine([]Share{p, s2})
+	require.NoError(t, err)
+	assert.Equal(t, secret, got)
+}
@@ -0,0 +1,58 @@
+package sshagent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// confirmMu serializes the confirmation requests
+var confirmMu sync.Mutex
+
+// Confirm asks the user to confirm the use of the key.
+// The program specified by the SSH_ASKPASS environment variable is used, if set,
+// otherwise the question is asked on the controlling terminal.
+// The use is denied if the user cannot be asked.
+func Confirm(key *paw.SSHKey) bool {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+
+	prompt := fmt.Sprintf("Allow use of key %s?\nKey fingerprint %s.", key.Name, key.Fingerprint)
+	if askpass := os.Getenv("SSH_ASKPASS"); askpass != "" {
+		return askpassConfirm(askpass, prompt)
+	}
+	return terminalConfirm(prompt)
+}
+
+// askpassConfirm asks the confirmation using the ssh-askpass program.
+// The program exits with zero status if the use is allowed.
+func askpassConfirm(askpass string, prompt string) bool {
+	cmd := exec.Command(askpass, prompt)
+	cmd.Env = append(os.Environ(), "SSH_ASKPASS_PROMPT=confirm")
+	return cmd.Run() == nil
+}
+
+// terminalConfirm asks the confirmation on the controlling terminal
+func terminalConfirm(prompt string) bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(tty, "%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
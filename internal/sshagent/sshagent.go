@@ -0,0 +1,257 @@
+// Package sshagent implements an ssh-agent that serves the SSH keys stored
+// into the vaults. Keys never touch the disk and each signature requires
+// the user confirmation.
+package sshagent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	pawagent "lucor.dev/paw/internal/agent"
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to ExtendedAgent interface
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+var (
+	errLocked      = errors.New("agent: locked")
+	errReadOnly    = errors.New("agent: keys are managed by the vault")
+	errNotFound    = errors.New("agent: key not found")
+	errNotAllowed  = errors.New("agent: signature not allowed")
+	errUnsupported = errors.New("agent: unsupported operation")
+)
+
+// ConfirmFunc asks the user to confirm the use of the key to sign.
+// It returns true if the use is allowed.
+type ConfirmFunc func(key *paw.SSHKey) bool
+
+// Agent is an ssh-agent serving the SSH keys from the vaults
+type Agent struct {
+	vaults  []azure.Vault
+	confirm ConfirmFunc
+
+	mu         sync.Mutex
+	locked     bool
+	passphrase []byte
+}
+
+// New returns an agent serving the SSH keys from vaults.
+// When confirm is not nil it is called before each signature.
+func New(vaults []azure.Vault, confirm ConfirmFunc) *Agent {
+	return &Agent{
+		vaults:  vaults,
+		confirm: confirm,
+	}
+}
+
+// List returns the identities known to the agent
+func (a *Agent) List() ([]*agent.Key, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.locked {
+		return nil, nil
+	}
+
+	keys, err := a.keys()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*agent.Key, 0, len(keys))
+	for _, k := range keys {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &agent.Key{
+			Format:  pub.Type(),
+			Blob:    pub.Marshal(),
+			Comment: k.Name,
+		})
+	}
+	return list, nil
+}
+
+// Sign has the agent sign the data using a protocol 2 key as defined
+// in [PROTOCOL.agent] section 2.6.2.
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags signs like Sign, but allows for additional flags to be sent/received
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.locked {
+		return nil, errLocked
+	}
+
+	keys, err := a.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := key.Marshal()
+	for _, k := range keys {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pub.Marshal(), wanted) {
+			continue
+		}
+
+		if !azure.HasValue(k.SSHKey) {
+			// only the private key used to sign is loaded from the vault
+			item, err := k.vault.GetItem(k.SSHKey)
+			if err != nil {
+				return nil, err
+			}
+			k.SSHKey = item.(*paw.SSHKey)
+		}
+		signer, err := k.Signer()
+		if err != nil {
+			return nil, err
+		}
+
+		if a.confirm != nil && !a.confirm(k.SSHKey) {
+			return nil, errNotAllowed
+		}
+
+		if flags == 0 {
+			return signer.Sign(rand.Reader, data)
+		}
+		algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+		if !ok {
+			return nil, fmt.Errorf("agent: signature does not support non-default signature algorithm: %T", signer)
+		}
+		var algorithm string
+		switch flags {
+		case agent.SignatureFlagRsaSha256:
+			algorithm = ssh.SigAlgoRSASHA2256
+		case agent.SignatureFlagRsaSha512:
+			algorithm = ssh.SigAlgoRSASHA2512
+		default:
+			return nil, fmt.Errorf("agent: unsupported signature flags: %d", flags)
+		}
+		return algorithmSigner.SignWithAlgorithm(rand.Reader, data, algorithm)
+	}
+	return nil, errNotFound
+}
+
+// Add is not supported, keys are managed by the vault
+func (a *Agent) Add(key agent.AddedKey) error {
+	return errReadOnly
+}
+
+// Remove is not supported, keys are managed by the vault
+func (a *Agent) Remove(key ssh.PublicKey) error {
+	return errReadOnly
+}
+
+// RemoveAll is not supported, keys are managed by the vault
+func (a *Agent) RemoveAll() error {
+	return errReadOnly
+}
+
+// Lock locks the agent. Sign and List will fail until Unlock is called
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.locked {
+		return errLocked
+	}
+	a.locked = true
+	a.passphrase = passphrase
+	return nil
+}
+
+// Unlock undoes the effect of Lock
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.locked {
+		return errors.New("agent: not locked")
+	}
+	if !bytes.Equal(passphrase, a.passphrase) {
+		return errors.New("agent: incorrect passphrase")
+	}
+	a.locked = false
+	a.passphrase = nil
+	return nil
+}
+
+// Signers is not supported, signatures must go through Sign to be confirmed
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return nil, errUnsupported
+}
+
+// Extension is not supported
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// vaultKey is an SSH key along with the vault holding it
+type vaultKey struct {
+	*paw.SSHKey
+	vault azure.Vault
+}
+
+// keys returns the SSH keys from all the vaults. The private keys are not loaded,
+// except for the keys stored without the public key tag that must be loaded to know it.
+func (a *Agent) keys() ([]vaultKey, error) {
+	var keys []vaultKey
+	var err error
+	for _, vault := range a.vaults {
+		v := vault
+		v.Range(func(name string, item paw.Item) bool {
+			key, ok := item.(*paw.SSHKey)
+			if !ok {
+				return true
+			}
+			if key.PublicKey == "" {
+				var loaded paw.Item
+				loaded, err = v.GetItem(item)
+				if err != nil {
+					return false
+				}
+				key = loaded.(*paw.SSHKey)
+			}
+			keys = append(keys, vaultKey{SSHKey: key, vault: v})
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// ListenAndServe serves the agent on the Unix socket path, accessible only by
+// the current user
+func ListenAndServe(path string, a agent.Agent) error {
+	l, err := pawagent.ListenUnix(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			agent.ServeAgent(a, conn)
+		}()
+	}
+}
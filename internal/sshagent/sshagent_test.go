@@ -0,0 +1,74 @@
+package sshagent
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// keyVault is an azure.Vault holding a single SSH key used for testing
+type keyVault struct {
+	azure.Vault
+	key *paw.SSHKey
+	// loaded counts the private key loads
+	loaded int
+}
+
+func (v *keyVault) GetItem(secret paw.Item) (paw.Item, error) {
+	v.loaded++
+	return v.key, nil
+}
+
+// Range yields the key as listed from the vault, i.e. without the private key
+func (v *keyVault) Range(f func(name string, item paw.Item) bool) {
+	item, _ := azure.WithoutValue(v.key)
+	f(v.key.Name, item)
+}
+
+func TestAgent(t *testing.T) {
+	key := paw.NewSSHKey()
+	key.Name = "deploy"
+	require.NoError(t, key.Generate(paw.ED25519SSHKey, 0))
+
+	allow := false
+	vault := &keyVault{key: key}
+	a := New([]azure.Vault{vault}, func(k *paw.SSHKey) bool {
+		assert.Equal(t, key, k)
+		return allow
+	})
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	go agent.ServeAgent(a, c2)
+	client := agent.NewClient(c1)
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "deploy", keys[0].Comment)
+	assert.Equal(t, key.AuthorizedKey(), keys[0].String())
+	assert.Zero(t, vault.loaded, "the private key must not be loaded to list")
+
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.PublicKey))
+	require.NoError(t, err)
+
+	data := []byte("data to sign")
+	_, err = client.Sign(pub, data)
+	assert.Error(t, err, "signature must be confirmed")
+
+	allow = true
+	sig, err := client.Sign(pub, data)
+	require.NoError(t, err)
+	assert.NoError(t, pub.Verify(data, sig))
+	assert.Equal(t, 2, vault.loaded, "the private key must be loaded only to sign")
+
+	err = client.Add(agent.AddedKey{PrivateKey: nil})
+	assert.Error(t, err)
+}
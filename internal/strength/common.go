@@ -0,0 +1,27 @@
+package strength
+
+import "strings"
+
+// commonPasswords is a list of the most common passwords sorted by frequency,
+// compiled from the public password leaks statistics.
+var commonPasswords = strings.Fields(`
+123456 password 123456789 12345678 12345 qwerty 1234567 111111 1234567890 123123
+abc123 1234 password1 iloveyou 1q2w3e4r 000000 qwerty123 zaq12wsx dragon sunshine
+princess letmein 654321 monkey 27653 1qaz2wsx 123321 qwertyuiop superman asdfghjkl
+football baseball welcome admin master shadow michael trustno1 jennifer hunter
+login starwars solo passw0rd freedom whatever qazwsx ninja mustang access
+flower hello charlie donald batman 696969 hottie loveme zxcvbnm 121212
+666666 555555 7777777 888888 112233 123654 987654321 159753 987654 11111111
+aa123456 1111 123qwe qwe123 qweasdzxc a123456 123abc asdasd asdf1234 pass
+password123 password12 test test123 guest root changeme secret default temp
+computer internet google samsung iphone apple cookie summer winter spring
+autumn monday friday killer soccer hockey tigger pepper ginger jordan
+thomas robert daniel jessica ashley andrew joshua matthew amanda michelle
+nicole chelsea liverpool arsenal barcelona pokemon naruto matrix mercedes ferrari
+corvette harley yankees cowboys dallas chicago london paris london123 qwerty1
+q1w2e3r4 q1w2e3r4t5 1q2w3e 1qazxsw2 zxcvbn asdfgh asdf qwer 147258369 147258
+789456123 789456 456789 135792468 102030 010203 101010 123456a 123456q
+iloveyou1 lovely love baby angel sweety purple orange yellow silver
+diamond golden buster maggie bailey tiger lucky jesus blessed heaven
+letmein1 welcome1 welcome123 admin123 administrator root123 toor abcd1234 abcdef
+`)
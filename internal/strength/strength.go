@@ -0,0 +1,644 @@
+// Package strength estimates the strength of the passwords.
+//
+// The estimation decomposes the password into the patterns an attacker would
+// try first: common passwords and dictionary words, keyboard sequences,
+// alphabetical and numerical sequences, repeats and dates. The remaining chars
+// are estimated as brute force. The decomposition with the lowest entropy wins.
+package strength
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"lucor.dev/paw/internal/age"
+)
+
+const (
+	// guessesPerSecond is the attack rate used to estimate the crack time,
+	// an offline attack against a slow hash function
+	guessesPerSecond = 1e4
+	// maxLength is the max number of chars matched against the patterns,
+	// the exceeding ones are estimated as brute force
+	maxLength = 128
+)
+
+// Pattern names
+const (
+	BruteforcePattern = "bruteforce"
+	DictionaryPattern = "dictionary"
+	SpatialPattern    = "spatial"
+	SequencePattern   = "sequence"
+	RepeatPattern     = "repeat"
+	DatePattern       = "date"
+)
+
+// Dictionary names
+const (
+	CommonPasswordsDictionary = "common passwords"
+	WordlistDictionary        = "wordlist"
+	UserInputsDictionary      = "user inputs"
+)
+
+// Match is a part of the password matching a pattern
+type Match struct {
+	Pattern string
+	// Dictionary is the dictionary name for the dictionary matches
+	Dictionary string
+	Token      string
+	// I and J are the first and the last char index of the token
+	I, J int
+	// Entropy is the entropy in bits of the token
+	Entropy float64
+}
+
+// Result is the password strength estimation
+type Result struct {
+	// Score is the strength from 0, very weak, to 4, very strong
+	Score int
+	// Entropy is the estimated entropy in bits
+	Entropy float64
+	// Matches is the decomposition of the password with the lowest entropy
+	Matches []*Match
+}
+
+// Estimate estimates the strength of the password.
+// The user inputs, like the item name or the username, are matched as dictionary words.
+func Estimate(password string, userInputs ...string) *Result {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return &Result{}
+	}
+
+	matched := runes
+	if len(matched) > maxLength {
+		matched = matched[:maxLength]
+	}
+	var matches []*Match
+	matches = append(matches, dictionaryMatches(matched, userInputs)...)
+	matches = append(matches, spatialMatches(matched)...)
+	matches = append(matches, sequenceMatches(matched)...)
+	matches = append(matches, repeatMatches(matched)...)
+	matches = append(matches, dateMatches(matched)...)
+
+	r := &Result{}
+	r.Matches, r.Entropy = minimumEntropy(runes, matches)
+	r.Score = score(r.Entropy)
+	return r
+}
+
+// minimumEntropy returns the sequence of matches covering the password with the lowest entropy.
+// The chars not covered by any match are estimated as brute force.
+func minimumEntropy(runes []rune, matches []*Match) ([]*Match, float64) {
+	n := len(runes)
+	bf := math.Log2(float64(cardinality(runes)))
+
+	// best[k] is the lowest entropy of the first k chars, last[k] the match ending at k-1
+	best := make([]float64, n+1)
+	last := make([]*Match, n+1)
+	byEnd := make([][]*Match, n)
+	for _, m := range matches {
+		byEnd[m.J] = append(byEnd[m.J], m)
+	}
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + bf
+		last[k] = nil
+		for _, m := range byEnd[k-1] {
+			if e := best[m.I] + m.Entropy; e < best[k] {
+				best[k] = e
+				last[k] = m
+			}
+		}
+	}
+
+	// walk back merging the adjacent brute force chars
+	var result []*Match
+	for k := n; k > 0; {
+		if m := last[k]; m != nil {
+			result = append(result, m)
+			k = m.I
+			continue
+		}
+		j := k - 1
+		for k > 0 && last[k] == nil {
+			k--
+		}
+		result = append(result, &Match{
+			Pattern: BruteforcePattern,
+			Token:   string(runes[k : j+1]),
+			I:       k,
+			J:       j,
+			Entropy: float64(j-k+1) * bf,
+		})
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, best[n]
+}
+
+// cardinality returns the size of the char classes used by the password
+func cardinality(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r > ' ' && r <= '~':
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	c := 0
+	if lower {
+		c += 26
+	}
+	if upper {
+		c += 26
+	}
+	if digit {
+		c += 10
+	}
+	if symbol {
+		c += 33
+	}
+	if other {
+		c += 100
+	}
+	return c
+}
+
+// score returns the score for the entropy using the thresholds of
+// 10^3, 10^6, 10^8 and 10^10 guesses
+func score(entropy float64) int {
+	guesses := math.Pow(2, entropy)
+	switch {
+	case guesses < 1e3:
+		return 0
+	case guesses < 1e6:
+		return 1
+	case guesses < 1e8:
+		return 2
+	case guesses < 1e10:
+		return 3
+	}
+	return 4
+}
+
+// Label returns a human readable label for the score
+func (r *Result) Label() string {
+	return [...]string{"Very weak", "Weak", "Fair", "Strong", "Very strong"}[r.Score]
+}
+
+// CrackTimeSeconds returns the estimated seconds to crack the password
+// with an offline attack against a slow hash function
+func (r *Result) CrackTimeSeconds() float64 {
+	// on average the password is found after half the guesses
+	return math.Pow(2, r.Entropy) / 2 / guessesPerSecond
+}
+
+// CrackTimeText returns the estimated crack time as human readable text
+func (r *Result) CrackTimeText() string {
+	s := r.CrackTimeSeconds()
+	const (
+		minute = 60
+		hour   = minute * 60
+		day    = hour * 24
+		month  = day * 31
+		year   = month * 12
+	)
+	units := []struct {
+		name string
+		size float64
+	}{
+		{"year", year},
+		{"month", month},
+		{"day", day},
+		{"hour", hour},
+		{"minute", minute},
+		{"second", 1},
+	}
+	switch {
+	case s < 1:
+		return "less than a second"
+	case s >= 100*year:
+		return "centuries"
+	}
+	for _, u := range units {
+		if s >= u.size {
+			n := int(math.Round(s / u.size))
+			if n == 1 {
+				return fmt.Sprintf("1 %s", u.name)
+			}
+			return fmt.Sprintf("%d %ss", n, u.name)
+		}
+	}
+	return "less than a second"
+}
+
+// Warning returns a warning about the weakest pattern found, if any
+func (r *Result) Warning() string {
+	if r.Score > 2 {
+		return ""
+	}
+	for _, m := range r.Matches {
+		switch m.Pattern {
+		case DictionaryPattern:
+			switch m.Dictionary {
+			case CommonPasswordsDictionary:
+				return "This is similar to a commonly used password"
+			case UserInputsDictionary:
+				return "Avoid the item name and the username"
+			}
+			return "A word is easy to guess"
+		case SpatialPattern:
+			return "Keyboard sequences are easy to guess"
+		case SequencePattern:
+			return "Sequences like abc or 6543 are easy to guess"
+		case RepeatPattern:
+			return "Repeats like aaa or abcabc are easy to guess"
+		case DatePattern:
+			return "Dates are easy to guess"
+		}
+	}
+	return "Add more chars"
+}
+
+// dictionary is a ranked list of words
+type dictionary struct {
+	name  string
+	ranks map[string]int
+}
+
+// fixedRankDictionary returns a dictionary where all the words have the same rank
+func fixedRankDictionary(name string, words []string) *dictionary {
+	d := &dictionary{name: name, ranks: make(map[string]int, len(words))}
+	for _, w := range words {
+		d.ranks[strings.ToLower(w)] = len(words)
+	}
+	return d
+}
+
+// rankedDictionary returns a dictionary where the words are ranked by their position
+func rankedDictionary(name string, words []string) *dictionary {
+	d := &dictionary{name: name, ranks: make(map[string]int, len(words))}
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if _, ok := d.ranks[w]; !ok {
+			d.ranks[w] = i + 1
+		}
+	}
+	return d
+}
+
+var (
+	commonDictionary   = rankedDictionary(CommonPasswordsDictionary, commonPasswords)
+	wordlistDictionary = fixedRankDictionary(WordlistDictionary, age.Wordlist())
+)
+
+// userInputsDictionary returns the dictionary of the user inputs and of their words
+func userInputsDictionary(inputs []string) *dictionary {
+	var words []string
+	for _, input := range inputs {
+		words = append(words, input)
+		words = append(words, strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return rankedDictionary(UserInputsDictionary, words)
+}
+
+// l33t maps the common substitutions to the letters
+var l33t = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '3': 'e', '6': 'g', '1': 'i', '!': 'i',
+	'0': 'o', '$': 's', '5': 's', '7': 't', '2': 'z',
+}
+
+// unleet returns the token with the l33t substitutions reverted and their number.
+// When l is true the digit 1 is replaced by l instead of i.
+func unleet(token []rune, l bool) (string, int) {
+	subs := 0
+	out := make([]rune, len(token))
+	for i, r := range token {
+		out[i] = r
+		if s, ok := l33t[r]; ok {
+			if r == '1' && l {
+				s = 'l'
+			}
+			out[i] = s
+			subs++
+		}
+	}
+	return string(out), subs
+}
+
+// dictionaryMatches returns the tokens found into the dictionaries
+func dictionaryMatches(runes []rune, userInputs []string) []*Match {
+	dicts := []*dictionary{commonDictionary, wordlistDictionary}
+	if len(userInputs) > 0 {
+		dicts = append(dicts, userInputsDictionary(userInputs))
+	}
+	var matches []*Match
+	for i := 0; i < len(runes); i++ {
+		for j := i + 2; j < len(runes); j++ {
+			token := runes[i : j+1]
+			lower := []rune(strings.ToLower(string(token)))
+			caseBits := uppercaseEntropy(token)
+			variants := map[string]int{string(lower): 0}
+			for _, l := range []bool{false, true} {
+				if v, subs := unleet(lower, l); subs > 0 {
+					variants[v] = subs
+				}
+			}
+			for _, d := range dicts {
+				best := math.Inf(1)
+				for v, subs := range variants {
+					rank, ok := d.ranks[v]
+					if !ok {
+						continue
+					}
+					e := math.Log2(float64(rank)) + caseBits + float64(subs)
+					if subs == len(token) {
+						// all the chars substituted, i.e. 1234 as "ieza", are not words
+						continue
+					}
+					best = math.Min(best, e)
+				}
+				if !math.IsInf(best, 1) {
+					matches = append(matches, &Match{
+						Pattern:    DictionaryPattern,
+						Dictionary: d.name,
+						Token:      string(token),
+						I:          i,
+						J:          j,
+						Entropy:    best,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// uppercaseEntropy returns the entropy added by the uppercase letters of the token
+func uppercaseEntropy(token []rune) float64 {
+	var upper, lower int
+	for _, r := range token {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 0
+	case lower == 0, upper == 1 && unicode.IsUpper(token[0]):
+		// all uppercase or capitalized
+		return 1
+	}
+	return float64(upper)
+}
+
+// keyboardRows are the rows of the US keyboard, unshifted and shifted
+var keyboardRows = [][2]string{
+	{"`1234567890-=", "~!@#$%^&*()_+"},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{"asdfghjkl;'", "ASDFGHJKL:\""},
+	{"zxcvbnm,./", "ZXCVBNM<>?"},
+}
+
+// keyPosition is the position of a key on the keyboard
+type keyPosition struct {
+	row, col int
+	shifted  bool
+}
+
+var keyPositions = func() map[rune]keyPosition {
+	m := map[rune]keyPosition{}
+	for row, keys := range keyboardRows {
+		for col, r := range keys[0] {
+			m[r] = keyPosition{row: row, col: col}
+		}
+		for col, r := range keys[1] {
+			m[r] = keyPosition{row: row, col: col, shifted: true}
+		}
+	}
+	return m
+}()
+
+// spatialMatches returns the sequences of adjacent keys on the same keyboard row
+func spatialMatches(runes []rune) []*Match {
+	var matches []*Match
+	for i := 0; i < len(runes)-2; {
+		start, ok := keyPositions[runes[i]]
+		if !ok {
+			i++
+			continue
+		}
+		j, dir, shifted := i, 0, start.shifted
+		for j+1 < len(runes) {
+			cur := keyPositions[runes[j]]
+			next, ok := keyPositions[runes[j+1]]
+			if !ok || next.row != cur.row {
+				break
+			}
+			d := next.col - cur.col
+			if (d != 1 && d != -1) || (dir != 0 && d != dir) {
+				break
+			}
+			dir = d
+			shifted = shifted || next.shifted
+			j++
+		}
+		if j-i >= 2 {
+			n := j - i + 1
+			// the starting key, the direction and the length
+			e := math.Log2(float64(len(keyPositions)/2)) + 1 + math.Log2(float64(n))
+			if shifted {
+				e++
+			}
+			matches = append(matches, &Match{Pattern: SpatialPattern, Token: string(runes[i : j+1]), I: i, J: j, Entropy: e})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// sequenceClass returns the size of the class of the char for the sequences
+func sequenceClass(r rune) (int, bool) {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return 26, true
+	case r >= '0' && r <= '9':
+		return 10, true
+	}
+	return 0, false
+}
+
+// sequenceMatches returns the alphabetical and numerical sequences, i.e. abc or 9876
+func sequenceMatches(runes []rune) []*Match {
+	var matches []*Match
+	for i := 0; i < len(runes)-2; {
+		size, ok := sequenceClass(runes[i])
+		if !ok {
+			i++
+			continue
+		}
+		j := i
+		delta := runes[i+1] - runes[i]
+		if delta == 1 || delta == -1 {
+			for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+				if s, ok := sequenceClass(runes[j+1]); !ok || s != size {
+					break
+				}
+				j++
+			}
+		}
+		if j-i >= 2 {
+			n := j - i + 1
+			e := math.Log2(float64(size)) + math.Log2(float64(n))
+			if delta == -1 {
+				e++
+			}
+			matches = append(matches, &Match{Pattern: SequencePattern, Token: string(runes[i : j+1]), I: i, J: j, Entropy: e})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// repeatMatches returns the repeated chars and chunks, i.e. aaa or abcabc
+func repeatMatches(runes []rune) []*Match {
+	var matches []*Match
+	for i := 0; i < len(runes); i++ {
+		for size := 1; i+2*size <= len(runes); size++ {
+			chunk := string(runes[i : i+size])
+			count := 1
+			for k := i + size; k+size <= len(runes) && string(runes[k:k+size]) == chunk; k += size {
+				count++
+			}
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+			j := i + size*count - 1
+			// the chunk is estimated as brute force
+			e := float64(size)*math.Log2(float64(cardinality(runes[i:i+size]))) + math.Log2(float64(count))
+			matches = append(matches, &Match{Pattern: RepeatPattern, Token: string(runes[i : j+1]), I: i, J: j, Entropy: e})
+		}
+	}
+	return matches
+}
+
+const (
+	minYear = 1900
+	maxYear = 2039
+)
+
+// dateMatches returns the dates and the years, i.e. 1984, 13/05/1984 or 19840513
+func dateMatches(runes []rune) []*Match {
+	var matches []*Match
+	for i := 0; i < len(runes); i++ {
+		for j := i + 3; j < len(runes) && j-i < 10; j++ {
+			token := string(runes[i : j+1])
+			e, ok := dateEntropy(token)
+			if !ok {
+				continue
+			}
+			matches = append(matches, &Match{Pattern: DatePattern, Token: token, I: i, J: j, Entropy: e})
+		}
+	}
+	return matches
+}
+
+// dateEntropy returns the entropy of the token if it is a date or a year
+func dateEntropy(token string) (float64, bool) {
+	years := math.Log2(maxYear - minYear + 1)
+	days := math.Log2(31 * 12)
+
+	parts := strings.FieldsFunc(token, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '_' || r == ' ' || r == '\\'
+	})
+	for _, p := range parts {
+		if strings.TrimLeft(p, "0123456789") != "" {
+			return 0, false
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		switch len(token) {
+		case 4:
+			if isYear(token) {
+				return years, true
+			}
+		case 6, 8:
+			yearLen := len(token) - 4
+			// yyyymmdd, ddmmyyyy, mmddyyyy or the two digits year variants
+			candidates := [][3]string{
+				{token[:yearLen], token[yearLen : yearLen+2], token[yearLen+2:]},
+				{token[4:], token[2:4], token[:2]},
+				{token[4:], token[:2], token[2:4]},
+			}
+			for _, c := range candidates {
+				if isDate(c[0], c[1], c[2]) {
+					return years + days, true
+				}
+			}
+		}
+	case 3:
+		if len(token) != len(parts[0])+len(parts[1])+len(parts[2])+2 {
+			return 0, false
+		}
+		// separators must be the same
+		sep := token[len(parts[0])]
+		if token[len(parts[0])+1+len(parts[1])] != sep {
+			return 0, false
+		}
+		if isDate(parts[0], parts[1], parts[2]) || isDate(parts[2], parts[1], parts[0]) || isDate(parts[2], parts[0], parts[1]) {
+			// the separator
+			return years + days + 2, true
+		}
+	}
+	return 0, false
+}
+
+// isYear reports whether s is a four digits year in the supported range
+func isYear(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	y := atoi(s)
+	return y >= minYear && y <= maxYear
+}
+
+// isDate reports whether year, month and day make a valid date.
+// Years can have two or four digits.
+func isDate(year, month, day string) bool {
+	if len(year) != 2 && !isYear(year) {
+		return false
+	}
+	if len(month) == 0 || len(month) > 2 || len(day) == 0 || len(day) > 2 {
+		return false
+	}
+	m, d := atoi(month), atoi(day)
+	return m >= 1 && m <= 12 && d >= 1 && d <= 31
+}
+
+func atoi(s string) int {
+	n := 0
+	for _, r := range s {
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package strength
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		password   string
+		userInputs []string
+		maxScore   int
+		minScore   int
+		// pattern is the pattern expected into the matches
+		pattern string
+	}{
+		{password: "password", maxScore: 0, pattern: DictionaryPattern},
+		{password: "P@ssw0rd", maxScore: 1, pattern: DictionaryPattern},
+		{password: "qwertyuiop", maxScore: 1, pattern: DictionaryPattern},
+		{password: "zxcvbnm,./", maxScore: 1, pattern: SpatialPattern},
+		{password: "abcdefghij", maxScore: 1, pattern: SequencePattern},
+		{password: "9876543210", maxScore: 1, pattern: SequencePattern},
+		{password: "aaaaaaaaaa", maxScore: 0, pattern: RepeatPattern},
+		{password: "xyzxyzxyzxyz", maxScore: 1, pattern: RepeatPattern},
+		{password: "13/05/1984", maxScore: 2, pattern: DatePattern},
+		{password: "19840513", maxScore: 2, pattern: DatePattern},
+		{password: "acme2021", userInputs: []string{"acme.example.com"}, maxScore: 1, pattern: DictionaryPattern},
+		{password: "correct-horse-battery-staple", minScore: 4, pattern: DictionaryPattern},
+		{password: "Tr0ub4dor&3", minScore: 2},
+		{password: "k#9Lq!2vZ@x7Wm$4", minScore: 4, pattern: BruteforcePattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			r := Estimate(tt.password, tt.userInputs...)
+			if tt.maxScore > 0 || tt.minScore == 0 {
+				assert.LessOrEqual(t, r.Score, tt.maxScore, "entropy %f", r.Entropy)
+			}
+			assert.GreaterOrEqual(t, r.Score, tt.minScore, "entropy %f", r.Entropy)
+
+			// the matches cover the whole password
+			require.NotEmpty(t, r.Matches)
+			token, found := "", false
+			for i, m := range r.Matches {
+				token += m.Token
+				if i > 0 {
+					assert.Equal(t, r.Matches[i-1].J+1, m.I)
+				}
+				found = found || m.Pattern == tt.pattern
+			}
+			assert.Equal(t, tt.password, token)
+			if tt.pattern != "" {
+				assert.True(t, found, "pattern %s not found in %v", tt.pattern, r.Matches)
+			}
+		})
+	}
+}
+
+func TestEstimateEmpty(t *testing.T) {
+	r := Estimate("")
+	assert.Equal(t, 0, r.Score)
+	assert.Equal(t, "less than a second", r.CrackTimeText())
+}
+
+func TestCrackTimeText(t *testing.T) {
+	tests := []struct {
+		entropy float64
+		want    string
+	}{
+		{entropy: 10, want: "less than a second"},
+		{entropy: 20, want: "52 seconds"},
+		{entropy: 30, want: "15 hours"},
+		{entropy: 40, want: "2 years"},
+		{entropy: 80, want: "centuries"},
+	}
+	for _, tt := range tests {
+		r := &Result{Entropy: tt.entropy}
+		assert.Equal(t, tt.want, r.CrackTimeText(), "entropy %f", tt.entropy)
+	}
+}
+
+func TestDateEntropy(t *testing.T) {
+	tests := []struct {
+		token string
+		ok    bool
+	}{
+		{"1984", true},
+		{"2099", false},
+		{"13.05.1984", true},
+		{"1984-05-13", true},
+		{"13/05-1984", false},
+		{"130584", true},
+		{"999999", false},
+		{"12ab", false},
+	}
+	for _, tt := range tests {
+		_, ok := dateEntropy(tt.token)
+		assert.Equal(t, tt.ok, ok, tt.token)
+	}
+}
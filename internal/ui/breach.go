@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// confirmBreachedLogin calls save if the item is not a login with a breached password,
+// otherwise it asks to confirm. The check runs in background while a progress dialog is displayed.
+// Breach check errors, i.e. offline, are logged and do not prevent the save.
+func confirmBreachedLogin(item paw.Item, w fyne.Window, save func()) {
+	login, ok := item.(*paw.Login)
+	if !ok || breachChecker == nil || login.Password == nil || login.Password.Value == "" {
+		save()
+		return
+	}
+	password := login.Password.Value
+	progress := dialog.NewProgressInfinite("Breached password", "Checking the password against the data breaches...", w)
+	progress.Show()
+	go func() {
+		count, err := breachChecker.Count(password)
+		progress.Hide()
+		if err != nil {
+			log.Println(err)
+			save()
+			return
+		}
+		if count == 0 {
+			save()
+			return
+		}
+		msg := fmt.Sprintf("This password has been found %d times in data breaches.\nSave it anyway?", count)
+		dialog.ShowConfirm("Breached password", msg, func(ok bool) {
+			if ok {
+				save()
+			}
+		}, w)
+	}()
+}
+
+// maxBreachedAttempts is the max number of breached passwords rejected by the generator
+const maxBreachedAttempts = 10
+
+// breachGuard checks in background the generated passwords against the data breaches and
+// rejects the breached ones: the random passwords are generated again, up to maxBreachedAttempts
+// times, while the stateless ones require to bump the counter.
+// Breach check errors, i.e. offline, are logged and do not reject the password.
+type breachGuard struct {
+	label    *widget.Label
+	bind     binding.String
+	generate func(password *paw.Password) (string, error)
+
+	// mu guards the fields below, only the result for the latest password is applied
+	mu       sync.Mutex
+	latest   int
+	verified string
+	rejected string
+}
+
+// newBreachGuard returns a breach guard for the password bound to bind, generate is used to
+// generate again the breached passwords
+func newBreachGuard(bind binding.String, password *paw.Password, generate func(password *paw.Password) (string, error)) *breachGuard {
+	g := &breachGuard{
+		label:    widget.NewLabel(""),
+		bind:     bind,
+		generate: generate,
+	}
+	g.label.Wrapping = fyne.TextWrapWord
+
+	bind.AddListener(binding.NewDataListener(func() {
+		value, _ := bind.Get()
+		g.mu.Lock()
+		g.latest++
+		n := g.latest
+		verified := value == g.verified
+		g.mu.Unlock()
+		if value == "" {
+			g.label.SetText("")
+			return
+		}
+		if verified {
+			g.label.SetText("Not found in data breaches")
+			return
+		}
+		g.label.SetText("Checking...")
+		// the options could change meanwhile, the check uses a copy
+		p := *password
+		go g.check(n, value, &p)
+	}))
+	return g
+}
+
+// Rejected reports whether the password has been found in a data breach and could not be replaced
+func (g *breachGuard) Rejected(value string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return value != "" && value == g.rejected
+}
+
+// check checks the password n against the data breaches generating it again, if needed
+func (g *breachGuard) check(n int, value string, password *paw.Password) {
+	for i := 0; ; i++ {
+		count, err := breachChecker.Count(value)
+		if err != nil {
+			log.Println(err)
+			g.show(n, "Could not check the data breaches")
+			return
+		}
+		if count == 0 {
+			g.mu.Lock()
+			if n != g.latest {
+				g.mu.Unlock()
+				return
+			}
+			g.verified = value
+			g.mu.Unlock()
+			if i == 0 {
+				g.label.SetText("Not found in data breaches")
+				return
+			}
+			g.bind.Set(value)
+			return
+		}
+		if password.Stateless() || i == maxBreachedAttempts {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if n != g.latest {
+				return
+			}
+			g.rejected = value
+			if password.Stateless() {
+				g.label.SetText(fmt.Sprintf("Found %d times in data breaches, increment the counter to rotate it", count))
+				return
+			}
+			g.label.SetText("Found in data breaches, all the attempts to generate a new one failed")
+			return
+		}
+		value, err = g.generate(password)
+		if err != nil {
+			log.Println(err)
+			g.show(n, "Could not generate a password not found in data breaches")
+			return
+		}
+	}
+}
+
+// show displays the text if the password n is still the latest
+func (g *breachGuard) show(n int, text string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n == g.latest {
+		g.label.SetText(text)
+	}
+}
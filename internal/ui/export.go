@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/exporter"
+	"lucor.dev/paw/internal/paw"
+)
+
+// makeExportButton returns the button used to export the vault items
+func (vw *vaultView) makeExportButton() fyne.CanvasObject {
+	button := widget.NewButtonWithIcon("", theme.DownloadIcon(), vw.showExportDialog)
+	button.Importance = widget.LowImportance
+	return button
+}
+
+// showExportDialog shows the dialog to choose the export options.
+// The plaintext formats display a warning and require an explicit confirmation.
+func (vw *vaultView) showExportDialog() {
+	w := vw.mainView.Window
+
+	formats := []string{}
+	for _, f := range exporter.Formats() {
+		formats = append(formats, string(f))
+	}
+
+	passphrase := widget.NewPasswordEntry()
+	passphrase.SetPlaceHolder("Passphrase to encrypt the archive")
+	warning := widget.NewLabelWithStyle(exporter.PlaintextWarning, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	warning.Wrapping = fyne.TextWrapWord
+	acknowledge := widget.NewCheck("I understand the export is not encrypted", nil)
+	warningBox := container.NewVBox(container.NewBorder(nil, nil, widget.NewIcon(theme.WarningIcon()), nil, warning), acknowledge)
+
+	format := widget.NewSelect(formats, func(s string) {
+		if exporter.Format(s).Plaintext() {
+			passphrase.Hide()
+			warningBox.Show()
+			return
+		}
+		passphrase.Show()
+		warningBox.Hide()
+	})
+	format.SetSelected(string(exporter.Age))
+
+	filtered := widget.NewCheck("Only the listed items", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Format", format),
+		widget.NewFormItem("", passphrase),
+		widget.NewFormItem("Items", filtered),
+	}
+	content := container.NewVBox(widget.NewForm(items...), warningBox)
+	d := dialog.NewCustomConfirm("Export vault", "Export", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		f := exporter.Format(format.Selected)
+		if f.Plaintext() && !acknowledge.Checked {
+			dialog.ShowInformation("Export vault", "Please confirm the export is not encrypted", w)
+			return
+		}
+		if !f.Plaintext() && passphrase.Text == "" {
+			dialog.ShowInformation("Export vault", "The passphrase cannot be empty", w)
+			return
+		}
+		var names []string
+		if filtered.Checked {
+			for _, m := range vw.vault.FilterItemMetadata(vw.filterOptions) {
+				names = append(names, m.Name)
+			}
+		}
+		vw.export(f, names, exporter.Options{Vault: vw.name.Text, Passphrase: passphrase.Text})
+	}, w)
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
+
+// export asks for the destination file, then loads the items concurrently
+// showing a progress bar and writes the export
+func (vw *vaultView) export(format exporter.Format, names []string, opts exporter.Options) {
+	w := vw.mainView.Window
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return
+		}
+
+		bar := widget.NewProgressBar()
+		progressDialog := dialog.NewCustom("Export vault", "Hide", container.NewVBox(widget.NewLabel("Loading the items..."), bar), w)
+		progressDialog.Show()
+
+		go func() {
+			defer wc.Close()
+			var items []paw.Item
+			items, err = exporter.Load(vw.vault, names, func(done, total int) {
+				bar.Max = float64(total)
+				bar.SetValue(float64(done))
+			})
+			if err == nil {
+				err = exporter.Export(wc, format, items, opts)
+			}
+			progressDialog.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("could not export the vault: %w", err), w)
+				return
+			}
+			dialog.ShowInformation("Export vault", fmt.Sprintf("%d items exported", len(items)), w)
+		}()
+	}, w)
+	d.SetFileName(vw.name.Text + format.Ext())
+	d.Show()
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// fieldRows returns the form rows displaying the custom fields according their kind
+func fieldRows(fields paw.Fields, w fyne.Window) []fyne.CanvasObject {
+	var obj []fyne.CanvasObject
+	for _, f := range fields {
+		if f.Value == "" {
+			continue
+		}
+		switch f.Kind {
+		case paw.HiddenField:
+			obj = append(obj, copiablePasswordRow(f.Label, f.Value, w)...)
+		case paw.URLField:
+			obj = append(obj, copiableLinkRow(f.Label, f.Value, w)...)
+		default:
+			obj = append(obj, copiableRow(f.Label, f.Value, w)...)
+		}
+	}
+	return obj
+}
+
+// fieldsEditor allows to add, edit and remove the custom fields of an item
+type fieldsEditor struct {
+	fields *paw.Fields
+	rows   *fyne.Container
+}
+
+// newFieldsEditor returns the editor for fields. The fields are cloned so that
+// changes are applied to the edited item only.
+func newFieldsEditor(fields *paw.Fields) *fieldsEditor {
+	clone := make(paw.Fields, 0, len(*fields))
+	for _, f := range *fields {
+		c := *f
+		clone = append(clone, &c)
+	}
+	*fields = clone
+
+	fe := &fieldsEditor{
+		fields: fields,
+		rows:   container.NewVBox(),
+	}
+	fe.refresh()
+	return fe
+}
+
+// CanvasObject returns the editor canvas object
+func (fe *fieldsEditor) CanvasObject() fyne.CanvasObject {
+	addButton := widget.NewButtonWithIcon("Add field", theme.ContentAddIcon(), func() {
+		*fe.fields = append(*fe.fields, &paw.Field{Kind: paw.TextField})
+		fe.refresh()
+	})
+	return container.NewVBox(fe.rows, container.NewHBox(addButton))
+}
+
+func (fe *fieldsEditor) refresh() {
+	fe.rows.Objects = nil
+	for i, f := range *fe.fields {
+		fe.rows.Add(fe.makeRow(i, f))
+	}
+	fe.rows.Refresh()
+}
+
+func (fe *fieldsEditor) makeRow(index int, f *paw.Field) fyne.CanvasObject {
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder("Label")
+	labelEntry.SetText(f.Label)
+	labelEntry.OnChanged = func(s string) {
+		f.Label = s
+	}
+
+	valueEntry := widget.NewEntry()
+	if f.Kind == paw.HiddenField {
+		valueEntry = widget.NewPasswordEntry()
+	}
+	valueEntry.SetPlaceHolder("Value")
+	valueEntry.SetText(f.Value)
+	valueEntry.Validator = func(s string) error {
+		v := *f
+		v.Value = s
+		if v.Label == "" {
+			v.Label = "value"
+		}
+		return v.Validate()
+	}
+	valueEntry.OnChanged = func(s string) {
+		f.Value = s
+	}
+	if f.Kind == paw.DateField {
+		valueEntry.SetPlaceHolder(paw.FieldDateLayout)
+	}
+
+	kinds := []string{}
+	for _, k := range paw.FieldKinds() {
+		kinds = append(kinds, string(k))
+	}
+	kindSelect := widget.NewSelect(kinds, nil)
+	kindSelect.SetSelected(string(f.Kind))
+	kindSelect.OnChanged = func(s string) {
+		if paw.FieldKind(s) == f.Kind {
+			return
+		}
+		f.Kind = paw.FieldKind(s)
+		// rebuild to switch between plain and password entries
+		fe.refresh()
+	}
+
+	removeButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		fields := *fe.fields
+		*fe.fields = append(fields[:index:index], fields[index+1:]...)
+		fe.refresh()
+	})
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(kindSelect, removeButton), container.NewGridWithColumns(2, labelEntry, valueEntry))
+}
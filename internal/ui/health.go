@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/health"
+	"lucor.dev/paw/internal/icon"
+	"lucor.dev/paw/internal/paw"
+)
+
+// makeHealthButton returns the button used to scan the vault and display the health report
+func (vw *vaultView) makeHealthButton() fyne.CanvasObject {
+	button := widget.NewButtonWithIcon("", icon.FactCheckOutlinedIconThemed, vw.showHealthReport)
+	button.Importance = widget.LowImportance
+	return button
+}
+
+// showHealthReport scans the vault and sets the health report as content.
+// The item values are loaded from the vault only once, then served by the vault cache.
+func (vw *vaultView) showHealthReport() {
+	bar := widget.NewProgressBar()
+	vw.setContent(container.NewCenter(container.NewVBox(widget.NewLabel("Checking the vault health..."), bar)))
+
+	go func() {
+		opts := health.DefaultOptions()
+		opts.Breach = breachChecker
+		r, err := health.Scan(vw.name.Text, vw.vault, opts, func(done, total int) {
+			bar.Max = float64(total)
+			bar.SetValue(float64(done))
+		})
+		if err != nil {
+			vw.setContent(vw.defaultContent())
+			dialog.ShowError(fmt.Errorf("could not check the vault health: %w", err), vw.mainView.Window)
+			return
+		}
+		vw.setContent(vw.healthView(r))
+	}()
+}
+
+// healthView returns the view that displays the findings of the report grouped by issue.
+// Tapping a finding displays the item.
+func (vw *vaultView) healthView(r *health.Report) fyne.CanvasObject {
+	if len(r.Findings) == 0 && len(r.Warnings) == 0 {
+		img := canvas.NewImageFromResource(icon.CheckCircleOutlinedIconThemed)
+		img.FillMode = canvas.ImageFillContain
+		img.SetMinSize(fyne.NewSize(64, 64))
+		msg := widget.NewLabelWithStyle("No issues found", fyne.TextAlignCenter, fyne.TextStyle{})
+		return container.NewCenter(container.NewVBox(img, msg))
+	}
+
+	accordion := widget.NewAccordion()
+	for _, issue := range health.Issues() {
+		findings := r.Group(issue)
+		if len(findings) == 0 {
+			continue
+		}
+		rows := container.NewVBox()
+		for _, f := range findings {
+			f := f
+			link := widget.NewButton(f.Item, func() {
+				vw.itemsWidget.OnSelected(&paw.Metadata{Name: f.Item, Type: f.Type})
+			})
+			link.Importance = widget.LowImportance
+			link.Alignment = widget.ButtonAlignLeading
+			rows.Add(container.NewBorder(nil, nil, link, nil, widget.NewLabel(f.Detail)))
+		}
+		title := fmt.Sprintf("%s (%d)", issue.Title(), len(findings))
+		accordion.Append(widget.NewAccordionItem(title, rows))
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("%d items checked, %d findings", r.Items, len(r.Findings)))
+	refresh := widget.NewButtonWithIcon("Check again", icon.FactCheckOutlinedIconThemed, vw.showHealthReport)
+	var top fyne.CanvasObject = container.NewBorder(nil, nil, summary, refresh)
+	if len(r.Warnings) > 0 {
+		warnings := container.NewVBox()
+		for _, warning := range r.Warnings {
+			label := widget.NewLabel(warning)
+			label.Wrapping = fyne.TextWrapWord
+			warnings.Add(label)
+		}
+		top = container.NewVBox(top, warnings)
+	}
+	return container.NewBorder(top, nil, nil, nil, container.NewVScroll(accordion))
+}
@@ -13,6 +13,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"lucor.dev/paw/internal/azure"
 	"lucor.dev/paw/internal/paw"
 )
 
@@ -23,14 +24,15 @@ type FyneItem interface {
 	// Show returns a fyne CanvasObject used to view the item
 	Show(ctx context.Context, w fyne.Window) fyne.CanvasObject
 	// Edit returns a fyne CanvasObject used to edit the item
-	Edit(ctx context.Context, key *paw.Key, w fyne.Window) (fyne.CanvasObject, paw.Item)
+	Edit(ctx context.Context, vault azure.Vault, w fyne.Window) (fyne.CanvasObject, paw.Item)
 	// Item returns the paw Item
 	Item() paw.Item
 }
 
-// FynePasswordGenerator wraps all methods to show a Fyne dialog to generate passwords
+// FynePasswordGenerator wraps all methods to show a Fyne dialog to generate passwords.
+// The item is the one owning the password, stateless passwords are derived from it.
 type FynePasswordGenerator interface {
-	ShowPasswordGenerator(bind binding.String, password *paw.Password, w fyne.Window)
+	ShowPasswordGenerator(bind binding.String, password *paw.Password, item paw.Item, w fyne.Window)
 }
 
 func NewFyneItem(item paw.Item) FyneItem {
@@ -38,6 +40,10 @@ func NewFyneItem(item paw.Item) FyneItem {
 	switch item.GetMetadata().Type {
 	case paw.LoginItemType:
 		fyneItem = &Login{Login: item.(*paw.Login)}
+	case paw.SSHKeyItemType:
+		fyneItem = &SSHKey{SSHKey: item.(*paw.SSHKey)}
+	case paw.TOTPItemType:
+		fyneItem = &TOTP{TOTP: item.(*paw.TOTP)}
 	}
 	return fyneItem
 }
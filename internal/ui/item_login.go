@@ -14,6 +14,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"lucor.dev/paw/internal/azure"
 	"lucor.dev/paw/internal/favicon"
 	"lucor.dev/paw/internal/icon"
 	"lucor.dev/paw/internal/paw"
@@ -40,7 +41,7 @@ func (login *Login) Icon() fyne.Resource {
 	return icon.KeyOutlinedIconThemed
 }
 
-func (login *Login) Edit(ctx context.Context, key *paw.Key, w fyne.Window) (fyne.CanvasObject, paw.Item) {
+func (login *Login) Edit(ctx context.Context, vault azure.Vault, w fyne.Window) (fyne.CanvasObject, paw.Item) {
 
 	loginIcon := widget.NewIcon(login.Icon())
 
@@ -52,6 +53,10 @@ func (login *Login) Edit(ctx context.Context, key *paw.Key, w fyne.Window) (fyne
 	*loginItem.Note = *login.Note
 	loginItem.Password = &paw.Password{}
 	*loginItem.Password = *login.Password
+	if login.TOTP != nil {
+		loginItem.TOTP = &paw.TOTP{}
+		*loginItem.TOTP = *login.TOTP
+	}
 
 	passwordBind := binding.BindString(&loginItem.Password.Value)
 
@@ -74,6 +79,11 @@ func (login *Login) Edit(ctx context.Context, key *paw.Key, w fyne.Window) (fyne
 	usernameEntry := widget.NewEntryWithData(binding.BindString(&loginItem.Username))
 	usernameEntry.Validator = nil
 
+	// the optional one-time password
+	totpEntry := newTOTPEntry(loginItem.TOTP, func(totp *paw.TOTP) {
+		loginItem.TOTP = totp
+	})
+
 	// the note field
 	noteEntry := widget.NewEntryWithData(binding.BindString(&loginItem.Note.Value))
 	noteEntry.MultiLine = true
@@ -90,8 +100,8 @@ func (login *Login) Edit(ctx context.Context, key *paw.Key, w fyne.Window) (fyne
 	})
 
 	passwordMakeButton := widget.NewButtonWithIcon("Generate", icon.KeyOutlinedIconThemed, func() {
-		pg := NewPasswordGenerator(key)
-		pg.ShowPasswordGenerator(passwordBind, loginItem.Password, w)
+		pg := NewPasswordGenerator(vault)
+		pg.ShowPasswordGenerator(passwordBind, loginItem.Password, loginItem, w)
 	})
 
 	form := container.New(layout.NewFormLayout())
@@ -108,10 +118,19 @@ func (login *Login) Edit(ctx context.Context, key *paw.Key, w fyne.Window) (fyne
 
 	form.Add(container.NewBorder(nil, nil, nil, container.NewHBox(passwordCopyButton, passwordMakeButton), passwordEntry))
 
+	form.Add(labelWithStyle("Strength"))
+	form.Add(newStrengthMeter(passwordBind, func() []string {
+		return []string{loginItem.Name, loginItem.Username}
+	}))
+
+	form.Add(labelWithStyle("One-time password"))
+	form.Add(totpEntry)
+
 	form.Add(labelWithStyle("Note"))
 	form.Add(noteEntry)
 
-	return form, loginItem
+	fieldsEditor := newFieldsEditor(&loginItem.Fields)
+	return container.NewVBox(form, widget.NewSeparator(), fieldsEditor.CanvasObject()), loginItem
 }
 
 func (login *Login) Show(ctx context.Context, w fyne.Window) fyne.CanvasObject {
@@ -125,6 +144,10 @@ func (login *Login) Show(ctx context.Context, w fyne.Window) fyne.CanvasObject {
 	if login.Password.Value != "" {
 		obj = append(obj, copiablePasswordRow("Password", login.Password.Value, w)...)
 	}
+	if login.TOTP != nil && login.TOTP.Secret != "" {
+		obj = append(obj, totpRow(ctx, "One-time password", login.TOTP, w)...)
+	}
+	obj = append(obj, fieldRows(login.Fields, w)...)
 	if login.Note != nil && login.Note.Value != "" {
 		obj = append(obj, copiableRow("Note", login.Note.Value, w)...)
 	}
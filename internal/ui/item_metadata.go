@@ -26,8 +26,13 @@ func (m *Metadata) Icon() fyne.Resource {
 	if m.Favicon != nil {
 		return m.Favicon
 	}
-	if m.Type == paw.LoginItemType {
+	switch m.Type {
+	case paw.LoginItemType:
 		return icon.KeyOutlinedIconThemed
+	case paw.SSHKeyItemType:
+		return icon.TerminalOutlinedIconThemed
+	case paw.TOTPItemType:
+		return icon.TimerOutlinedIconThemed
 	}
 	return icon.PawIcon
 }
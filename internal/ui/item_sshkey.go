@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"context"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/icon"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Item interface
+var _ paw.Item = (*SSHKey)(nil)
+
+// Declare conformity to FyneItem interface
+var _ FyneItem = (*SSHKey)(nil)
+
+type SSHKey struct {
+	*paw.SSHKey
+}
+
+func (key *SSHKey) Item() paw.Item {
+	return key.SSHKey
+}
+
+func (key *SSHKey) Icon() fyne.Resource {
+	return icon.TerminalOutlinedIconThemed
+}
+
+func (key *SSHKey) Edit(ctx context.Context, _ azure.Vault, w fyne.Window) (fyne.CanvasObject, paw.Item) {
+	keyItem := &paw.SSHKey{}
+	*keyItem = *key.SSHKey
+	keyItem.Metadata = &paw.Metadata{}
+	*keyItem.Metadata = *key.Metadata
+	keyItem.Note = &paw.Note{}
+	*keyItem.Note = *key.Note
+
+	titleEntry := widget.NewEntryWithData(binding.BindString(&keyItem.Name))
+	titleEntry.Validator = nil
+	titleEntry.PlaceHolder = "Untitled SSH key"
+
+	publicKeyLabel := widget.NewLabel(keyItem.PublicKey)
+	publicKeyLabel.Wrapping = fyne.TextWrapBreak
+	fingerprintLabel := widget.NewLabel(keyItem.Fingerprint)
+
+	privateKeyEntry := widget.NewPasswordEntry()
+	privateKeyEntry.SetText(keyItem.PrivateKey)
+	privateKeyEntry.Validator = nil
+	privateKeyEntry.SetPlaceHolder("Paste a PEM encoded private key")
+
+	refresh := func() {
+		privateKeyEntry.SetText(keyItem.PrivateKey)
+		publicKeyLabel.SetText(keyItem.PublicKey)
+		fingerprintLabel.SetText(keyItem.Fingerprint)
+	}
+
+	privateKeyEntry.OnChanged = func(s string) {
+		if s == keyItem.PrivateKey {
+			return
+		}
+		err := keyItem.SetPrivateKey(s)
+		if err != nil {
+			// keep the previous key until a valid one is pasted
+			return
+		}
+		publicKeyLabel.SetText(keyItem.PublicKey)
+		fingerprintLabel.SetText(keyItem.Fingerprint)
+	}
+
+	keyTypeSelect := widget.NewSelect([]string{string(paw.ED25519SSHKey), string(paw.RSASSHKey)}, nil)
+	keyTypeSelect.SetSelected(string(paw.ED25519SSHKey))
+
+	generateButton := widget.NewButtonWithIcon("Generate", icon.KeyOutlinedIconThemed, func() {
+		generate := func() {
+			err := keyItem.Generate(paw.SSHKeyType(keyTypeSelect.Selected), 0)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			refresh()
+		}
+		if keyItem.PrivateKey == "" {
+			generate()
+			return
+		}
+		dialog.ShowConfirm("Generate", "The current key will be replaced. Continue?", func(b bool) {
+			if b {
+				generate()
+			}
+		}, w)
+	})
+
+	// the note field
+	noteEntry := widget.NewEntryWithData(binding.BindString(&keyItem.Note.Value))
+	noteEntry.MultiLine = true
+	noteEntry.Validator = nil
+
+	form := container.New(layout.NewFormLayout())
+	form.Add(widget.NewIcon(key.Icon()))
+	form.Add(titleEntry)
+
+	form.Add(labelWithStyle("Private key"))
+	form.Add(container.NewBorder(nil, nil, nil, container.NewHBox(keyTypeSelect, generateButton), privateKeyEntry))
+
+	form.Add(labelWithStyle("Public key"))
+	form.Add(publicKeyLabel)
+
+	form.Add(labelWithStyle("Fingerprint"))
+	form.Add(fingerprintLabel)
+
+	form.Add(labelWithStyle("Note"))
+	form.Add(noteEntry)
+
+	return form, keyItem
+}
+
+func (key *SSHKey) Show(ctx context.Context, w fyne.Window) fyne.CanvasObject {
+	obj := titleRow(key.Icon(), key.Name)
+	if key.PublicKey != "" {
+		obj = append(obj, copiableRow("Public key", key.AuthorizedKey(), w)...)
+		obj = append(obj, copiableRow("Fingerprint", key.Fingerprint, w)...)
+	}
+	if key.PrivateKey != "" {
+		obj = append(obj, copiablePasswordRow("Private key", key.PrivateKey, w)...)
+	}
+	if key.Note != nil && key.Note.Value != "" {
+		obj = append(obj, copiableRow("Note", key.Note.Value, w)...)
+	}
+	return container.New(layout.NewFormLayout(), obj...)
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"context"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/icon"
+	"lucor.dev/paw/internal/paw"
+)
+
+// Declare conformity to Item interface
+var _ paw.Item = (*TOTP)(nil)
+
+// Declare conformity to FyneItem interface
+var _ FyneItem = (*TOTP)(nil)
+
+type TOTP struct {
+	*paw.TOTP
+}
+
+func (totp *TOTP) Item() paw.Item {
+	return totp.TOTP
+}
+
+func (totp *TOTP) Icon() fyne.Resource {
+	return icon.TimerOutlinedIconThemed
+}
+
+func (totp *TOTP) Edit(ctx context.Context, _ azure.Vault, w fyne.Window) (fyne.CanvasObject, paw.Item) {
+	totpItem := &paw.TOTP{}
+	*totpItem = *totp.TOTP
+	totpItem.Metadata = &paw.Metadata{}
+	*totpItem.Metadata = *totp.Metadata
+	totpItem.Note = &paw.Note{}
+	*totpItem.Note = *totp.Note
+
+	titleEntry := widget.NewEntryWithData(binding.BindString(&totpItem.Name))
+	titleEntry.Validator = nil
+	titleEntry.PlaceHolder = "Untitled one-time password"
+
+	var initial *paw.TOTP
+	if totpItem.Secret != "" {
+		initial = totpItem
+	}
+	uriEntry := newTOTPEntry(initial, func(t *paw.TOTP) {
+		if t == nil {
+			totpItem.Secret = ""
+			return
+		}
+		totpItem.Secret = t.Secret
+		totpItem.Issuer = t.Issuer
+		totpItem.Account = t.Account
+		totpItem.Algorithm = t.Algorithm
+		totpItem.Digits = t.Digits
+		totpItem.Period = t.Period
+	})
+
+	// the note field
+	noteEntry := widget.NewEntryWithData(binding.BindString(&totpItem.Note.Value))
+	noteEntry.MultiLine = true
+	noteEntry.Validator = nil
+
+	form := container.New(layout.NewFormLayout())
+	form.Add(widget.NewIcon(totp.Icon()))
+	form.Add(titleEntry)
+
+	form.Add(labelWithStyle("URI"))
+	form.Add(uriEntry)
+
+	form.Add(labelWithStyle("Note"))
+	form.Add(noteEntry)
+
+	return form, totpItem
+}
+
+func (totp *TOTP) Show(ctx context.Context, w fyne.Window) fyne.CanvasObject {
+	obj := titleRow(totp.Icon(), totp.Name)
+	if totp.Issuer != "" {
+		obj = append(obj, copiableRow("Issuer", totp.Issuer, w)...)
+	}
+	if totp.Account != "" {
+		obj = append(obj, copiableRow("Account", totp.Account, w)...)
+	}
+	if totp.Secret != "" {
+		obj = append(obj, totpRow(ctx, "Code", totp.TOTP, w)...)
+	}
+	if totp.Note != nil && totp.Note.Value != "" {
+		obj = append(obj, copiableRow("Note", totp.Note.Value, w)...)
+	}
+	return container.New(layout.NewFormLayout(), obj...)
+}
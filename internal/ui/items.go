@@ -17,7 +17,7 @@ import (
 type itemsWidget struct {
 	widget.BaseWidget
 
-	vault *azure.SecretsVault
+	vault azure.Vault
 
 	selectedIndex int
 
@@ -32,7 +32,7 @@ type itemsWidget struct {
 }
 
 // newItemsWidget returns a new items widget
-func newItemsWidget(vault *azure.SecretsVault, opts *paw.VaultFilterOptions) *itemsWidget {
+func newItemsWidget(vault azure.Vault, opts *paw.VaultFilterOptions) *itemsWidget {
 	iw := &itemsWidget{
 		vault:         vault,
 		selectedIndex: -1,
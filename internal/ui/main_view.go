@@ -13,8 +13,11 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"lucor.dev/paw/internal/agent"
 	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/breach"
 	"lucor.dev/paw/internal/icon"
+	"lucor.dev/paw/internal/paw"
 )
 
 // newVaultView is the function that would get us the visual data of the VAULT
@@ -26,6 +29,9 @@ var maxWorkers = runtime.NumCPU()
 // progress bar visual representation of Clipboard Timeout
 var progress *widget.ProgressBar
 
+// breachChecker checks the passwords against the breached ones, nil if not configured
+var breachChecker *breach.Checker
+
 // mainView represents the Paw main view
 // TODO modify to reflect azure keyvault structure
 type mainView struct {
@@ -34,7 +40,7 @@ type mainView struct {
 	Conf  *azure.Config
 	token azcore.TokenCredential
 
-	unlockedVault map[string]*azure.SecretsVault // this act as cache
+	unlockedVault map[string]azure.Vault // this act as cache
 
 	view *fyne.Container
 
@@ -54,6 +60,13 @@ func Make(a fyne.App, w fyne.Window, ver string) fyne.CanvasObject {
 	if err != nil {
 		log.Fatal(err)
 	}
+	breachChecker = c.BreachChecker()
+
+	if s, err := paw.NewOSStorage(); err == nil {
+		if err := paw.LoadWordlists(paw.WordlistsPath(s)); err != nil {
+			log.Println(err)
+		}
+	}
 
 	if ver == "" {
 		ver = "(unknown)"
@@ -62,7 +75,7 @@ func Make(a fyne.App, w fyne.Window, ver string) fyne.CanvasObject {
 	mw := &mainView{
 		Window:        w,
 		Conf:          c,
-		unlockedVault: make(map[string]*azure.SecretsVault),
+		unlockedVault: make(map[string]azure.Vault),
 		version:       ver,
 	}
 
@@ -191,15 +204,7 @@ func (mw *mainView) createVaultView() fyne.CanvasObject {
 		}
 		// we would try and GET the new vault that was selected to be used
 		// and if sucessfull save to config file
-		if mw.token == nil {
-			var err error
-			mw.token, err = mw.Conf.NewCredential()
-			if err != nil {
-				dialog.ShowError(err, mw.Window)
-				return
-			}
-		}
-		vault, err := azure.NewSecretsVault(name.Text, mw.token)
+		vault, err := mw.openVault(name.Text)
 		if err != nil {
 			dialog.ShowError(err, mw.Window)
 			return
@@ -244,15 +249,7 @@ func (mw *mainView) vaultListView() fyne.CanvasObject {
 			resource = icon.LockOutlinedIconThemed
 		}
 		btn := widget.NewButtonWithIcon(name, resource, func() {
-			if mw.token == nil {
-				var err error
-				mw.token, err = mw.Conf.NewCredential()
-				if err != nil {
-					dialog.ShowError(err, mw.Window)
-					return
-				}
-			}
-			vault, err := azure.NewSecretsVault(name, mw.token)
+			vault, err := mw.openVault(name)
 			if err != nil {
 				dialog.ShowError(err, mw.Window)
 				return
@@ -267,6 +264,22 @@ func (mw *mainView) vaultListView() fyne.CanvasObject {
 	return container.NewCenter(c)
 }
 
+// openVault opens the named vault. The vault is served by the agent, if
+// running, so that the authenticated session is shared with the CLI.
+func (mw *mainView) openVault(name string) (azure.Vault, error) {
+	if client, err := agent.DialDefault(); err == nil {
+		return agent.NewRemoteVault(client, name)
+	}
+	if mw.token == nil {
+		var err error
+		mw.token, err = mw.Conf.NewCredential()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return azure.NewSecretsVault(name, mw.token)
+}
+
 // vaultView returns the view used to handle a vault
 // redundant as once we are authenticated we have access to all known vaults
 func (mw *mainView) vaultViewByName(name string) fyne.CanvasObject {
@@ -1,18 +1,26 @@
 package ui
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"lucor.dev/paw/internal/azure"
 	"lucor.dev/paw/internal/paw"
 )
 
@@ -44,13 +52,13 @@ type RandomPasswordOptions struct {
 }
 
 type pwgenDialog struct {
-	key     *paw.Key
+	vault   azure.Vault
 	options pwgenOptions
 }
 
-func NewPasswordGenerator(key *paw.Key) *pwgenDialog {
+func NewPasswordGenerator(vault azure.Vault) *pwgenDialog {
 	pd := &pwgenDialog{
-		key: key,
+		vault: vault,
 		options: pwgenOptions{
 			RandomPasswordOptions: RandomPasswordOptions{
 				DefaultFormat: RandomPasswordDefaultFormat(),
@@ -75,14 +83,14 @@ func NewPasswordGenerator(key *paw.Key) *pwgenDialog {
 	return pd
 }
 
-func (pd *pwgenDialog) ShowPasswordGenerator(bind binding.String, password *paw.Password, w fyne.Window) {
+func (pd *pwgenDialog) ShowPasswordGenerator(bind binding.String, password *paw.Password, item paw.Item, w fyne.Window) {
 
 	passwordBind := binding.NewString()
 	passwordEntry := widget.NewEntryWithData(passwordBind)
 	passwordEntry.Disable()
 	passwordEntry.Validator = nil
 	refreshButton := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
-		secret, err := pwgen(pd.key, password)
+		secret, err := pwgen(pd.vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -96,15 +104,24 @@ func (pd *pwgenDialog) ShowPasswordGenerator(bind binding.String, password *paw.
 		paw.RandomPassword.String(),
 		paw.PassphrasePassword.String(),
 		paw.PinPassword.String(),
+		paw.PatternPassword.String(),
+		paw.PronounceablePassword.String(),
+		paw.StatelessPassword.String(),
 	}
 	typeList := widget.NewSelect(typeOptions, func(s string) {
 		switch s {
 		case paw.PassphrasePassword.String():
-			content.Objects[0] = passphraseOptions(pd.key, passwordBind, password, pd.options.PassphrasePasswordOptions)
+			content.Objects[0] = passphraseOptions(pd.vault, item, passwordBind, password, pd.options.PassphrasePasswordOptions, w)
 		case paw.PinPassword.String():
-			content.Objects[0] = pinOptions(pd.key, passwordBind, password, pd.options.PinPasswordOptions)
+			content.Objects[0] = pinOptions(pd.vault, item, passwordBind, password, pd.options.PinPasswordOptions)
+		case paw.PronounceablePassword.String():
+			content.Objects[0] = pronounceableOptions(pd.vault, item, passwordBind, password)
+		case paw.PatternPassword.String():
+			content.Objects[0] = patternOptions(pd.vault, item, passwordBind, password)
+		case paw.StatelessPassword.String():
+			content.Objects[0] = randomPasswordOptions(pd.vault, item, passwordBind, password, paw.StatelessPassword, pd.options.RandomPasswordOptions)
 		default:
-			content.Objects[0] = randomPasswordOptions(pd.key, passwordBind, password, pd.options.RandomPasswordOptions)
+			content.Objects[0] = randomPasswordOptions(pd.vault, item, passwordBind, password, paw.RandomPassword, pd.options.RandomPasswordOptions)
 		}
 		content.Refresh()
 	})
@@ -119,6 +136,21 @@ func (pd *pwgenDialog) ShowPasswordGenerator(bind binding.String, password *paw.
 	form := container.New(layout.NewFormLayout())
 	form.Add(labelWithStyle("Password"))
 	form.Add(container.NewBorder(nil, nil, nil, refreshButton, passwordEntry))
+	form.Add(labelWithStyle("Strength"))
+	form.Add(newStrengthMeter(passwordBind, func() []string {
+		if item == nil {
+			return nil
+		}
+		return []string{item.GetMetadata().Name}
+	}))
+	var guard *breachGuard
+	if breachChecker != nil {
+		guard = newBreachGuard(passwordBind, password, func(p *paw.Password) (string, error) {
+			return pwgen(pd.vault, p, item)
+		})
+		form.Add(labelWithStyle("Breach"))
+		form.Add(guard.label)
+	}
 	form.Add(labelWithStyle("Type"))
 	form.Add(typeList)
 	c := container.NewBorder(form, nil, nil, nil, content)
@@ -126,6 +158,10 @@ func (pd *pwgenDialog) ShowPasswordGenerator(bind binding.String, password *paw.
 	d := dialog.NewCustomConfirm("Generate password", "Use", "Cancel", c, func(b bool) {
 		if b {
 			value, _ := passwordBind.Get()
+			if guard != nil && guard.Rejected(value) {
+				dialog.ShowInformation("Breached password", "The password has been found in data breaches and has not been used", w)
+				return
+			}
 			bind.Set(value)
 		}
 	}, w)
@@ -133,7 +169,7 @@ func (pd *pwgenDialog) ShowPasswordGenerator(bind binding.String, password *paw.
 	d.Show()
 }
 
-func passphraseOptions(key *paw.Key, passwordBind binding.String, password *paw.Password, opts PassphrasePasswordOptions) fyne.CanvasObject {
+func passphraseOptions(vault azure.Vault, item paw.Item, passwordBind binding.String, password *paw.Password, opts PassphrasePasswordOptions, w fyne.Window) fyne.CanvasObject {
 
 	if password.Length == 0 || password.Length < opts.MinLength || password.Length > opts.MaxLength {
 		password.Length = opts.DefaultLength
@@ -162,7 +198,7 @@ func passphraseOptions(key *paw.Key, passwordBind binding.String, password *paw.
 			return
 		}
 		lengthBind.Set(l)
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -174,7 +210,7 @@ func passphraseOptions(key *paw.Key, passwordBind binding.String, password *paw.
 	lengthSlider := widget.NewSlider(float64(opts.MinLength), float64(opts.MaxLength))
 	lengthSlider.OnChanged = func(f float64) {
 		lengthBind.Set(int(f))
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -184,21 +220,196 @@ func passphraseOptions(key *paw.Key, passwordBind binding.String, password *paw.
 	}
 	lengthSlider.SetValue(float64(password.Length))
 
-	secret, err := pwgen(key, password)
-	if err != nil {
-		// TODO show dialog
-		log.Println(err)
+	regenerate := func() {
+		secret, err := pwgen(vault, password, item)
+		if err != nil {
+			// TODO show dialog
+			log.Println(err)
+			return
+		}
+		passwordBind.Set(secret)
+	}
+
+	separatorEntry := widget.NewSelectEntry([]string{"-", "_", ".", ",", " "})
+	separatorEntry.SetText(password.Separator)
+	separatorEntry.SetPlaceHolder(paw.PassphraseDefaultSeparator)
+	separatorEntry.OnChanged = func(s string) {
+		password.Separator = s
+		regenerate()
 	}
-	passwordBind.Set(secret)
+
+	var capitalizations []string
+	for _, c := range paw.Capitalizations() {
+		capitalizations = append(capitalizations, c.String())
+	}
+	capitalizationSelect := widget.NewSelect(capitalizations, nil)
+	capitalizationSelect.SetSelected(password.Capitalization.String())
+	capitalizationSelect.OnChanged = func(s string) {
+		for _, c := range paw.Capitalizations() {
+			if c.String() == s {
+				password.Capitalization = c
+			}
+		}
+		regenerate()
+	}
+
+	digitCheck := widget.NewCheck("Digit", func(b bool) {
+		password.InjectDigit = b
+		regenerate()
+	})
+	digitCheck.SetChecked(password.InjectDigit)
+	symbolCheck := widget.NewCheck("Symbol", func(b bool) {
+		password.InjectSymbol = b
+		regenerate()
+	})
+	symbolCheck.SetChecked(password.InjectSymbol)
+
+	wordlistSelect := widget.NewSelect(paw.Wordlists(), func(s string) {
+		password.Wordlist = s
+		if s == paw.DefaultWordlist {
+			password.Wordlist = ""
+		}
+		regenerate()
+	})
+	if password.Wordlist == "" {
+		wordlistSelect.SetSelected(paw.DefaultWordlist)
+	} else {
+		wordlistSelect.SetSelected(password.Wordlist)
+	}
+	loadButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if r == nil {
+				return
+			}
+			defer r.Close()
+			name, err := importWordlist(r)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			wordlistSelect.Options = paw.Wordlists()
+			wordlistSelect.SetSelected(name)
+		}, w)
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
+		d.Show()
+	})
+
+	regenerate()
 
 	form := container.New(layout.NewFormLayout())
 	form.Add(labelWithStyle("Length"))
 	form.Add(container.NewBorder(nil, nil, nil, lengthEntry, lengthSlider))
+	form.Add(labelWithStyle("Separator"))
+	form.Add(separatorEntry)
+	form.Add(labelWithStyle("Capitalize"))
+	form.Add(capitalizationSelect)
+	form.Add(labelWithStyle("Add"))
+	form.Add(container.NewHBox(digitCheck, symbolCheck))
+	form.Add(labelWithStyle("Wordlist"))
+	form.Add(container.NewBorder(nil, nil, nil, loadButton, wordlistSelect))
+
+	return form
+}
+
+// importWordlist registers the wordlist read from r and copies it into the wordlists
+// directory so that it is available on the next runs. It returns the wordlist name.
+func importWordlist(r fyne.URIReadCloser) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	words, err := paw.ParseWordlist(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(r.URI().Name(), r.URI().Extension())
+	if err := paw.RegisterWordlist(name, words); err != nil {
+		return "", err
+	}
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return "", err
+	}
+	dir := paw.WordlistsPath(s)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return name, os.WriteFile(filepath.Join(dir, name+".txt"), data, 0600)
+}
+
+// patternOptions returns the pattern editor with a live preview of the generated passwords
+func patternOptions(vault azure.Vault, item paw.Item, passwordBind binding.String, password *paw.Password) fyne.CanvasObject {
+	if password.Mode != paw.PatternPassword {
+		password.Mode = paw.PatternPassword
+	}
+	if password.Pattern == "" {
+		password.Pattern = paw.PatternPasswordDefaultPattern
+	}
+
+	preview := widget.NewLabel("")
+	preview.TextStyle = fyne.TextStyle{Monospace: true}
+	preview.Wrapping = fyne.TextWrapBreak
+	regenerate := func() {
+		pattern, err := paw.ParsePattern(password.Pattern)
+		if err != nil {
+			preview.SetText(err.Error())
+			return
+		}
+		var samples []string
+		for i := 0; i < 3; i++ {
+			secret, err := pwgen(vault, password, item)
+			if err != nil {
+				preview.SetText(err.Error())
+				return
+			}
+			if i == 0 {
+				passwordBind.Set(secret)
+				continue
+			}
+			samples = append(samples, secret)
+		}
+		preview.SetText(fmt.Sprintf("%s\n%.0f bits of entropy", strings.Join(samples, "\n"), pattern.Entropy()))
+	}
 
+	patternEntry := widget.NewSelectEntry([]string{
+		paw.PatternPasswordDefaultPattern,
+		"[A-Z]{4}-[0-9]{4}",
+		"HH:HH:HH:HH:HH:HH",
+		"[A-Z0-9]{5}-[A-Z0-9]{5}-[A-Z0-9]{5}",
+	})
+	patternEntry.SetText(password.Pattern)
+	patternEntry.Validator = func(s string) error {
+		_, err := paw.ParsePattern(s)
+		return err
+	}
+	patternEntry.OnChanged = func(s string) {
+		password.Pattern = s
+		regenerate()
+	}
+
+	var help []string
+	for _, p := range paw.PatternPlaceholders() {
+		help = append(help, fmt.Sprintf("%s\t%s", p[0], p[1]))
+	}
+	helpLabel := widget.NewLabel(strings.Join(help, "\n"))
+
+	regenerate()
+
+	form := container.New(layout.NewFormLayout())
+	form.Add(labelWithStyle("Pattern"))
+	form.Add(patternEntry)
+	form.Add(labelWithStyle("Preview"))
+	form.Add(preview)
+	form.Add(labelWithStyle("Syntax"))
+	form.Add(helpLabel)
 	return form
 }
 
-func pinOptions(key *paw.Key, passwordBind binding.String, password *paw.Password, opts PinPasswordOptions) fyne.CanvasObject {
+func pinOptions(vault azure.Vault, item paw.Item, passwordBind binding.String, password *paw.Password, opts PinPasswordOptions) fyne.CanvasObject {
 
 	if password.Length == 0 || password.Length < opts.MinLength || password.Length > opts.MaxLength {
 		password.Length = opts.DefaultLength
@@ -233,7 +444,7 @@ func pinOptions(key *paw.Key, passwordBind binding.String, password *paw.Passwor
 			return
 		}
 		lengthBind.Set(l)
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -245,7 +456,7 @@ func pinOptions(key *paw.Key, passwordBind binding.String, password *paw.Passwor
 	lengthSlider := widget.NewSlider(float64(opts.MinLength), float64(opts.MaxLength))
 	lengthSlider.OnChanged = func(f float64) {
 		lengthBind.Set(int(f))
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -255,7 +466,7 @@ func pinOptions(key *paw.Key, passwordBind binding.String, password *paw.Passwor
 	}
 	lengthSlider.SetValue(float64(password.Length))
 
-	secret, err := pwgen(key, password)
+	secret, err := pwgen(vault, password, item)
 	if err != nil {
 		// TODO show dialog
 		log.Println(err)
@@ -269,7 +480,65 @@ func pinOptions(key *paw.Key, passwordBind binding.String, password *paw.Passwor
 	return form
 }
 
-func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *paw.Password, opts RandomPasswordOptions) fyne.CanvasObject {
+// pronounceableOptions returns the options for the pronounceable passwords along with their entropy
+func pronounceableOptions(vault azure.Vault, item paw.Item, passwordBind binding.String, password *paw.Password) fyne.CanvasObject {
+	if password.Mode != paw.PronounceablePassword {
+		password.Mode = paw.PronounceablePassword
+		password.Format = paw.PronounceablePasswordDefaultFormat
+	}
+	if password.Length < paw.PronounceablePasswordMinLength || password.Length > paw.PronounceablePasswordMaxLength {
+		password.Length = paw.PronounceablePasswordDefaultLength
+	}
+
+	entropyLabel := widget.NewLabel("")
+	regenerate := func() {
+		secret, err := pwgen(vault, password, item)
+		if err != nil {
+			entropyLabel.SetText(err.Error())
+			return
+		}
+		passwordBind.Set(secret)
+		entropyLabel.SetText(fmt.Sprintf("%.0f bits", password.Pronounceable().Entropy()))
+	}
+
+	lengthBind := binding.BindInt(&password.Length)
+	lengthEntry := widget.NewEntryWithData(binding.IntToString(lengthBind))
+	lengthEntry.Disable()
+	lengthSlider := widget.NewSlider(paw.PronounceablePasswordMinLength, paw.PronounceablePasswordMaxLength)
+	lengthSlider.SetValue(float64(password.Length))
+	lengthSlider.OnChanged = func(f float64) {
+		lengthBind.Set(int(f))
+		regenerate()
+	}
+
+	formatCheck := func(label string, format paw.Format) *widget.Check {
+		c := widget.NewCheck(label, func(b bool) {
+			if b {
+				password.Format |= format
+			} else {
+				password.Format &^= format
+			}
+			regenerate()
+		})
+		c.SetChecked(password.Format&format != 0)
+		return c
+	}
+
+	regenerate()
+
+	form := container.New(layout.NewFormLayout())
+	form.Add(labelWithStyle("Length"))
+	form.Add(container.NewBorder(nil, nil, nil, lengthEntry, lengthSlider))
+	form.Add(widget.NewLabel(""))
+	form.Add(container.NewHBox(formatCheck("Uppercase", paw.UppercaseFormat), formatCheck("Digits", paw.DigitsFormat), formatCheck("Symbol", paw.SymbolsFormat)))
+	form.Add(labelWithStyle("Entropy"))
+	form.Add(entropyLabel)
+	return form
+}
+
+// randomPasswordOptions returns the options for the random and the stateless modes.
+// Stateless passwords are derived from the key, the item and the counter.
+func randomPasswordOptions(vault azure.Vault, item paw.Item, passwordBind binding.String, password *paw.Password, mode paw.PasswordMode, opts RandomPasswordOptions) fyne.CanvasObject {
 
 	if password.Length == 0 || password.Length < opts.MinLength || password.Length > opts.MaxLength {
 		password.Length = opts.DefaultLength
@@ -279,10 +548,13 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 		password.Format = opts.DefaultFormat
 	}
 
-	if password.Mode != paw.RandomPassword {
-		password.Mode = paw.RandomPassword
+	if password.Mode != paw.RandomPassword && password.Mode != paw.StatelessPassword {
 		password.Format = opts.DefaultFormat
 	}
+	password.Mode = mode
+	if mode == paw.StatelessPassword && password.Counter < paw.StatelessPasswordDefaultCounter {
+		password.Counter = paw.StatelessPasswordDefaultCounter
+	}
 
 	lengthBind := binding.BindInt(&password.Length)
 	lengthEntry := widget.NewEntryWithData(binding.IntToString(lengthBind))
@@ -303,7 +575,7 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 			return
 		}
 		lengthBind.Set(l)
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -315,7 +587,7 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 	lengthSlider := widget.NewSlider(float64(opts.MinLength), float64(opts.MaxLength))
 	lengthSlider.OnChanged = func(f float64) {
 		lengthBind.Set(int(f))
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -331,7 +603,7 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 		} else {
 			password.Format &^= paw.LowercaseFormat
 		}
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -351,7 +623,7 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 		} else {
 			password.Format &^= paw.UppercaseFormat
 		}
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -371,7 +643,7 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 		} else {
 			password.Format &^= paw.DigitsFormat
 		}
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -391,7 +663,7 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 		} else {
 			password.Format &^= paw.SymbolsFormat
 		}
-		secret, err := pwgen(key, password)
+		secret, err := pwgen(vault, password, item)
 		if err != nil {
 			// TODO show dialog
 			log.Println(err)
@@ -405,7 +677,107 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 		symbolsButton.SetChecked(false)
 	}
 
-	secret, err := pwgen(key, password)
+	regenerate := func() {
+		secret, err := pwgen(vault, password, item)
+		if err != nil {
+			// TODO show dialog
+			log.Println(err)
+			return
+		}
+		passwordBind.Set(secret)
+	}
+
+	minEntries := []fyne.CanvasObject{}
+	for _, format := range []paw.Format{paw.LowercaseFormat, paw.UppercaseFormat, paw.DigitsFormat, paw.SymbolsFormat} {
+		format := format
+		e := newIntEntry(password.Min[format], func(n int) {
+			// copy on write, the map could be shared with the item being edited
+			min := map[paw.Format]int{}
+			for k, v := range password.Min {
+				min[k] = v
+			}
+			if n == 0 {
+				delete(min, format)
+			} else {
+				min[format] = n
+			}
+			password.Min = min
+			regenerate()
+		})
+		minEntries = append(minEntries, e)
+	}
+
+	maxConsecutiveEntry := newIntEntry(password.MaxConsecutive, func(n int) {
+		password.MaxConsecutive = n
+		regenerate()
+	})
+
+	excludeEntry := widget.NewEntry()
+	excludeEntry.SetPlaceHolder("Chars to exclude")
+	excludeEntry.SetText(password.Exclude)
+	excludeEntry.Validator = nil
+	excludeEntry.OnChanged = func(s string) {
+		password.Exclude = s
+		regenerate()
+	}
+
+	symbolsEntry := widget.NewEntry()
+	symbolsEntry.SetPlaceHolder("All symbols allowed")
+	symbolsEntry.SetText(password.Symbols)
+	symbolsEntry.Validator = func(s string) error {
+		rule, err := paw.NewRule(0, paw.SymbolsFormat)
+		if err != nil {
+			return err
+		}
+		return rule.WithSymbols([]byte(s))
+	}
+	symbolsEntry.OnChanged = func(s string) {
+		password.Symbols = s
+		regenerate()
+	}
+
+	presets := paw.CharsetPresets()
+	presetOptions := make([]string, 0, len(presets))
+	for _, p := range presets {
+		presetOptions = append(presetOptions, p.Name)
+	}
+	presetSelect := widget.NewSelect(presetOptions, func(s string) {
+		for _, p := range presets {
+			if p.Name != s {
+				continue
+			}
+			if p.Exclude != "" {
+				excludeEntry.SetText(p.Exclude)
+			}
+			if p.Symbols != "" {
+				symbolsEntry.SetText(p.Symbols)
+			}
+		}
+	})
+	presetSelect.PlaceHolder = "Presets"
+
+	rulesEntry := widget.NewEntry()
+	rulesEntry.SetPlaceHolder("required: upper; required: digit; minlength: 12")
+	rulesEntry.SetText(password.Rules)
+	rulesEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := paw.ParsePasswordRules(s)
+		return err
+	}
+	rulesEntry.OnChanged = func(s string) {
+		if s != "" {
+			if _, err := paw.ParsePasswordRules(s); err != nil {
+				return
+			}
+		}
+		// the rules replace the format and the constraints options
+		password.Rules = s
+		regenerate()
+	}
+
+	secret, err := pwgen(vault, password, item)
 	if err != nil {
 		// TODO show dialog
 		log.Println(err)
@@ -417,15 +789,89 @@ func randomPasswordOptions(key *paw.Key, passwordBind binding.String, password *
 	form.Add(container.NewBorder(nil, nil, nil, lengthEntry, lengthSlider))
 	form.Add(widget.NewLabel(""))
 	form.Add(container.NewGridWithColumns(4, lowercaseButton, uppercaseButton, digitsButton, symbolsButton))
+	form.Add(labelWithStyle("Exclude"))
+	form.Add(container.NewBorder(nil, nil, nil, presetSelect, excludeEntry))
+	form.Add(labelWithStyle("Symbols"))
+	form.Add(symbolsEntry)
+	form.Add(labelWithStyle("Min count"))
+	form.Add(container.NewGridWithColumns(4, minEntries...))
+	form.Add(labelWithStyle("Max repeat"))
+	form.Add(maxConsecutiveEntry)
+	form.Add(labelWithStyle("Rules"))
+	form.Add(rulesEntry)
+
+	if mode == paw.StatelessPassword {
+		counterEntry := widget.NewEntry()
+		counterEntry.SetText(strconv.Itoa(password.Counter))
+		counterEntry.OnChanged = func(s string) {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return
+			}
+			password.Counter = n
+			regenerate()
+		}
+		counterEntry.Validator = func(s string) error {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return fmt.Errorf("must be a positive number")
+			}
+			return nil
+		}
+		// rotating a stateless password just bumps the counter
+		rotateButton := widget.NewButtonWithIcon("Rotate", theme.ViewRefreshIcon(), func() {
+			counterEntry.SetText(strconv.Itoa(password.Counter + 1))
+		})
+		form.Add(labelWithStyle("Counter"))
+		form.Add(container.NewBorder(nil, nil, nil, rotateButton, counterEntry))
+		form.Add(widget.NewLabel(""))
+		form.Add(widget.NewLabelWithStyle("Derived from the vault key, the item name and the counter", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}))
+	}
 
 	return form
 }
 
-func pwgen(key *paw.Key, password *paw.Password) (string, error) {
-	if password.Mode == paw.PassphrasePassword {
-		return key.Passphrase(password.Length)
+// newIntEntry returns an entry for non negative integers. Zero is displayed as empty text
+func newIntEntry(value int, onChanged func(int)) *widget.Entry {
+	e := widget.NewEntry()
+	e.SetPlaceHolder("0")
+	if value > 0 {
+		e.SetText(strconv.Itoa(value))
+	}
+	e.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return fmt.Errorf("must be a non negative number")
+		}
+		return nil
+	}
+	e.OnChanged = func(s string) {
+		if s == "" {
+			onChanged(0)
+			return
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return
+		}
+		onChanged(n)
+	}
+	return e
+}
+
+// pwgen generates the password of the item, the vault key is created on the first stateless password.
+// The breached passwords are rejected in background, see breachGuard.
+func pwgen(vault azure.Vault, password *paw.Password, item paw.Item) (string, error) {
+	secret, err := vault.Secret(password, item)
+	if errors.Is(err, azure.ErrNoKey) {
+		// the vault key is created with the first stateless password
+		if err = vault.CreateKey(); err == nil {
+			secret, err = vault.Secret(password, item)
+		}
 	}
-	secret, err := key.Secret(password)
 	if err != nil {
 		return "", fmt.Errorf("could not generate password: %w", err)
 	}
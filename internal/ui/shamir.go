@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/kit"
+	"lucor.dev/paw/internal/shamir"
+)
+
+const (
+	shamirSourceValue = "Secret value"
+	shamirSourceKey   = "Vault key"
+)
+
+// makeShamirButton returns the button used to split a secret into shares and combine them back
+func (vw *vaultView) makeShamirButton() fyne.CanvasObject {
+	button := widget.NewButtonWithIcon("", theme.ContentCutIcon(), vw.showShamir)
+	button.Importance = widget.LowImportance
+	return button
+}
+
+// showShamir sets the secret sharing view as content
+func (vw *vaultView) showShamir() {
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Split", vw.shamirSplitView()),
+		container.NewTabItem("Combine", vw.shamirCombineView()),
+	)
+	vw.setContent(tabs)
+}
+
+// shamirSplitView returns the view that splits a secret value or the vault key into shares.
+// The shares of the vault key are the shares of the emergency kit.
+func (vw *vaultView) shamirSplitView() fyne.CanvasObject {
+	w := vw.mainView.Window
+
+	name := widget.NewEntry()
+	name.SetText("secret")
+	secret := widget.NewPasswordEntry()
+	secret.SetPlaceHolder("The secret to split")
+
+	sources := []string{shamirSourceValue}
+	if _, err := vw.vault.Key(); err == nil {
+		sources = append(sources, shamirSourceKey)
+	}
+	source := widget.NewRadioGroup(sources, func(s string) {
+		if s == shamirSourceKey {
+			name.SetText(vw.name.Text)
+			name.Disable()
+			secret.Hide()
+			return
+		}
+		name.Enable()
+		secret.Show()
+	})
+	source.Horizontal = true
+	source.Required = true
+	source.SetSelected(shamirSourceValue)
+
+	shares := widget.NewEntry()
+	shares.SetText("3")
+	threshold := widget.NewEntry()
+	threshold.SetText("2")
+
+	result := container.NewVBox()
+	split := widget.NewButtonWithIcon("Split", theme.ContentCutIcon(), func() {
+		n, err := strconv.Atoi(shares.Text)
+		if err != nil {
+			dialog.ShowError(errors.New("the number of shares is invalid"), w)
+			return
+		}
+		m, err := strconv.Atoi(threshold.Text)
+		if err != nil {
+			dialog.ShowError(errors.New("the threshold is invalid"), w)
+			return
+		}
+
+		var parts []shamirPart
+		if source.Selected == shamirSourceKey {
+			parts, err = vw.splitKey(n, m)
+		} else {
+			parts, err = splitValue(name.Text, secret.Text, n, m)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		vw.setShamirResult(result, parts)
+	})
+	split.Importance = widget.HighImportance
+
+	warning := widget.NewLabel("Anyone holding the threshold shares can combine the secret, give each share to a different person.")
+	warning.Wrapping = fyne.TextWrapWord
+
+	form := widget.NewForm(
+		widget.NewFormItem("Split", source),
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("", secret),
+		widget.NewFormItem("Shares", shares),
+		widget.NewFormItem("Threshold", threshold),
+	)
+	top := container.NewVBox(form, warning, container.NewHBox(split))
+	return container.NewBorder(top, nil, nil, nil, container.NewVScroll(result))
+}
+
+// shamirCombineView returns the view that combines the shares into the secret value
+func (vw *vaultView) shamirCombineView() fyne.CanvasObject {
+	w := vw.mainView.Window
+
+	shares := widget.NewMultiLineEntry()
+	shares.SetPlaceHolder("Paste or type the shares, one per line")
+	shares.Wrapping = fyne.TextWrapBreak
+
+	secret := widget.NewPasswordEntry()
+	secret.Disable()
+	copyButton := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		w.Clipboard().SetContent(secret.Text)
+	})
+	copyButton.Disable()
+
+	combine := widget.NewButtonWithIcon("Combine", theme.ConfirmIcon(), func() {
+		v, err := combineShares(shares.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		secret.SetText(v)
+		copyButton.Enable()
+	})
+	combine.Importance = widget.HighImportance
+
+	bottom := container.NewVBox(
+		container.NewHBox(combine),
+		widget.NewForm(widget.NewFormItem("Secret", container.NewBorder(nil, nil, nil, copyButton, secret))),
+	)
+	return container.NewBorder(nil, bottom, nil, nil, shares)
+}
+
+// shamirPart is a share ready to be displayed and saved
+type shamirPart struct {
+	// fileName is the name of the file where to save the share
+	fileName string
+	// value is the encoded share
+	value string
+	// write writes the share file
+	write func(w io.Writer) error
+}
+
+// splitKey splits the vault key into the pages of the emergency kit, so that the
+// key can be recovered using the recover command
+func (vw *vaultView) splitKey(n int, threshold int) ([]shamirPart, error) {
+	key, err := vw.vault.Key()
+	if err != nil {
+		return nil, err
+	}
+	k, err := kit.New(vw.name.Text, key, n, threshold)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]shamirPart, k.Pages())
+	for i := range parts {
+		i := i
+		parts[i] = shamirPart{
+			fileName: fmt.Sprintf("%s-kit-share-%d-of-%d.txt", k.Vault, i+1, k.Pages()),
+			value:    k.Secrets[i],
+			write: func(w io.Writer) error {
+				return k.WriteText(w, i)
+			},
+		}
+	}
+	return parts, nil
+}
+
+// splitValue splits the secret value into the shares
+func splitValue(name string, secret string, n int, threshold int) ([]shamirPart, error) {
+	if name == "" {
+		return nil, errors.New("the name cannot be empty")
+	}
+	shares, err := shamir.Split([]byte(secret), n, threshold)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]shamirPart, len(shares))
+	for i, share := range shares {
+		v, err := share.Encode()
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = shamirPart{
+			fileName: fmt.Sprintf("%s-share-%d-of-%d.txt", name, i+1, len(shares)),
+			value:    v,
+			write: func(w io.Writer) error {
+				_, err := io.WriteString(w, v+"\n")
+				return err
+			},
+		}
+	}
+	return parts, nil
+}
+
+// combineShares combines the shares, one per line, into the secret value
+func combineShares(text string) (string, error) {
+	secrets, err := kit.ReadSecrets(strings.NewReader(text))
+	if err != nil {
+		return "", err
+	}
+	var shares []shamir.Share
+	for _, v := range secrets {
+		share, err := shamir.ParseShare(v)
+		if err != nil {
+			return "", err
+		}
+		shares = append(shares, share)
+	}
+	secret, err := shamir.Combine(shares)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(secret) {
+		return "", errors.New("the shares do not hold a text value, use the paw recover command for the shares of a vault key")
+	}
+	return string(secret), nil
+}
+
+// setShamirResult displays the shares, each one can be copied, and the button to save them as files
+func (vw *vaultView) setShamirResult(result *fyne.Container, parts []shamirPart) {
+	w := vw.mainView.Window
+
+	result.Objects = nil
+	for i, part := range parts {
+		value := part.value
+		label := widget.NewLabel(value)
+		label.Wrapping = fyne.TextWrapBreak
+		copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+			w.Clipboard().SetContent(value)
+		})
+		title := widget.NewLabelWithStyle(fmt.Sprintf("Share %d of %d", i+1, len(parts)), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		result.Add(container.NewBorder(title, nil, nil, copyButton, label))
+	}
+
+	save := widget.NewButtonWithIcon("Save as files", theme.DocumentSaveIcon(), func() {
+		dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if dir == nil {
+				return
+			}
+			if err := saveShamirParts(dir, parts); err != nil {
+				dialog.ShowError(fmt.Errorf("could not save the shares: %w", err), w)
+				return
+			}
+			dialog.ShowInformation("Secret sharing", fmt.Sprintf("%d shares saved", len(parts)), w)
+		}, w)
+	})
+	result.Add(container.NewHBox(save))
+	result.Refresh()
+}
+
+// saveShamirParts writes the shares into the directory, the existing files are not overwritten
+func saveShamirParts(dir fyne.ListableURI, parts []shamirPart) error {
+	for _, part := range parts {
+		u, err := storage.Child(dir, part.fileName)
+		if err != nil {
+			return err
+		}
+		if ok, _ := storage.Exists(u); ok {
+			return fmt.Errorf("%s already exists", part.fileName)
+		}
+		wc, err := storage.Writer(u)
+		if err != nil {
+			return err
+		}
+		if err := part.write(wc); err != nil {
+			wc.Close()
+			return err
+		}
+		if err := wc.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/strength"
+)
+
+// newStrengthMeter returns a meter displaying the strength of the password bound to bind.
+// The inputs func returns the values to match as dictionary words, like the item name and the username.
+func newStrengthMeter(bind binding.String, inputs func() []string) fyne.CanvasObject {
+	var result *strength.Result
+
+	bar := widget.NewProgressBar()
+	bar.Max = 4
+	bar.TextFormatter = func() string {
+		if result == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s - %.0f bits - cracked in %s", result.Label(), result.Entropy, result.CrackTimeText())
+	}
+	warning := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	warning.Wrapping = fyne.TextWrapWord
+	warning.Hide()
+
+	bind.AddListener(binding.NewDataListener(func() {
+		password, _ := bind.Get()
+		if password == "" {
+			result = nil
+			bar.SetValue(0)
+			warning.Hide()
+			return
+		}
+		var in []string
+		if inputs != nil {
+			in = inputs()
+		}
+		result = strength.Estimate(password, in...)
+		// a non empty password always shows a piece of bar
+		bar.SetValue(float64(result.Score) + 0.1)
+		warning.SetText(result.Warning())
+		if warning.Text == "" {
+			warning.Hide()
+		} else {
+			warning.Show()
+		}
+	}))
+	return container.NewVBox(bar, warning)
+}
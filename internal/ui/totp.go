@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"context"
+	"image/color"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/paw"
+)
+
+// countdownRingSize is the size of the ring displaying the TOTP remaining time
+const countdownRingSize = 24
+
+// newCountdownRing returns a ring filled according the fraction returned by f
+func newCountdownRing(f func() float64) *canvas.Raster {
+	r := canvas.NewRasterWithPixels(func(x, y, w, h int) color.Color {
+		cx, cy := float64(w)/2, float64(h)/2
+		outer := math.Min(cx, cy)
+		inner := outer * 0.6
+		dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+		d := math.Hypot(dx, dy)
+		if d > outer || d < inner {
+			return color.Transparent
+		}
+		// angle clockwise starting from the top, normalized to [0,1)
+		angle := math.Atan2(dx, -dy) / (2 * math.Pi)
+		if angle < 0 {
+			angle++
+		}
+		if angle < f() {
+			return theme.PrimaryColor()
+		}
+		return theme.DisabledColor()
+	})
+	r.SetMinSize(fyne.NewSize(countdownRingSize, countdownRingSize))
+	return r
+}
+
+// totpRow returns a form row displaying the live TOTP code with a countdown ring
+// and the copy button. The code is updated until the context is cancelled.
+func totpRow(ctx context.Context, label string, totp *paw.TOTP, w fyne.Window) []fyne.CanvasObject {
+	code := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true, Bold: true})
+	remaining := func() float64 {
+		return float64(totp.Remaining(time.Now())) / float64(time.Duration(totp.Period)*time.Second)
+	}
+	ring := newCountdownRing(remaining)
+
+	update := func() {
+		v, err := totp.Code(time.Now())
+		if err != nil {
+			v = err.Error()
+		}
+		if code.Text != v {
+			code.SetText(v)
+		}
+		ring.Refresh()
+	}
+	update()
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	}()
+
+	b := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		v, err := totp.Code(time.Now())
+		if err != nil {
+			return
+		}
+		w.Clipboard().SetContent(v)
+	})
+
+	l := labelWithStyle(label)
+	return []fyne.CanvasObject{l, container.NewBorder(nil, nil, ring, b, code)}
+}
+
+// newTOTPEntry returns an entry to edit the otpauth URI or the base32 secret of a TOTP.
+// The onChanged callback is invoked with a nil TOTP when the entry is empty.
+func newTOTPEntry(totp *paw.TOTP, onChanged func(*paw.TOTP)) *widget.Entry {
+	e := widget.NewPasswordEntry()
+	e.SetPlaceHolder("otpauth:// URI or secret")
+	if totp != nil {
+		e.SetText(totp.URI())
+	}
+	e.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		return (&paw.TOTP{}).SetURI(s)
+	}
+	e.OnChanged = func(s string) {
+		if s == "" {
+			onChanged(nil)
+			return
+		}
+		t := &paw.TOTP{}
+		if err := t.SetURI(s); err != nil {
+			return
+		}
+		onChanged(t)
+	}
+	return e
+}
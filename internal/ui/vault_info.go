@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"lucor.dev/paw/internal/agent"
+	"lucor.dev/paw/internal/azure"
+	"lucor.dev/paw/internal/paw"
+)
+
+// makeInfoButton returns the button used to display the vault info
+func (vw *vaultView) makeInfoButton() fyne.CanvasObject {
+	button := widget.NewButtonWithIcon("", theme.InfoIcon(), vw.showVaultInfo)
+	button.Importance = widget.LowImportance
+	return button
+}
+
+// showVaultInfo sets the vault info panel as content.
+// The panel displays the key metadata, the creation date and the scrypt work factor
+// are known only for the keys stored into a key file.
+// The key is not available while the vault is served by the agent.
+func (vw *vaultView) showVaultInfo() {
+	form := widget.NewForm()
+	form.Append("Vault", widget.NewLabel(vw.name.Text))
+	form.Append("Items", widget.NewLabel(fmt.Sprintf("%d", vw.vault.Size())))
+
+	if _, ok := vw.vault.(*agent.RemoteVault); ok {
+		form.Append("Key", widget.NewLabel("Not available while the agent is running"))
+		vw.setContent(container.NewVScroll(container.NewPadded(form)))
+		return
+	}
+
+	key, err := vw.vault.Key()
+	if err != nil {
+		text := err.Error()
+		if errors.Is(err, azure.ErrNoKey) {
+			text = "No vault key yet, it is created with the first stateless password"
+		}
+		label := widget.NewLabel(text)
+		label.Wrapping = fyne.TextWrapWord
+		form.Append("Key", label)
+		vw.setContent(container.NewVScroll(container.NewPadded(form)))
+		return
+	}
+
+	info := key.Info()
+	form.Append("Key ID", widget.NewLabel(info.ID))
+
+	publicKey := widget.NewLabel(info.PublicKey)
+	publicKey.Wrapping = fyne.TextWrapBreak
+	copyButton := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		vw.mainView.Window.Clipboard().SetContent(info.PublicKey)
+	})
+	form.Append("Public Key", container.NewBorder(nil, nil, nil, copyButton, publicKey))
+
+	created := "Unknown"
+	if !info.Created.IsZero() {
+		created = info.Created.Local().Format(time.RFC1123)
+	}
+	form.Append("Created", widget.NewLabel(created))
+
+	if local := localKeyInfo(vw.name.Text, info.PublicKey); local != nil {
+		workFactor := fmt.Sprintf("%d (N=2^%d)", local.WorkFactor, local.WorkFactor)
+		form.Append("Scrypt Work Factor", widget.NewLabel(workFactor))
+	}
+
+	vw.setContent(container.NewVScroll(container.NewPadded(form)))
+}
+
+// localKeyInfo returns the metadata of the vault key stored into the local key.age,
+// if any. It returns nil if the local key is not the vault key.
+func localKeyInfo(name string, publicKey string) *paw.KeyInfo {
+	s, err := paw.NewOSStorage()
+	if err != nil {
+		return nil
+	}
+	info, err := s.(*paw.OSStorage).VaultKeyInfo(name)
+	if err != nil || info.PublicKey != publicKey || info.WorkFactor == 0 {
+		return nil
+	}
+	return info
+}
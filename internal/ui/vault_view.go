@@ -26,7 +26,7 @@ type vaultView struct {
 	mainView *mainView
 
 	name          *widget.Label
-	vault         *azure.SecretsVault
+	vault         azure.Vault
 	filterOptions *paw.VaultFilterOptions
 
 	// view is a container used to split the vault view in two areas: navbar and content.
@@ -44,7 +44,7 @@ type vaultView struct {
 	itemsWidget     *itemsWidget
 }
 
-func newVaultView(mw *mainView, vault *azure.SecretsVault, name string) *vaultView {
+func newVaultView(mw *mainView, vault azure.Vault, name string) *vaultView {
 	vw := &vaultView{
 		mainView: mw,
 		name: &widget.Label{
@@ -144,7 +144,7 @@ func (vw *vaultView) makeVaultMenu() fyne.CanvasObject {
 		switchVault.Disabled = true
 	}
 
-	return container.NewBorder(nil, nil, nil, nil, vw.name)
+	return container.NewBorder(nil, nil, nil, container.NewHBox(vw.makeInfoButton(), vw.makeExportButton(), vw.makeHealthButton(), vw.makeShamirButton()), vw.name)
 }
 
 // makeSearchEntry returns the search entry used to filter the item list by name
@@ -193,22 +193,39 @@ func (vw *vaultView) makeItems() []paw.Item {
 	note := paw.NewNote()
 	password := paw.NewPassword()
 	website := paw.NewLogin()
+	sshKey := paw.NewSSHKey()
+	totp := paw.NewTOTP()
 
 	return []paw.Item{
 		note,
 		password,
 		website,
+		sshKey,
+		totp,
 	}
 }
 
 // makeAddItemButton returns the button used to add an item to the vault
 func (vw *vaultView) makeAddItemButton() fyne.CanvasObject {
 
-	button := widget.NewButtonWithIcon("New Entry", theme.ContentAddIcon(), func() {
-		// on button press we need to display the content of password menu
-		p := paw.NewLogin()
-		vw.setContentItem(NewFyneItem(p), vw.editItemView)
-		vw.Reload()
+	newItem := func(item paw.Item) func() {
+		return func() {
+			vw.setContentItem(NewFyneItem(item), vw.editItemView)
+			vw.Reload()
+		}
+	}
+
+	var button *widget.Button
+	button = widget.NewButtonWithIcon("New Entry", theme.ContentAddIcon(), func() {
+		// on button press display the menu to choose the item type to create
+		menu := fyne.NewMenu("",
+			fyne.NewMenuItem("Login", newItem(paw.NewLogin())),
+			fyne.NewMenuItem("SSH Key", newItem(paw.NewSSHKey())),
+			fyne.NewMenuItem("One-time password", newItem(paw.NewTOTP())),
+		)
+		c := fyne.CurrentApp().Driver().CanvasForObject(button)
+		pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(button)
+		widget.ShowPopUpMenuAtPosition(menu, c, pos.Add(fyne.NewPos(0, button.Size().Height)))
 	})
 	button.Importance = widget.HighImportance
 	return button
@@ -250,17 +267,10 @@ func (vw *vaultView) editItemView(ctx context.Context, fyneItem FyneItem) fyne.C
 		isNew = true
 	}
 
-	content, editItem := fyneItem.Edit(ctx, vw.vault.Key(), vw.mainView.Window)
-	saveBtn := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+	content, editItem := fyneItem.Edit(ctx, vw.vault, vw.mainView.Window)
+	save := func() {
 		metadata := editItem.GetMetadata()
 
-		// TODO: update to use the built-in entry validation
-		if metadata.Name == "" {
-			d := dialog.NewInformation("", "The title cannot be emtpy", vw.mainView.Window)
-			d.Show()
-			return
-		}
-
 		var reloadItems bool
 
 		// add item to vault
@@ -285,7 +295,15 @@ func (vw *vaultView) editItemView(ctx context.Context, fyneItem FyneItem) fyne.C
 		fyneItem := NewFyneItem(item)
 		vw.setContentItem(fyneItem, vw.itemView)
 		vw.Reload()
-
+	}
+	saveBtn := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		// TODO: update to use the built-in entry validation
+		if editItem.GetMetadata().Name == "" {
+			d := dialog.NewInformation("", "The title cannot be emtpy", vw.mainView.Window)
+			d.Show()
+			return
+		}
+		confirmBreachedLogin(editItem, vw.mainView.Window, save)
 	})
 	saveBtn.Importance = widget.HighImportance
 	deleteBtn := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {